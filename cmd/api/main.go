@@ -1,28 +1,49 @@
 package main
 
 import (
+	"context"
 	"log"
-	"net/http"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"onestay-back/internal/app"
 	"onestay-back/internal/config"
-	"onestay-back/internal/database"
-	"onestay-back/internal/router"
 )
 
 func main() {
-	if err := config.Load(); err != nil {
+	cfg, err := config.Load()
+	if err != nil {
 		log.Fatal("Erreur lors du chargement de la configuration:", err)
 	}
 
-	if err := database.Connect(); err != nil {
-		log.Fatal("Erreur lors de la connexion à MongoDB:", err)
+	if err := run(cfg); err != nil {
+		log.Fatal(err)
 	}
-	defer database.Disconnect()
+	log.Println("Serveur arrêté")
+}
+
+// run démarre l'API et la fait tourner jusqu'à SIGINT ou SIGTERM. Les erreurs sont
+// retournées plutôt que fatales pour que la connexion à MongoDB soit toujours fermée.
+func run(cfg *config.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	r := router.SetupRouter()
+	application, err := app.New(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := application.Close(); err != nil {
+			log.Printf("Erreur lors de la déconnexion de MongoDB: %v", err)
+		}
+	}()
 
-	log.Printf("Serveur démarré sur le port %s", config.AppConfig.Port)
-	if err := http.ListenAndServe(":"+config.AppConfig.Port, r); err != nil {
-		log.Fatal("Erreur lors du démarrage du serveur:", err)
+	ln, err := net.Listen("tcp", ":"+cfg.Port)
+	if err != nil {
+		return err
 	}
+
+	return application.Serve(ctx, ln)
 }
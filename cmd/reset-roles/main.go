@@ -4,24 +4,26 @@ import (
 	"context"
 	"log"
 
+	"onestay-back/internal/app"
 	"onestay-back/internal/config"
-	"onestay-back/internal/database"
 	"onestay-back/internal/seed"
 )
 
 func main() {
-	if err := config.Load(); err != nil {
+	cfg, err := config.Load()
+	if err != nil {
 		log.Fatal("Erreur lors du chargement de la configuration:", err)
 	}
 
-	if err := database.Connect(); err != nil {
-		log.Fatal("Erreur lors de la connexion à MongoDB:", err)
+	ctx := context.Background()
+	application, err := app.New(ctx, cfg)
+	if err != nil {
+		log.Fatal("Erreur lors de l'initialisation de l'application:", err)
 	}
-	defer database.Disconnect()
+	defer application.Close()
 
 	// Supprimer tous les rôles existants
-	ctx := context.Background()
-	collection := database.DB.Collection("roles")
+	collection := application.DB.Collection("roles")
 	
 	result, err := collection.DeleteMany(ctx, map[string]interface{}{})
 	if err != nil {
@@ -30,7 +32,7 @@ func main() {
 	log.Printf("Supprimé %d rôles", result.DeletedCount)
 
 	// Recréer les rôles
-	if err := seed.SeedRoles(); err != nil {
+	if err := seed.SeedRoles(application.Stores.Roles); err != nil {
 		log.Fatal("Erreur lors de l'initialisation des rôles:", err)
 	}
 
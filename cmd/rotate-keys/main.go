@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"onestay-back/internal/app"
+	"onestay-back/internal/config"
+	"onestay-back/internal/repository"
+)
+
+// Rechiffre les codes d'accès et mots de passe des propriétés avec la clé active.
+// Procédure : ajouter la nouvelle clé à FIELD_ENCRYPTION_KEYS, la déclarer dans
+// FIELD_ENCRYPTION_ACTIVE_KEY, lancer cette commande, puis retirer l'ancienne clé.
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Erreur lors du chargement de la configuration:", err)
+	}
+
+	if cfg.FieldEncryptionKeys == "" {
+		log.Fatal("FIELD_ENCRYPTION_KEYS doit être défini pour rechiffrer les données")
+	}
+
+	application, err := app.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatal("Erreur lors de l'initialisation de l'application:", err)
+	}
+	defer application.Close()
+
+	propertyRepo := repository.NewPropertyRepository(application.DB, application.Keyring)
+
+	updated, err := propertyRepo.ReencryptAll(context.Background())
+	if err != nil {
+		log.Fatalf("Erreur lors du rechiffrement (%d propriétés déjà traitées): %v", updated, err)
+	}
+
+	log.Printf("%d propriétés rechiffrées avec la clé %s", updated, application.Keyring.ActiveKeyID())
+
+	// L'historique, les brouillons et les modèles de section conservent des copies des secrets : elles doivent aussi être rechiffrées
+	revisions, err := repository.NewPropertyRevisionRepository(application.DB, application.Keyring).ReencryptAll(context.Background())
+	if err != nil {
+		log.Fatalf("Erreur lors du rechiffrement de l'historique (%d révisions déjà traitées): %v", revisions, err)
+	}
+
+	log.Printf("%d révisions rechiffrées avec la clé %s", revisions, application.Keyring.ActiveKeyID())
+
+	drafts, err := repository.NewPropertyDraftRepository(application.DB, application.Keyring).ReencryptAll(context.Background())
+	if err != nil {
+		log.Fatalf("Erreur lors du rechiffrement des brouillons (%d brouillons déjà traités): %v", drafts, err)
+	}
+
+	log.Printf("%d brouillons rechiffrés avec la clé %s", drafts, application.Keyring.ActiveKeyID())
+
+	templates, err := repository.NewSectionTemplateRepository(application.DB, application.Keyring).ReencryptAll(context.Background())
+	if err != nil {
+		log.Fatalf("Erreur lors du rechiffrement des modèles de section (%d modèles déjà traités): %v", templates, err)
+	}
+
+	log.Printf("%d modèles de section rechiffrés avec la clé %s", templates, application.Keyring.ActiveKeyID())
+}
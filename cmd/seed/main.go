@@ -1,24 +1,27 @@
 package main
 
 import (
+	"context"
 	"log"
 
+	"onestay-back/internal/app"
 	"onestay-back/internal/config"
-	"onestay-back/internal/database"
 	"onestay-back/internal/seed"
 )
 
 func main() {
-	if err := config.Load(); err != nil {
+	cfg, err := config.Load()
+	if err != nil {
 		log.Fatal("Erreur lors du chargement de la configuration:", err)
 	}
 
-	if err := database.Connect(); err != nil {
-		log.Fatal("Erreur lors de la connexion à MongoDB:", err)
+	application, err := app.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatal("Erreur lors de l'initialisation de l'application:", err)
 	}
-	defer database.Disconnect()
+	defer application.Close()
 
-	if err := seed.SeedRoles(); err != nil {
+	if err := seed.SeedRoles(application.Stores.Roles); err != nil {
 		log.Fatal("Erreur lors de l'initialisation des rôles:", err)
 	}
 
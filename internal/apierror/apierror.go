@@ -0,0 +1,107 @@
+// Package apierror centralise les erreurs renvoyées par l'API : chaque erreur a un code
+// stable, un statut HTTP et un message traduit selon la langue du client.
+//
+// L'enveloppe est toujours la même :
+//
+//	{"error": "<message traduit>", "code": "PROPERTY_NOT_FOUND", "details": ..., "fields": [...]}
+//
+// "details" et "fields" sont facultatifs. Les erreurs techniques (base de données, stockage...)
+// ne sont jamais exposées : elles sont journalisées et remplacées par INTERNAL_ERROR.
+package apierror
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"onestay-back/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultLocale est la langue des messages quand le client n'en demande aucune disponible
+const DefaultLocale = "fr"
+
+// Locales liste les langues dans lesquelles le catalogue est traduit
+var Locales = []string{"fr", "en"}
+
+// Response est l'enveloppe JSON d'une erreur
+type Response struct {
+	Error   string       `json:"error"`
+	Code    Code         `json:"code"`
+	Details any          `json:"details,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// Status retourne le statut HTTP associé au code (500 pour un code inconnu)
+func (code Code) Status() int {
+	if e, ok := catalog[code]; ok {
+		return e.Status
+	}
+	return catalog[InternalError].Status
+}
+
+// Message retourne le message du code dans la langue demandée, avec repli sur le français
+func (code Code) Message(locale string, args ...any) string {
+	e, ok := catalog[code]
+	if !ok {
+		e = catalog[InternalError]
+	}
+	format, ok := e.Messages[locale]
+	if !ok {
+		format = e.Messages[DefaultLocale]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Locale choisit la langue des messages d'erreur selon ?lang= ou l'en-tête Accept-Language
+func Locale(c *gin.Context) string {
+	return i18n.Negotiate(c.Query("lang"), c.GetHeader("Accept-Language"), Locales, DefaultLocale)
+}
+
+// Abort écrit l'erreur et interrompt la chaîne de handlers. Les arguments complètent le message.
+func Abort(c *gin.Context, code Code, args ...any) {
+	write(c, Response{Code: code, Error: code.Message(Locale(c), args...)})
+}
+
+// AbortWithDetails écrit l'erreur accompagnée d'informations destinées au client
+// (permissions manquantes, champs inconnus...)
+func AbortWithDetails(c *gin.Context, code Code, details any, args ...any) {
+	write(c, Response{Code: code, Error: code.Message(Locale(c), args...), Details: details})
+}
+
+// Internal journalise l'erreur technique et répond INTERNAL_ERROR sans la divulguer
+func Internal(c *gin.Context, err error) {
+	if err != nil {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	} else {
+		log.Printf("%s %s: erreur interne", c.Request.Method, c.Request.URL.Path)
+	}
+	Abort(c, InternalError)
+}
+
+func write(c *gin.Context, response Response) {
+	c.AbortWithStatusJSON(response.Code.Status(), response)
+}
+
+// CatalogEntry décrit un code du catalogue, pour la documentation des clients
+type CatalogEntry struct {
+	Code     Code              `json:"code"`
+	Status   int               `json:"status"`
+	Messages map[string]string `json:"messages"`
+}
+
+// Catalog retourne tous les codes d'erreur, triés, avec leurs messages (formats non remplis)
+func Catalog() []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(catalog))
+	for code, e := range catalog {
+		entries = append(entries, CatalogEntry{Code: code, Status: e.Status, Messages: e.Messages})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Code < entries[j].Code
+	})
+	return entries
+}
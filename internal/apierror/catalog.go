@@ -0,0 +1,601 @@
+package apierror
+
+import "net/http"
+
+// Code identifie une erreur de l'API de façon stable : les clients s'appuient dessus
+// plutôt que sur le message, qui dépend de la langue
+type Code string
+
+// Erreurs génériques
+const (
+	RouteNotFound      Code = "ROUTE_NOT_FOUND"
+	InvalidRequest     Code = "INVALID_REQUEST"
+	MalformedJSON      Code = "MALFORMED_JSON"
+	RequestBodyMissing Code = "REQUEST_BODY_MISSING"
+	RequestTooLarge    Code = "REQUEST_TOO_LARGE"
+	ValidationFailed   Code = "VALIDATION_FAILED"
+	InvalidID          Code = "INVALID_ID"
+	MissingID          Code = "MISSING_ID"
+	InvalidDate        Code = "INVALID_DATE"
+	InvalidDateRange   Code = "INVALID_DATE_RANGE"
+	DateInPast         Code = "DATE_IN_PAST"
+	NothingToUpdate    Code = "NOTHING_TO_UPDATE"
+	InternalError      Code = "INTERNAL_ERROR"
+	ServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+)
+
+// Authentification, comptes et rôles
+const (
+	TokenMissing            Code = "TOKEN_MISSING"
+	TokenInvalid            Code = "TOKEN_INVALID"
+	SessionInvalid          Code = "SESSION_INVALID"
+	Unauthenticated         Code = "UNAUTHENTICATED"
+	Forbidden               Code = "FORBIDDEN"
+	InvalidCredentials      Code = "INVALID_CREDENTIALS"
+	EmailNotVerified        Code = "EMAIL_NOT_VERIFIED"
+	EmailTaken              Code = "EMAIL_TAKEN"
+	RefreshTokenInvalid     Code = "REFRESH_TOKEN_INVALID"
+	ResetLinkInvalid        Code = "RESET_LINK_INVALID"
+	VerificationLinkInvalid Code = "VERIFICATION_LINK_INVALID"
+	UserNotFound            Code = "USER_NOT_FOUND"
+	RoleNotFound            Code = "ROLE_NOT_FOUND"
+	UnknownRole             Code = "UNKNOWN_ROLE"
+	RoleSlugTaken           Code = "ROLE_SLUG_TAKEN"
+	RoleSystemProtected     Code = "ROLE_SYSTEM_PROTECTED"
+	RolePermissionRequired  Code = "ROLE_PERMISSION_REQUIRED"
+	UnknownPermissions      Code = "UNKNOWN_PERMISSIONS"
+)
+
+// Propriétés, recherche et photos
+const (
+	PropertyNotFound         Code = "PROPERTY_NOT_FOUND"
+	PropertyForbidden        Code = "PROPERTY_FORBIDDEN"
+	PropertyNameTaken        Code = "PROPERTY_NAME_TAKEN"
+	InvalidCoordinates       Code = "INVALID_COORDINATES"
+	InvalidCursor            Code = "INVALID_CURSOR"
+	InvalidNear              Code = "INVALID_NEAR"
+	DistanceSortRequiresNear Code = "DISTANCE_SORT_REQUIRES_NEAR"
+	DistanceSortWithText     Code = "DISTANCE_SORT_WITH_TEXT"
+	PhotoNotFound            Code = "PHOTO_NOT_FOUND"
+	PhotoLimitReached        Code = "PHOTO_LIMIT_REACHED"
+	PhotoOrderInvalid        Code = "PHOTO_ORDER_INVALID"
+	CoverRequired            Code = "COVER_REQUIRED"
+	PhotosChanged            Code = "PHOTOS_CHANGED"
+	CaptionTooLong           Code = "CAPTION_TOO_LONG"
+	FileMissing              Code = "FILE_MISSING"
+	FileUnreadable           Code = "FILE_UNREADABLE"
+	FileTooLarge             Code = "FILE_TOO_LARGE"
+	UnsupportedMediaType     Code = "UNSUPPORTED_MEDIA_TYPE"
+	ImageInvalid             Code = "IMAGE_INVALID"
+	ImageTooLarge            Code = "IMAGE_TOO_LARGE"
+)
+
+// Liens voyageurs, réservations et calendrier
+const (
+	GuestLinkNotFound           Code = "GUEST_LINK_NOT_FOUND"
+	GuestLinkRevoked            Code = "GUEST_LINK_REVOKED"
+	GuestLinkExpired            Code = "GUEST_LINK_EXPIRED"
+	GuestLinkNotStarted         Code = "GUEST_LINK_NOT_STARTED"
+	GuestLinkLocked             Code = "GUEST_LINK_LOCKED"
+	PinRequired                 Code = "PIN_REQUIRED"
+	PinInvalid                  Code = "PIN_INVALID"
+	ReservationNotFound         Code = "RESERVATION_NOT_FOUND"
+	ReservationOverlap          Code = "RESERVATION_OVERLAP"
+	ReservationStatusTransition Code = "RESERVATION_STATUS_TRANSITION"
+	ReservationStayLocked       Code = "RESERVATION_STAY_LOCKED"
+	DatesBlocked                Code = "DATES_BLOCKED"
+	CalendarBusy                Code = "CALENDAR_BUSY"
+	CapacityExceeded            Code = "CAPACITY_EXCEEDED"
+	CalendarBlockNotFound       Code = "CALENDAR_BLOCK_NOT_FOUND"
+	CalendarBlockImported       Code = "CALENDAR_BLOCK_IMPORTED"
+	CalendarFeedNotFound        Code = "CALENDAR_FEED_NOT_FOUND"
+	CalendarFeedURLInvalid      Code = "CALENDAR_FEED_URL_INVALID"
+	CalendarFeedHostUnknown     Code = "CALENDAR_FEED_HOST_UNKNOWN"
+	CalendarFeedHostForbidden   Code = "CALENDAR_FEED_HOST_FORBIDDEN"
+	CalendarSyncFailed          Code = "CALENDAR_SYNC_FAILED"
+)
+
+// Livret, QR codes et traductions
+const (
+	WifiNotConfigured        Code = "WIFI_NOT_CONFIGURED"
+	QRCodeInvalidFormat      Code = "QRCODE_INVALID_FORMAT"
+	QRCodeInvalidSize        Code = "QRCODE_INVALID_SIZE"
+	QRCodeInvalidLevel       Code = "QRCODE_INVALID_LEVEL"
+	LocaleInvalid            Code = "LOCALE_INVALID"
+	LocaleUnsupported        Code = "LOCALE_UNSUPPORTED"
+	TranslationNotFound      Code = "TRANSLATION_NOT_FOUND"
+	TranslationDefaultLocale Code = "TRANSLATION_DEFAULT_LOCALE"
+	TranslationUnknownFields Code = "TRANSLATION_UNKNOWN_FIELDS"
+	TranslationLocaleExists  Code = "TRANSLATION_LOCALE_EXISTS"
+)
+
+// Historique et publication des propriétés
+const (
+	RevisionNotFound        Code = "REVISION_NOT_FOUND"
+	RevisionInvalid         Code = "REVISION_INVALID"
+	RevisionSectionsInvalid Code = "REVISION_SECTIONS_INVALID"
+	DraftNotFound           Code = "DRAFT_NOT_FOUND"
+	DraftChanged            Code = "DRAFT_CHANGED"
+	PropertyNotPublished    Code = "PROPERTY_NOT_PUBLISHED"
+	PropertyNotReady        Code = "PROPERTY_NOT_READY"
+	NothingToPublish        Code = "NOTHING_TO_PUBLISH"
+	PublicationDateInPast   Code = "PUBLICATION_DATE_IN_PAST"
+	PublicationNotScheduled Code = "PUBLICATION_NOT_SCHEDULED"
+)
+
+// Modèles de section
+const (
+	TemplateNotFound       Code = "TEMPLATE_NOT_FOUND"
+	TemplateForbidden      Code = "TEMPLATE_FORBIDDEN"
+	TemplateSectionInvalid Code = "TEMPLATE_SECTION_INVALID"
+	TemplateContentInvalid Code = "TEMPLATE_CONTENT_INVALID"
+	TemplateNotLinked      Code = "TEMPLATE_NOT_LINKED"
+)
+
+// Collaborateurs des propriétés
+const (
+	MemberNotFound          Code = "MEMBER_NOT_FOUND"
+	MemberAlreadyExists     Code = "MEMBER_ALREADY_EXISTS"
+	InvitationInvalid       Code = "INVITATION_INVALID"
+	InvitationEmailMismatch Code = "INVITATION_EMAIL_MISMATCH"
+)
+
+// Organisations
+const (
+	OrganizationNotFound       Code = "ORGANIZATION_NOT_FOUND"
+	OrganizationForbidden      Code = "ORGANIZATION_FORBIDDEN"
+	OrganizationMemberNotFound Code = "ORGANIZATION_MEMBER_NOT_FOUND"
+	OrganizationMemberExists   Code = "ORGANIZATION_MEMBER_EXISTS"
+	OrganizationLastOwner      Code = "ORGANIZATION_LAST_OWNER"
+	PropertyOrganizationOwned  Code = "PROPERTY_ORGANIZATION_OWNED"
+)
+
+// entry associe à un code son statut HTTP et ses messages par langue.
+// Les messages sont des formats fmt : les arguments sont passés par le handler.
+type entry struct {
+	Status   int
+	Messages map[string]string
+}
+
+var catalog = map[Code]entry{
+	RouteNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Route introuvable",
+		"en": "Route not found",
+	}},
+	InvalidRequest: {http.StatusBadRequest, map[string]string{
+		"fr": "Données invalides",
+		"en": "Invalid request data",
+	}},
+	MalformedJSON: {http.StatusBadRequest, map[string]string{
+		"fr": "Le corps de la requête n'est pas un JSON valide",
+		"en": "The request body is not valid JSON",
+	}},
+	RequestBodyMissing: {http.StatusBadRequest, map[string]string{
+		"fr": "Le corps de la requête est vide",
+		"en": "The request body is empty",
+	}},
+	RequestTooLarge: {http.StatusRequestEntityTooLarge, map[string]string{
+		"fr": "Le corps de la requête est trop volumineux",
+		"en": "The request body is too large",
+	}},
+	ValidationFailed: {http.StatusBadRequest, map[string]string{
+		"fr": "Certains champs sont invalides",
+		"en": "Some fields are invalid",
+	}},
+	InvalidID: {http.StatusBadRequest, map[string]string{
+		"fr": "Identifiant invalide",
+		"en": "Invalid identifier",
+	}},
+	MissingID: {http.StatusBadRequest, map[string]string{
+		"fr": "Identifiant manquant",
+		"en": "Missing identifier",
+	}},
+	InvalidDate: {http.StatusBadRequest, map[string]string{
+		"fr": "Date %s invalide (format attendu AAAA-MM-JJ)",
+		"en": "Invalid %s date (expected format YYYY-MM-DD)",
+	}},
+	InvalidDateRange: {http.StatusBadRequest, map[string]string{
+		"fr": "La date de fin doit être postérieure à la date de début",
+		"en": "The end date must be after the start date",
+	}},
+	DateInPast: {http.StatusBadRequest, map[string]string{
+		"fr": "La date de fin doit être dans le futur",
+		"en": "The end date must be in the future",
+	}},
+	NothingToUpdate: {http.StatusBadRequest, map[string]string{
+		"fr": "Aucune donnée à mettre à jour",
+		"en": "Nothing to update",
+	}},
+	InternalError: {http.StatusInternalServerError, map[string]string{
+		"fr": "Une erreur interne est survenue, veuillez réessayer plus tard",
+		"en": "An internal error occurred, please try again later",
+	}},
+	ServiceUnavailable: {http.StatusServiceUnavailable, map[string]string{
+		"fr": "Le service n'est pas prêt à recevoir des requêtes",
+		"en": "The service is not ready to accept requests",
+	}},
+
+	TokenMissing: {http.StatusUnauthorized, map[string]string{
+		"fr": "Token manquant",
+		"en": "Missing token",
+	}},
+	TokenInvalid: {http.StatusUnauthorized, map[string]string{
+		"fr": "Token invalide ou expiré",
+		"en": "Invalid or expired token",
+	}},
+	SessionInvalid: {http.StatusUnauthorized, map[string]string{
+		"fr": "Session expirée ou révoquée",
+		"en": "Session expired or revoked",
+	}},
+	Unauthenticated: {http.StatusUnauthorized, map[string]string{
+		"fr": "Utilisateur non authentifié",
+		"en": "Authentication required",
+	}},
+	Forbidden: {http.StatusForbidden, map[string]string{
+		"fr": "Accès refusé. Permissions insuffisantes pour accéder à cette ressource",
+		"en": "Access denied. Insufficient permissions to access this resource",
+	}},
+	InvalidCredentials: {http.StatusUnauthorized, map[string]string{
+		"fr": "Email ou mot de passe incorrect",
+		"en": "Incorrect email or password",
+	}},
+	EmailNotVerified: {http.StatusForbidden, map[string]string{
+		"fr": "Veuillez confirmer votre adresse email avant de vous connecter",
+		"en": "Please confirm your email address before logging in",
+	}},
+	EmailTaken: {http.StatusConflict, map[string]string{
+		"fr": "Cet email est déjà utilisé",
+		"en": "This email is already in use",
+	}},
+	RefreshTokenInvalid: {http.StatusUnauthorized, map[string]string{
+		"fr": "Refresh token invalide",
+		"en": "Invalid refresh token",
+	}},
+	ResetLinkInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "Lien de réinitialisation invalide ou expiré",
+		"en": "Invalid or expired reset link",
+	}},
+	VerificationLinkInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "Lien de vérification invalide ou expiré",
+		"en": "Invalid or expired verification link",
+	}},
+	UserNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Utilisateur introuvable",
+		"en": "User not found",
+	}},
+	RoleNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Rôle introuvable",
+		"en": "Role not found",
+	}},
+	UnknownRole: {http.StatusBadRequest, map[string]string{
+		"fr": "Rôle inconnu",
+		"en": "Unknown role",
+	}},
+	RoleSlugTaken: {http.StatusConflict, map[string]string{
+		"fr": "Un rôle avec ce slug existe déjà",
+		"en": "A role with this slug already exists",
+	}},
+	RoleSystemProtected: {http.StatusForbidden, map[string]string{
+		"fr": "Impossible de supprimer un rôle système",
+		"en": "System roles cannot be deleted",
+	}},
+	RolePermissionRequired: {http.StatusBadRequest, map[string]string{
+		"fr": "Le rôle super administrateur doit conserver la permission %s",
+		"en": "The super administrator role must keep the %s permission",
+	}},
+	UnknownPermissions: {http.StatusBadRequest, map[string]string{
+		"fr": "Permissions inconnues",
+		"en": "Unknown permissions",
+	}},
+
+	PropertyNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Propriété introuvable",
+		"en": "Property not found",
+	}},
+	PropertyForbidden: {http.StatusForbidden, map[string]string{
+		"fr": "Vous n'êtes pas autorisé à gérer cette propriété",
+		"en": "You are not allowed to manage this property",
+	}},
+	PropertyNameTaken: {http.StatusConflict, map[string]string{
+		"fr": "Vous avez déjà un logement avec ce nom",
+		"en": "You already have a property with this name",
+	}},
+	InvalidCoordinates: {http.StatusBadRequest, map[string]string{
+		"fr": "Coordonnées invalides : latitude entre -90 et 90, longitude entre -180 et 180",
+		"en": "Invalid coordinates: latitude between -90 and 90, longitude between -180 and 180",
+	}},
+	InvalidCursor: {http.StatusBadRequest, map[string]string{
+		"fr": "Curseur de pagination invalide",
+		"en": "Invalid pagination cursor",
+	}},
+	InvalidNear: {http.StatusBadRequest, map[string]string{
+		"fr": "Paramètre near invalide (format attendu latitude,longitude)",
+		"en": "Invalid near parameter (expected format latitude,longitude)",
+	}},
+	DistanceSortRequiresNear: {http.StatusBadRequest, map[string]string{
+		"fr": "Le tri par distance nécessite le paramètre near",
+		"en": "Sorting by distance requires the near parameter",
+	}},
+	DistanceSortWithText: {http.StatusBadRequest, map[string]string{
+		"fr": "Le tri par distance n'est pas compatible avec la recherche textuelle",
+		"en": "Sorting by distance cannot be combined with a text search",
+	}},
+	PhotoNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Photo introuvable",
+		"en": "Photo not found",
+	}},
+	PhotoLimitReached: {http.StatusConflict, map[string]string{
+		"fr": "Une propriété ne peut pas avoir plus de %d photos",
+		"en": "A property cannot have more than %d photos",
+	}},
+	PhotoOrderInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "L'ordre doit contenir chaque photo de la propriété exactement une fois",
+		"en": "The order must list every photo of the property exactly once",
+	}},
+	CoverRequired: {http.StatusBadRequest, map[string]string{
+		"fr": "Définissez une autre photo comme couverture pour retirer celle-ci",
+		"en": "Set another photo as cover before removing this one",
+	}},
+	PhotosChanged: {http.StatusConflict, map[string]string{
+		"fr": "Les photos ont été modifiées entre-temps, veuillez recharger la propriété",
+		"en": "The photos were modified in the meantime, please reload the property",
+	}},
+	CaptionTooLong: {http.StatusBadRequest, map[string]string{
+		"fr": "La légende ne doit pas dépasser %d caractères",
+		"en": "The caption must not exceed %d characters",
+	}},
+	FileMissing: {http.StatusBadRequest, map[string]string{
+		"fr": "Fichier manquant (champ multipart \"file\")",
+		"en": "Missing file (multipart field \"file\")",
+	}},
+	FileUnreadable: {http.StatusBadRequest, map[string]string{
+		"fr": "Impossible de lire le fichier envoyé",
+		"en": "The uploaded file could not be read",
+	}},
+	FileTooLarge: {http.StatusRequestEntityTooLarge, map[string]string{
+		"fr": "Fichier trop volumineux (%d Mo maximum)",
+		"en": "File too large (%d MB maximum)",
+	}},
+	UnsupportedMediaType: {http.StatusUnsupportedMediaType, map[string]string{
+		"fr": "Format d'image non supporté (JPEG, PNG ou WebP attendu)",
+		"en": "Unsupported image format (JPEG, PNG or WebP expected)",
+	}},
+	ImageInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "Image illisible ou corrompue",
+		"en": "The image is unreadable or corrupted",
+	}},
+	ImageTooLarge: {http.StatusRequestEntityTooLarge, map[string]string{
+		"fr": "Image trop grande (%d mégapixels maximum)",
+		"en": "Image too large (%d megapixels maximum)",
+	}},
+
+	GuestLinkNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Lien voyageur introuvable",
+		"en": "Guest link not found",
+	}},
+	GuestLinkRevoked: {http.StatusGone, map[string]string{
+		"fr": "Ce lien a été révoqué",
+		"en": "This link has been revoked",
+	}},
+	GuestLinkExpired: {http.StatusGone, map[string]string{
+		"fr": "Ce lien a expiré",
+		"en": "This link has expired",
+	}},
+	GuestLinkNotStarted: {http.StatusForbidden, map[string]string{
+		"fr": "Ce lien n'est pas encore actif",
+		"en": "This link is not active yet",
+	}},
+	GuestLinkLocked: {http.StatusForbidden, map[string]string{
+		"fr": "Ce lien est bloqué suite à trop de tentatives",
+		"en": "This link is locked after too many attempts",
+	}},
+	PinRequired: {http.StatusUnauthorized, map[string]string{
+		"fr": "Code PIN requis",
+		"en": "PIN code required",
+	}},
+	PinInvalid: {http.StatusUnauthorized, map[string]string{
+		"fr": "Code PIN incorrect",
+		"en": "Incorrect PIN code",
+	}},
+	ReservationNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Réservation introuvable",
+		"en": "Reservation not found",
+	}},
+	ReservationOverlap: {http.StatusConflict, map[string]string{
+		"fr": "Ces dates chevauchent une autre réservation",
+		"en": "These dates overlap another reservation",
+	}},
+	ReservationStatusTransition: {http.StatusConflict, map[string]string{
+		"fr": "Changement de statut impossible de %s vers %s",
+		"en": "Cannot change status from %s to %s",
+	}},
+	ReservationStayLocked: {http.StatusConflict, map[string]string{
+		"fr": "Impossible de modifier le séjour d'une réservation %s",
+		"en": "Cannot change the stay of a %s reservation",
+	}},
+	DatesBlocked: {http.StatusConflict, map[string]string{
+		"fr": "Ces dates sont bloquées dans le calendrier",
+		"en": "These dates are blocked in the calendar",
+	}},
+	CalendarBusy: {http.StatusConflict, map[string]string{
+		"fr": "Le calendrier est en cours de modification, veuillez réessayer",
+		"en": "The calendar is being updated, please try again",
+	}},
+	CapacityExceeded: {http.StatusBadRequest, map[string]string{
+		"fr": "Le nombre de voyageurs dépasse la capacité maximale du logement (%d)",
+		"en": "The number of guests exceeds the property's maximum capacity (%d)",
+	}},
+	CalendarBlockNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Période bloquée introuvable",
+		"en": "Blocked period not found",
+	}},
+	CalendarBlockImported: {http.StatusConflict, map[string]string{
+		"fr": "Cette période provient d'un calendrier externe : supprimez-la sur la plateforme d'origine",
+		"en": "This period comes from an external calendar: remove it on the originating platform",
+	}},
+	CalendarFeedNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Calendrier externe introuvable",
+		"en": "External calendar not found",
+	}},
+	CalendarFeedURLInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "L'URL du calendrier doit commencer par https://, http:// ou webcal://",
+		"en": "The calendar URL must start with https://, http:// or webcal://",
+	}},
+	CalendarFeedHostUnknown: {http.StatusBadRequest, map[string]string{
+		"fr": "Le nom de domaine du calendrier est introuvable",
+		"en": "The calendar's domain name cannot be resolved",
+	}},
+	CalendarFeedHostForbidden: {http.StatusBadRequest, map[string]string{
+		"fr": "L'URL du calendrier doit désigner une adresse publique",
+		"en": "The calendar URL must point to a public address",
+	}},
+	CalendarSyncFailed: {http.StatusBadGateway, map[string]string{
+		"fr": "La synchronisation du calendrier externe a échoué",
+		"en": "The external calendar synchronization failed",
+	}},
+
+	WifiNotConfigured: {http.StatusNotFound, map[string]string{
+		"fr": "Aucun réseau Wi-Fi renseigné pour cette propriété",
+		"en": "No Wi-Fi network is set up for this property",
+	}},
+	QRCodeInvalidFormat: {http.StatusBadRequest, map[string]string{
+		"fr": "Format invalide (png ou svg)",
+		"en": "Invalid format (png or svg)",
+	}},
+	QRCodeInvalidSize: {http.StatusBadRequest, map[string]string{
+		"fr": "Taille invalide (entre %d et %d pixels)",
+		"en": "Invalid size (between %d and %d pixels)",
+	}},
+	QRCodeInvalidLevel: {http.StatusBadRequest, map[string]string{
+		"fr": "Niveau de correction invalide (L, M, Q ou H)",
+		"en": "Invalid error correction level (L, M, Q or H)",
+	}},
+	LocaleInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "Langue invalide (code ISO 639-1 attendu, ex. fr, en)",
+		"en": "Invalid language (ISO 639-1 code expected, e.g. fr, en)",
+	}},
+	LocaleUnsupported: {http.StatusBadRequest, map[string]string{
+		"fr": "Langue non supportée",
+		"en": "Unsupported language",
+	}},
+	TranslationNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Aucune traduction dans cette langue",
+		"en": "No translation in this language",
+	}},
+	TranslationDefaultLocale: {http.StatusBadRequest, map[string]string{
+		"fr": "Cette langue est celle des contenus de base : modifiez directement la propriété",
+		"en": "This is the language of the base content: edit the property directly",
+	}},
+	TranslationUnknownFields: {http.StatusBadRequest, map[string]string{
+		"fr": "Champs non traduisibles ou inexistants",
+		"en": "Fields that do not exist or cannot be translated",
+	}},
+	TranslationLocaleExists: {http.StatusConflict, map[string]string{
+		"fr": "Une traduction existe déjà dans cette langue : supprimez-la avant d'en faire la langue par défaut",
+		"en": "A translation already exists in this language: delete it before making it the default language",
+	}},
+
+	RevisionNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Révision non trouvée",
+		"en": "Revision not found",
+	}},
+	RevisionInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "Numéro de révision invalide",
+		"en": "Invalid revision number",
+	}},
+	RevisionSectionsInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "Sections inconnues ou non restaurables",
+		"en": "Unknown sections or sections that cannot be restored",
+	}},
+	DraftNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Aucune modification en attente pour cette propriété",
+		"en": "This property has no pending changes",
+	}},
+	DraftChanged: {http.StatusConflict, map[string]string{
+		"fr": "Les modifications en attente ont changé pendant la publication, veuillez réessayer",
+		"en": "The pending changes were modified during publication, please try again",
+	}},
+	PropertyNotPublished: {http.StatusConflict, map[string]string{
+		"fr": "Cette propriété n'est pas publiée",
+		"en": "This property is not published",
+	}},
+	PropertyNotReady: {http.StatusUnprocessableEntity, map[string]string{
+		"fr": "La propriété ne remplit pas les conditions de publication",
+		"en": "The property does not meet the publication requirements",
+	}},
+	NothingToPublish: {http.StatusConflict, map[string]string{
+		"fr": "Cette propriété est déjà publiée et n'a aucune modification en attente",
+		"en": "This property is already published and has no pending changes",
+	}},
+	PublicationDateInPast: {http.StatusBadRequest, map[string]string{
+		"fr": "La date de publication doit être dans le futur",
+		"en": "The publication date must be in the future",
+	}},
+	PublicationNotScheduled: {http.StatusNotFound, map[string]string{
+		"fr": "Aucune publication n'est programmée pour cette propriété",
+		"en": "No publication is scheduled for this property",
+	}},
+
+	TemplateNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Modèle non trouvé",
+		"en": "Template not found",
+	}},
+	TemplateForbidden: {http.StatusForbidden, map[string]string{
+		"fr": "Vous n'êtes pas autorisé à utiliser ce modèle",
+		"en": "You are not allowed to use this template",
+	}},
+	TemplateSectionInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "Cette section ne peut pas être enregistrée comme modèle",
+		"en": "This section cannot be saved as a template",
+	}},
+	TemplateContentInvalid: {http.StatusBadRequest, map[string]string{
+		"fr": "Indiquez une propriété ou un contenu valide pour la section du modèle",
+		"en": "Provide a property or valid content for the template section",
+	}},
+	TemplateNotLinked: {http.StatusNotFound, map[string]string{
+		"fr": "Cette propriété n'est pas liée au modèle",
+		"en": "This property is not linked to the template",
+	}},
+
+	MemberNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Cet utilisateur ne collabore pas sur cette propriété",
+		"en": "This user is not a collaborator on this property",
+	}},
+	MemberAlreadyExists: {http.StatusConflict, map[string]string{
+		"fr": "Cette personne fait déjà partie de l'équipe de la propriété",
+		"en": "This person is already on the property team",
+	}},
+	InvitationInvalid: {http.StatusNotFound, map[string]string{
+		"fr": "Invitation invalide, expirée ou déjà utilisée",
+		"en": "Invalid, expired or already used invitation",
+	}},
+	InvitationEmailMismatch: {http.StatusForbidden, map[string]string{
+		"fr": "Cette invitation a été envoyée à une autre adresse email",
+		"en": "This invitation was sent to a different email address",
+	}},
+
+	OrganizationNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Organisation non trouvée",
+		"en": "Organization not found",
+	}},
+	OrganizationForbidden: {http.StatusForbidden, map[string]string{
+		"fr": "Votre rôle dans l'organisation ne permet pas cette action",
+		"en": "Your role in the organization does not allow this action",
+	}},
+	OrganizationMemberNotFound: {http.StatusNotFound, map[string]string{
+		"fr": "Cet utilisateur n'est pas membre de l'organisation",
+		"en": "This user is not a member of the organization",
+	}},
+	OrganizationMemberExists: {http.StatusConflict, map[string]string{
+		"fr": "Cet utilisateur est déjà membre de l'organisation",
+		"en": "This user is already a member of the organization",
+	}},
+	OrganizationLastOwner: {http.StatusConflict, map[string]string{
+		"fr": "L'organisation doit garder au moins un propriétaire : nommez-en un autre ou supprimez-la",
+		"en": "The organization must keep at least one owner: appoint another one or delete it",
+	}},
+	PropertyOrganizationOwned: {http.StatusConflict, map[string]string{
+		"fr": "Cette propriété appartient à une organisation",
+		"en": "This property belongs to an organization",
+	}},
+}
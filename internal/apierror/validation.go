@@ -0,0 +1,238 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError décrit un champ rejeté par la validation. Field est le chemin JSON du champ
+// ("wifi.networkName"), Rule la règle non respectée ("required", "max"...).
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ruleMessages traduit les règles de validation. Pour min, max et len, le message dépend
+// du type du champ : longueur d'un texte, nombre d'éléments d'une liste ou valeur d'un nombre.
+var ruleMessages = map[string]map[string]string{
+	"required": {
+		"fr": "Ce champ est obligatoire",
+		"en": "This field is required",
+	},
+	"email": {
+		"fr": "Adresse email invalide",
+		"en": "Invalid email address",
+	},
+	"url": {
+		"fr": "URL invalide",
+		"en": "Invalid URL",
+	},
+	"numeric": {
+		"fr": "Doit contenir uniquement des chiffres",
+		"en": "Must contain only digits",
+	},
+	"oneof": {
+		"fr": "Doit valoir l'une des valeurs suivantes : %s",
+		"en": "Must be one of: %s",
+	},
+	"eq": {
+		"fr": "Doit valoir %s",
+		"en": "Must be %s",
+	},
+	"gt": {
+		"fr": "Doit être supérieur à %s",
+		"en": "Must be greater than %s",
+	},
+	"gte": {
+		"fr": "Doit être supérieur ou égal à %s",
+		"en": "Must be greater than or equal to %s",
+	},
+	"lt": {
+		"fr": "Doit être inférieur à %s",
+		"en": "Must be less than %s",
+	},
+	"lte": {
+		"fr": "Doit être inférieur ou égal à %s",
+		"en": "Must be less than or equal to %s",
+	},
+	"min.string": {
+		"fr": "Doit contenir au moins %s caractères",
+		"en": "Must be at least %s characters long",
+	},
+	"max.string": {
+		"fr": "Ne doit pas dépasser %s caractères",
+		"en": "Must be at most %s characters long",
+	},
+	"len.string": {
+		"fr": "Doit contenir exactement %s caractères",
+		"en": "Must be exactly %s characters long",
+	},
+	"min.list": {
+		"fr": "Doit contenir au moins %s éléments",
+		"en": "Must contain at least %s items",
+	},
+	"max.list": {
+		"fr": "Ne doit pas contenir plus de %s éléments",
+		"en": "Must contain at most %s items",
+	},
+	"len.list": {
+		"fr": "Doit contenir exactement %s éléments",
+		"en": "Must contain exactly %s items",
+	},
+	"min.number": {
+		"fr": "Doit être supérieur ou égal à %s",
+		"en": "Must be greater than or equal to %s",
+	},
+	"max.number": {
+		"fr": "Doit être inférieur ou égal à %s",
+		"en": "Must be less than or equal to %s",
+	},
+	"len.number": {
+		"fr": "Doit valoir %s",
+		"en": "Must be %s",
+	},
+	"type": {
+		"fr": "Type invalide (%s attendu)",
+		"en": "Invalid type (%s expected)",
+	},
+	"invalid": {
+		"fr": "Valeur invalide",
+		"en": "Invalid value",
+	},
+}
+
+// RegisterFieldNames fait remonter les noms JSON des champs (ou, à défaut, les noms
+// de formulaire) dans les erreurs de validation, à la place des noms Go
+func RegisterFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// AbortBinding traduit une erreur de ShouldBindJSON / ShouldBindQuery : JSON mal formé,
+// corps vide, type inattendu ou règles de validation non respectées, champ par champ
+func AbortBinding(c *gin.Context, err error) {
+	locale := Locale(c)
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var validationErrs validator.ValidationErrors
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		Abort(c, RequestBodyMissing)
+	case errors.As(err, &maxBytesErr):
+		Abort(c, RequestTooLarge)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		Abort(c, MalformedJSON)
+	case errors.As(err, &typeErr):
+		write(c, Response{
+			Code:  ValidationFailed,
+			Error: ValidationFailed.Message(locale),
+			Fields: []FieldError{{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Message: ruleMessage(locale, "type", jsonType(typeErr.Type)),
+			}},
+		})
+	case errors.As(err, &validationErrs):
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, fieldError(locale, fe))
+		}
+		write(c, Response{Code: ValidationFailed, Error: ValidationFailed.Message(locale), Fields: fields})
+	default:
+		Abort(c, InvalidRequest)
+	}
+}
+
+func fieldError(locale string, fe validator.FieldError) FieldError {
+	// Le namespace commence par le nom de la structure racine (CreatePropertyRequest.wifi.password)
+	field := fe.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+
+	key := fe.Tag()
+	switch key {
+	case "min", "max", "len":
+		key += "." + kindOf(fe.Kind())
+	}
+
+	param := fe.Param()
+	if fe.Tag() == "oneof" {
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+
+	return FieldError{Field: field, Rule: fe.Tag(), Message: ruleMessage(locale, key, param)}
+}
+
+func ruleMessage(locale, key, param string) string {
+	messages, ok := ruleMessages[key]
+	if !ok {
+		messages = ruleMessages["invalid"]
+	}
+	format, ok := messages[locale]
+	if !ok {
+		format = messages[DefaultLocale]
+	}
+	if strings.Contains(format, "%s") {
+		return strings.Replace(format, "%s", param, 1)
+	}
+	return format
+}
+
+func kindOf(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "list"
+	default:
+		return "number"
+	}
+}
+
+// jsonType retourne le nom JSON du type Go attendu
+func jsonType(t reflect.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
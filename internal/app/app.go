@@ -0,0 +1,165 @@
+// Package app assemble l'application : configuration, base de données, repositories,
+// services et handlers sont construits explicitement puis transmis au routeur. Chaque App a
+// sa configuration, ses stores, son trousseau de chiffrement et sa résolution des permissions :
+// aucun état n'est partagé, ce qui permet d'en créer plusieurs dans un même processus (tests).
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"onestay-back/internal/calendarsync"
+	"onestay-back/internal/config"
+	"onestay-back/internal/database"
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/handlers"
+	"onestay-back/internal/mailer"
+	"onestay-back/internal/middleware"
+	"onestay-back/internal/publishing"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/readiness"
+	"onestay-back/internal/repository"
+	"onestay-back/internal/router"
+	"onestay-back/internal/storage"
+	"onestay-back/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// App contient les dépendances de l'application
+type App struct {
+	Config    *config.Config
+	DB        *mongo.Database     // nil lorsque l'application est construite sur d'autres stores
+	Keyring   *fieldcrypt.Keyring // trousseau des stores MongoDB ; nil sur d'autres stores ou sans chiffrement
+	Stores    *repository.Stores
+	Tokens    *utils.TokenService
+	RBAC      *rbac.Service
+	Mailer    mailer.Sender
+	Storage   storage.Storage
+	Publisher *publishing.Publisher
+	Syncer    *calendarsync.Syncer
+	Handlers  *router.Handlers
+	Router    *gin.Engine
+
+	// shuttingDown est levé par Serve dès le début de l'arrêt : /readyz échoue alors
+	shuttingDown atomic.Bool
+}
+
+// Options remplace certaines dépendances construites par défaut à partir de la configuration
+type Options struct {
+	Mailer  mailer.Sender
+	Storage storage.Storage
+}
+
+// New connecte MongoDB, crée les index et construit l'application. Close libère la connexion.
+func New(ctx context.Context, cfg *config.Config) (*App, error) {
+	keyring, err := fieldcrypt.Load(cfg.FieldEncryptionKeys, cfg.FieldEncryptionActiveKey)
+	if err != nil {
+		return nil, fmt.Errorf("clés de chiffrement: %w", err)
+	}
+
+	db, err := database.Connect(cfg.MongoURI, cfg.DBName)
+	if err != nil {
+		return nil, fmt.Errorf("connexion à MongoDB: %w", err)
+	}
+
+	if err := repository.EnsureIndexes(ctx, db); err != nil {
+		database.Disconnect(db)
+		return nil, fmt.Errorf("création des index: %w", err)
+	}
+
+	a := NewWithStores(cfg, repository.NewStores(db, keyring), Options{})
+	a.DB = db
+	a.Keyring = keyring
+	return a, nil
+}
+
+// NewWithStores construit l'application sur des stores déjà créés (MongoDB ou en mémoire)
+func NewWithStores(cfg *config.Config, stores *repository.Stores, opts Options) *App {
+	a := &App{
+		Config:  cfg,
+		Stores:  stores,
+		Tokens:  utils.NewTokenService(cfg.JWTSecret, cfg.AccessTokenTTL),
+		RBAC:    rbac.NewService(stores.Roles, stores.PropertyMembers, stores.Organizations),
+		Mailer:  opts.Mailer,
+		Storage: opts.Storage,
+	}
+	if a.Mailer == nil {
+		a.Mailer = mailer.New(cfg)
+	}
+	if a.Storage == nil {
+		a.Storage = storage.New(cfg)
+	}
+
+	a.Publisher = publishing.NewPublisher(stores.Properties, stores.PropertyDrafts, stores.PropertyRevisions, readiness.New(cfg.PublicationRules))
+	a.Syncer = calendarsync.NewSyncer(stores)
+
+	a.Handlers = &router.Handlers{
+		RequireAuth:  middleware.AuthMiddleware(a.Tokens, stores.Sessions),
+		OptionalAuth: middleware.OptionalAuthMiddleware(a.Tokens, stores.Sessions),
+		RequirePermission: func(permissions ...string) gin.HandlerFunc {
+			return middleware.RequirePermission(a.RBAC, permissions...)
+		},
+
+		Auth:         handlers.NewAuthHandler(cfg, stores, a.Tokens, a.Mailer, a.RBAC),
+		Property:     handlers.NewPropertyHandler(cfg, stores, a.Publisher, a.Storage, a.RBAC),
+		GuestLink:    handlers.NewGuestLinkHandler(cfg, stores, a.RBAC),
+		Reservation:  handlers.NewReservationHandler(stores, a.RBAC),
+		Calendar:     handlers.NewCalendarHandler(stores, a.Syncer, a.RBAC),
+		Image:        handlers.NewPropertyImageHandler(cfg, stores, a.Storage, a.RBAC),
+		Guidebook:    handlers.NewGuidebookHandler(stores, a.RBAC),
+		QRCode:       handlers.NewQRCodeHandler(cfg, stores, a.RBAC),
+		Translation:  handlers.NewTranslationHandler(cfg, stores, a.RBAC),
+		Revision:     handlers.NewPropertyRevisionHandler(cfg, stores, a.RBAC),
+		Template:     handlers.NewSectionTemplateHandler(stores, a.RBAC),
+		Member:       handlers.NewPropertyMemberHandler(cfg, stores, a.Mailer, a.RBAC),
+		Organization: handlers.NewOrganizationHandler(stores, a.RBAC),
+		Health:       handlers.NewHealthHandler(a.readinessChecks()),
+	}
+	a.Router = router.SetupRouter(cfg, a.Handlers)
+	return a
+}
+
+// readinessChecks liste les vérifications de /readyz. La base est lue à chaque appel : New
+// ne la renseigne qu'après NewWithStores, et elle reste nil sur des stores en mémoire.
+func (a *App) readinessChecks() map[string]handlers.HealthCheck {
+	return map[string]handlers.HealthCheck{
+		"shutdown": func(ctx context.Context) error {
+			if a.shuttingDown.Load() {
+				return errors.New("arrêt en cours")
+			}
+			return nil
+		},
+		"database": func(ctx context.Context) error {
+			if a.DB == nil {
+				return nil
+			}
+			return a.DB.Client().Ping(ctx, nil)
+		},
+		"indexes": func(ctx context.Context) error {
+			if a.DB == nil {
+				return nil
+			}
+			missing, err := repository.MissingIndexes(ctx, a.DB)
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("index manquants: %s", strings.Join(missing, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// Close ferme la connexion à MongoDB ouverte par New
+func (a *App) Close() error {
+	if a.DB == nil {
+		return nil
+	}
+	return database.Disconnect(a.DB)
+}
@@ -0,0 +1,293 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/mailer"
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository/memory"
+	"onestay-back/internal/router"
+	"onestay-back/internal/seed"
+	"onestay-back/internal/storage"
+	"onestay-back/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+type discardMailer struct{}
+
+func (discardMailer) Send(ctx context.Context, msg mailer.Message) error { return nil }
+
+// newTestApp construit une application en mémoire contenant un utilisateur alice@example.com.
+// Les secrets des propriétés sont chiffrés avec keyring (nil : en clair).
+func newTestApp(t *testing.T, secret string, keyring *fieldcrypt.Keyring) *App {
+	t.Helper()
+
+	cfg := &config.Config{
+		JWTSecret:       secret,
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		StorageDriver:   "local",
+		MediaLocalDir:   t.TempDir(),
+		DefaultLocale:   "fr",
+	}
+
+	stores := memory.NewStores(keyring)
+	if err := seed.SeedRoles(stores.Roles); err != nil {
+		t.Fatal(err)
+	}
+	hashed, err := utils.HashPassword("motdepasse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Nom: "Martin", Prenom: "Alice", Email: "alice@example.com", Password: hashed, RoleID: "1"}
+	if err := stores.Users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewWithStores(cfg, stores, Options{
+		Mailer:  discardMailer{},
+		Storage: storage.NewLocalStorage(cfg.MediaLocalDir, ""),
+	})
+}
+
+func serve(a *App, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+// testKeyring construit un trousseau d'une clé aléatoire
+func testKeyring(t *testing.T, id string) *fieldcrypt.Keyring {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := fieldcrypt.NewKeyring(map[string][]byte{id: key}, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+// login connecte alice@example.com et retourne son access token
+func login(t *testing.T, a *App) string {
+	t.Helper()
+
+	rec := serve(a, http.MethodPost, "/api/v1/auth/login", "", `{"email":"alice@example.com","password":"motdepasse"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("connexion : statut %d (%s)", rec.Code, rec.Body.String())
+	}
+	return strings.TrimPrefix(rec.Header().Get("Authorization"), "Bearer ")
+}
+
+// createProperty crée une propriété dont le mot de passe Wi-Fi est password et retourne son ID
+func createProperty(t *testing.T, a *App, token, password string) string {
+	t.Helper()
+
+	rec := serve(a, http.MethodPost, "/api/v1/properties", token, `{"name":"Cabane du Port","address":"1 quai Est","city":"Sète","country":"France","wifi":{"enabled":true,"networkName":"Cabane","password":"`+password+`"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("création de la propriété : statut %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Property struct {
+			ID string `json:"_id"`
+		} `json:"property"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return body.Property.ID
+}
+
+// wifiPassword retourne le mot de passe Wi-Fi d'une propriété, tel que le voit son hôte
+func wifiPassword(t *testing.T, a *App, token, id string) string {
+	t.Helper()
+
+	rec := serve(a, http.MethodGet, "/api/v1/properties/"+id, token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("lecture de la propriété : statut %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Property struct {
+			Wifi struct {
+				Password string `json:"password"`
+			} `json:"wifi"`
+		} `json:"property"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return body.Property.Wifi.Password
+}
+
+func TestApplicationsAreIsolated(t *testing.T) {
+	ctx := context.Background()
+
+	// Dans la première application seulement, le rôle d'Alice permet de lister les utilisateurs
+	first := newTestApp(t, "premier-secret", testKeyring(t, "premiere"))
+	if err := first.Stores.Roles.Update(ctx, "1", bson.M{"permissions": []string{models.PermissionUsersRead}}); err != nil {
+		t.Fatal(err)
+	}
+	firstToken := login(t, first)
+	firstProperty := createProperty(t, first, firstToken, "premier-wifi")
+
+	if rec := serve(first, http.MethodGet, "/api/v1/users", firstToken, ""); rec.Code != http.StatusOK {
+		t.Fatalf("liste des utilisateurs sur la première application : statut %d, attendu 200", rec.Code)
+	}
+
+	// La seconde application, construite ensuite, a ses propres rôles et son propre trousseau
+	second := newTestApp(t, "second-secret", testKeyring(t, "seconde"))
+	secondToken := login(t, second)
+	secondProperty := createProperty(t, second, secondToken, "second-wifi")
+
+	if rec := serve(second, http.MethodGet, "/api/v1/users", secondToken, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("liste des utilisateurs sur la seconde application : statut %d, attendu 403", rec.Code)
+	}
+	if rec := serve(first, http.MethodGet, "/api/v1/users", firstToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("liste des utilisateurs sur la première application : statut %d, attendu 200", rec.Code)
+	}
+
+	// Chaque application déchiffre ses secrets avec sa propre clé
+	if got := wifiPassword(t, first, firstToken, firstProperty); got != "premier-wifi" {
+		t.Errorf("mot de passe Wi-Fi de la première application %q, attendu premier-wifi", got)
+	}
+	if got := wifiPassword(t, second, secondToken, secondProperty); got != "second-wifi" {
+		t.Errorf("mot de passe Wi-Fi de la seconde application %q, attendu second-wifi", got)
+	}
+
+	// Ni le secret JWT ni les sessions ne sont partagés
+	if rec := serve(first, http.MethodGet, "/api/v1/users/profile", firstToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("profil sur la première application : statut %d, attendu 200", rec.Code)
+	}
+	if rec := serve(second, http.MethodGet, "/api/v1/users/profile", firstToken, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("profil sur la seconde application : statut %d, attendu 401", rec.Code)
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	a := newTestApp(t, "secret", nil)
+	a.Config.MaxRequestBodySize = 1 << 10
+	a.Router = router.SetupRouter(a.Config, a.Handlers)
+
+	body := `{"email":"alice@example.com","password":"` + strings.Repeat("x", 2<<10) + `"}`
+	rec := serve(a, http.MethodPost, "/api/v1/auth/login", "", body)
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), string(apierror.RequestTooLarge)) {
+		t.Errorf("statut %d (%s), attendu 413 REQUEST_TOO_LARGE", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(a, http.MethodPost, "/api/v1/auth/login", "", `{"email":"alice@example.com","password":"motdepasse"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("requête sous la limite : statut %d, attendu 200", rec.Code)
+	}
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	a := newTestApp(t, "secret", nil)
+	a.Config.ShutdownTimeout = 5 * time.Second
+
+	started := make(chan struct{})
+	a.Router.GET("/lent", func(c *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "terminé")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- a.Serve(ctx, ln) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String() + "/lent")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		response <- result{string(body), err}
+	}()
+
+	// L'arrêt est demandé pendant le traitement de la requête
+	<-started
+	cancel()
+
+	if res := <-response; res.err != nil || res.body != "terminé" {
+		t.Errorf("requête en cours : %q, %v", res.body, res.err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve : %v", err)
+	}
+
+	// Le serveur n'accepte plus de connexions
+	if _, err := http.Get("http://" + ln.Addr().String() + "/lent"); err == nil {
+		t.Error("le serveur répond encore après l'arrêt")
+	}
+}
+
+func TestProbes(t *testing.T) {
+	a := newTestApp(t, "secret", nil)
+
+	if rec := serve(a, http.MethodGet, "/healthz", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("/healthz : statut %d, attendu 200", rec.Code)
+	}
+	if rec := serve(a, http.MethodGet, "/version", "", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"commit"`) {
+		t.Errorf("/version : statut %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := serve(a, http.MethodGet, "/readyz", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("/readyz : statut %d (%s), attendu 200", rec.Code, rec.Body.String())
+	}
+
+	// Pendant l'arrêt, l'instance reste vivante mais n'est plus prête
+	a.shuttingDown.Store(true)
+	rec := serve(a, http.MethodGet, "/readyz", "", "")
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), string(apierror.ServiceUnavailable)) {
+		t.Errorf("/readyz pendant l'arrêt : statut %d (%s), attendu 503", rec.Code, rec.Body.String())
+	}
+	if body := rec.Body.String(); strings.Contains(body, "arrêt en cours") || !strings.Contains(body, `"shutdown":"unavailable"`) {
+		t.Errorf("/readyz expose le détail de l'échec : %s", body)
+	}
+	if rec := serve(a, http.MethodGet, "/healthz", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("/healthz pendant l'arrêt : statut %d, attendu 200", rec.Code)
+	}
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// NewServer construit le serveur HTTP de l'application avec les délais configurés
+func (a *App) NewServer() *http.Server {
+	server := &http.Server{
+		Addr:              ":" + a.Config.Port,
+		Handler:           a.Router,
+		ReadTimeout:       a.Config.HTTPReadTimeout,
+		ReadHeaderTimeout: a.Config.HTTPReadTimeout,
+		WriteTimeout:      a.Config.HTTPWriteTimeout,
+		IdleTimeout:       a.Config.HTTPIdleTimeout,
+	}
+	if a.tlsEnabled() {
+		server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return server
+}
+
+func (a *App) tlsEnabled() bool {
+	return a.Config.TLSCertFile != "" && a.Config.TLSKeyFile != ""
+}
+
+// Serve répond aux requêtes reçues sur ln et fait tourner les tâches de fond jusqu'à
+// l'annulation de ctx. L'instance se signale alors indisponible sur /readyz, puis, après
+// ShutdownDelay, le serveur cesse d'accepter des connexions, laisse les
+// requêtes en cours se terminer dans la limite de ShutdownTimeout puis arrête les tâches
+// de fond. La connexion à MongoDB reste ouverte : elle est fermée par Close.
+func (a *App) Serve(ctx context.Context, ln net.Listener) error {
+	server := a.NewServer()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if a.tlsEnabled() {
+			log.Printf("Serveur démarré en HTTPS sur %s", ln.Addr())
+			serveErr <- server.ServeTLS(ln, a.Config.TLSCertFile, a.Config.TLSKeyFile)
+		} else {
+			log.Printf("Serveur démarré sur %s", ln.Addr())
+			serveErr <- server.Serve(ln)
+		}
+	}()
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	workers := a.startWorkers(workersCtx)
+
+	var err error
+	select {
+	case err = <-serveErr:
+		// Le serveur s'est arrêté de lui-même (certificat illisible, écoute impossible...)
+	case <-ctx.Done():
+		// /readyz échoue désormais ; le serveur continue de répondre pendant ShutdownDelay
+		// pour laisser à l'orchestrateur le temps de retirer l'instance du trafic
+		a.shuttingDown.Store(true)
+		if a.Config.ShutdownDelay > 0 {
+			log.Printf("Arrêt demandé : instance signalée indisponible pendant %s", a.Config.ShutdownDelay)
+			time.Sleep(a.Config.ShutdownDelay)
+		}
+
+		log.Printf("Arrêt du serveur : fin des requêtes en cours (%s maximum)", a.Config.ShutdownTimeout)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), a.Config.ShutdownTimeout)
+		err = server.Shutdown(shutdownCtx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Println("Délai d'arrêt dépassé : les connexions restantes sont fermées")
+			server.Close()
+		}
+	}
+
+	stopWorkers()
+	workers.Wait()
+	log.Println("Tâches de fond arrêtées")
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// startWorkers lance la synchronisation des calendriers et les publications programmées.
+// Un intervalle nul désactive la tâche correspondante.
+func (a *App) startWorkers(ctx context.Context) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	start := func(run func(context.Context, time.Duration), interval time.Duration) {
+		if interval <= 0 {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(ctx, interval)
+		}()
+	}
+
+	start(a.Syncer.Run, a.Config.CalendarSyncInterval)
+	start(a.Publisher.Run, a.Config.PublicationCheckInterval)
+	return &wg
+}
@@ -0,0 +1,125 @@
+package calendarsync
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrInvalidURL       = errors.New("calendarsync: URL invalide")
+	ErrUnknownHost      = errors.New("calendarsync: hôte introuvable")
+	ErrForbiddenAddress = errors.New("calendarsync: adresse non autorisée")
+)
+
+const maxRedirects = 5
+
+// blockedPrefixes complète les catégories de la bibliothèque standard (boucle locale,
+// réseaux privés, lien local...) avec les plages réservées qui n'en font pas partie
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "ce réseau"
+	netip.MustParsePrefix("100.64.0.0/10"),  // NAT des opérateurs
+	netip.MustParsePrefix("192.0.0.0/24"),   // affectations IETF
+	netip.MustParsePrefix("198.18.0.0/15"),  // bancs de test
+	netip.MustParsePrefix("240.0.0.0/4"),    // réservé, dont la diffusion
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, traduit vers de l'IPv4 quelconque
+	netip.MustParsePrefix("64:ff9b:1::/48"), // NAT64 local
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+	netip.MustParsePrefix("2002::/16"),      // 6to4, encapsule de l'IPv4 quelconque
+}
+
+// allowedAddr indique si le serveur peut se connecter à cette adresse : seules les
+// adresses publiques sont autorisées, pour qu'une URL de calendrier ne permette pas
+// d'atteindre les services internes (métadonnées du cloud, base de données...)
+func allowedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateURL vérifie le schéma d'une URL de calendrier et que son hôte ne désigne que
+// des adresses publiques. La vérification est refaite à chaque connexion (voir guardDial) :
+// le nom peut être redirigé ou résolu autrement par la suite.
+func ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "webcal") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !allowedAddr(addr) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return ErrUnknownHost
+	}
+	for _, addr := range addrs {
+		if !allowedAddr(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// guardDial est appelé après la résolution DNS, juste avant chaque connexion : il couvre
+// les redirections et un nom dont la résolution change après ValidateURL
+func guardDial(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !allowedAddr(addr) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// newClient construit le client HTTP des téléchargements de calendriers. Le proxy de
+// l'environnement est ignoré : la connexion passerait par lui et échapperait au contrôle.
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: guardDial,
+	}
+
+	return &http.Client{
+		Timeout: fetchTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 15 * time.Second,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("trop de redirections")
+			}
+			if scheme := strings.ToLower(req.URL.Scheme); scheme != "http" && scheme != "https" {
+				return ErrInvalidURL
+			}
+			return nil
+		},
+	}
+}
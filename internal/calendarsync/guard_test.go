@@ -0,0 +1,48 @@
+package calendarsync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestAllowedAddr(t *testing.T) {
+	for addr, allowed := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.0.10":     false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+		"64:ff9b::a00:1":   false,
+	} {
+		if got := allowedAddr(netip.MustParseAddr(addr)); got != allowed {
+			t.Errorf("allowedAddr(%s) = %v, attendu %v", addr, got, allowed)
+		}
+	}
+}
+
+func TestClientRefusesInternalConnections(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	}))
+	defer internal.Close()
+
+	// La connexion est refusée au moment de la numérotation, quel que soit le chemin
+	// (URL directe, redirection ou nom résolu vers une adresse interne)
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, internal.URL, nil)
+	_, err := newClient().Do(req)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("erreur %v, attendu ErrForbiddenAddress", err)
+	}
+}
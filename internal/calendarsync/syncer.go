@@ -0,0 +1,195 @@
+// Package calendarsync importe périodiquement les calendriers iCal externes
+// des propriétés sous forme de périodes bloquées.
+package calendarsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"onestay-back/internal/ical"
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository"
+)
+
+const (
+	fetchTimeout = 30 * time.Second
+	maxFeedBytes = 5 << 20
+	queueSize    = 100
+)
+
+// Causes d'échec d'un téléchargement. Seul leur libellé est enregistré sur le calendrier
+// et renvoyé au client ; le détail technique n'apparaît que dans les journaux du serveur.
+var (
+	errUnreachable     = errors.New("injoignable")
+	errBadResponse     = errors.New("réponse inattendue du serveur distant")
+	errInvalidCalendar = errors.New("calendrier invalide")
+)
+
+type Syncer struct {
+	feedRepo  repository.CalendarFeedStore
+	blockRepo repository.CalendarBlockStore
+	client    *http.Client
+	queue     chan models.CalendarFeed
+}
+
+func NewSyncer(stores *repository.Stores) *Syncer {
+	return &Syncer{
+		feedRepo:  stores.CalendarFeeds,
+		blockRepo: stores.CalendarBlocks,
+		client:    newClient(),
+		queue:     make(chan models.CalendarFeed, queueSize),
+	}
+}
+
+// Enqueue demande la synchronisation d'un calendrier par Run, sans attendre. Si la file
+// est pleine, le calendrier sera synchronisé au prochain passage périodique.
+func (s *Syncer) Enqueue(feed models.CalendarFeed) {
+	select {
+	case s.queue <- feed:
+	default:
+		log.Printf("File de synchronisation pleine : calendrier %s reporté", feed.ID.Hex())
+	}
+}
+
+// Run synchronise tous les calendriers au démarrage puis à chaque intervalle, ainsi que
+// les calendriers demandés par Enqueue, jusqu'à l'annulation du contexte
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	log.Printf("Synchronisation des calendriers externes toutes les %s", interval)
+
+	s.SyncAll(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.SyncAll(ctx)
+		case feed := <-s.queue:
+			if _, err := s.SyncFeed(ctx, &feed); err != nil {
+				log.Printf("Synchronisation du calendrier %s échouée: %v", feed.ID.Hex(), err)
+			}
+		}
+	}
+}
+
+// SyncAll synchronise tous les calendriers externes ; un échec n'interrompt pas les suivants
+func (s *Syncer) SyncAll(ctx context.Context) {
+	feeds, err := s.feedRepo.FindAll(ctx)
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des calendriers externes: %v", err)
+		return
+	}
+
+	for i := range feeds {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.SyncFeed(ctx, &feeds[i]); err != nil {
+			log.Printf("Synchronisation du calendrier %s échouée: %v", feeds[i].ID.Hex(), err)
+		}
+	}
+}
+
+// SyncFeed télécharge un calendrier externe et remplace ses périodes importées.
+// Les événements annulés ou déjà terminés sont ignorés. En cas d'échec, les périodes
+// importées précédemment sont conservées et la cause est enregistrée sur le calendrier ;
+// l'erreur détaillée est retournée pour être journalisée.
+func (s *Syncer) SyncFeed(ctx context.Context, feed *models.CalendarFeed) (int, error) {
+	cal, err := s.fetch(ctx, feed.URL)
+	if err != nil {
+		s.feedRepo.UpdateSyncStatus(ctx, feed.ID, models.CalendarFeedSyncError, syncErrorMessage(err), 0)
+		return 0, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	blocks := make([]models.CalendarBlock, 0, len(cal.Events))
+	for _, event := range cal.Events {
+		if event.IsCancelled() {
+			continue
+		}
+		start, end := event.Days()
+		if !end.After(today) {
+			continue
+		}
+		summary := feed.Name
+		if event.Summary != "" {
+			summary = fmt.Sprintf("%s : %s", feed.Name, event.Summary)
+		}
+		blocks = append(blocks, models.CalendarBlock{
+			PropertyID:  feed.PropertyID,
+			ExternalUID: event.UID,
+			Summary:     summary,
+			StartDate:   start,
+			EndDate:     end,
+		})
+	}
+
+	if err := s.blockRepo.ReplaceFeedBlocks(ctx, feed.ID, blocks); err != nil {
+		s.feedRepo.UpdateSyncStatus(ctx, feed.ID, models.CalendarFeedSyncError, "enregistrement des périodes impossible", 0)
+		return 0, err
+	}
+
+	if cal.Skipped > 0 {
+		log.Printf("Calendrier %s : %d événements mal formés ignorés", feed.ID.Hex(), cal.Skipped)
+	}
+
+	if err := s.feedRepo.UpdateSyncStatus(ctx, feed.ID, models.CalendarFeedSyncOK, "", len(blocks)); err != nil {
+		return len(blocks), err
+	}
+	return len(blocks), nil
+}
+
+// fetch télécharge et analyse un calendrier (les URL webcal:// sont lues en HTTPS)
+func (s *Syncer) fetch(ctx context.Context, url string) (*ical.Calendar, error) {
+	if strings.HasPrefix(url, "webcal://") {
+		url = "https://" + strings.TrimPrefix(url, "webcal://")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+	req.Header.Set("User-Agent", "OneStay-CalendarSync/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP %d", errBadResponse, resp.StatusCode)
+	}
+
+	cal, err := ical.Parse(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidCalendar, err)
+	}
+	return cal, nil
+}
+
+// syncErrorMessage retourne la cause d'un échec telle qu'elle est présentée au client
+func syncErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrForbiddenAddress):
+		return "adresse non autorisée"
+	case errors.Is(err, ErrInvalidURL):
+		return "URL invalide"
+	case errors.Is(err, errBadResponse):
+		return errBadResponse.Error()
+	case errors.Is(err, errInvalidCalendar):
+		return errInvalidCalendar.Error()
+	default:
+		return errUnreachable.Error()
+	}
+}
@@ -0,0 +1,96 @@
+package calendarsync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository/memory"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestSyncer retourne un syncer sur des stores en mémoire, dont le client peut
+// joindre le serveur de test local
+func newTestSyncer(t *testing.T, server *httptest.Server) (*Syncer, *models.CalendarFeed) {
+	t.Helper()
+
+	stores := memory.NewStores(nil)
+	syncer := NewSyncer(stores)
+	syncer.client = server.Client()
+
+	feed := &models.CalendarFeed{PropertyID: primitive.NewObjectID(), Name: "Airbnb", URL: server.URL}
+	if err := stores.CalendarFeeds.Create(context.Background(), feed); err != nil {
+		t.Fatal(err)
+	}
+	return syncer, feed
+}
+
+func TestSyncErrorDoesNotLeakResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret-interne=42\n"))
+	}))
+	defer server.Close()
+
+	syncer, feed := newTestSyncer(t, server)
+	if _, err := syncer.SyncFeed(context.Background(), feed); err == nil {
+		t.Fatal("la synchronisation d'une page qui n'est pas un calendrier a réussi")
+	}
+
+	stored, err := syncer.feedRepo.FindByID(context.Background(), feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.LastSyncError != "calendrier invalide" || strings.Contains(stored.LastSyncError, "secret") {
+		t.Errorf("erreur enregistrée %q, attendu \"calendrier invalide\"", stored.LastSyncError)
+	}
+}
+
+func TestRunSyncsQueuedFeedsUntilCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:sejour\r\n" +
+			"DTSTART;VALUE=DATE:20300701\r\nDTEND;VALUE=DATE:20300708\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	}))
+	defer server.Close()
+
+	stores := memory.NewStores(nil)
+	syncer := NewSyncer(stores)
+	syncer.client = server.Client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		syncer.Run(ctx, time.Hour)
+		close(stopped)
+	}()
+
+	// Le calendrier n'est connu que de la file : seul Enqueue peut déclencher sa synchronisation
+	feed := models.CalendarFeed{ID: primitive.NewObjectID(), PropertyID: primitive.NewObjectID(), Name: "Airbnb", URL: server.URL}
+	syncer.Enqueue(feed)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		blocks, err := stores.CalendarBlocks.FindByPropertyID(context.Background(), feed.PropertyID, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blocks) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("le calendrier mis en file n'a pas été synchronisé")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run ne s'arrête pas à l'annulation du contexte")
+	}
+}
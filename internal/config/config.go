@@ -1,42 +1,164 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port       string
-	MongoURI   string
-	DBName     string
-	JWTSecret  string
-}
+	Port string
+
+	// Serveur HTTP : délais de lecture, d'écriture et d'inactivité des connexions,
+	// taille maximale du corps des requêtes (hors envoi d'images), délai pendant lequel
+	// l'instance reste joignable mais signalée indisponible avant l'arrêt, et délai accordé
+	// aux requêtes en cours lors de l'arrêt
+	HTTPReadTimeout    time.Duration
+	HTTPWriteTimeout   time.Duration
+	HTTPIdleTimeout    time.Duration
+	MaxRequestBodySize int64
+	ShutdownDelay      time.Duration
+	ShutdownTimeout    time.Duration
+
+	// HTTPS : le serveur écoute en TLS si TLS_CERT_FILE et TLS_KEY_FILE sont renseignés
+	TLSCertFile string
+	TLSKeyFile  string
+
+	MongoURI        string
+	DBName          string
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// URL du front, utilisée pour construire les liens envoyés par email
+	FrontendURL          string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	InvitationTTL        time.Duration // Validité d'une invitation à collaborer sur une propriété
+
+	// Envoi d'emails : MAIL_DRIVER = "smtp" ou "log"
+	MailDriver   string
+	MailFrom     string
+	MailLogFile  string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
 
-var AppConfig *Config
+	// Chiffrement des codes d'accès : FIELD_ENCRYPTION_KEYS = "id1:base64,id2:base64"
+	FieldEncryptionKeys      string
+	FieldEncryptionActiveKey string
 
-func Load() error {
+	// Intervalle de synchronisation des calendriers iCal externes
+	CalendarSyncInterval time.Duration
+
+	// Intervalle de vérification des publications programmées
+	PublicationCheckInterval time.Duration
+
+	// Sévérité des règles de publication : PUBLICATION_RULES = "photos.minimum=off,wifi.network=error"
+	// (error bloque la publication, warning est signalé, off désactive la règle)
+	PublicationRules map[string]string
+
+	// Stockage des médias : STORAGE_DRIVER = "local" ou "s3" (S3, MinIO...)
+	StorageDriver      string
+	MediaLocalDir      string
+	MediaBaseURL       string
+	MaxImageUploadSize int64
+	S3Endpoint         string
+	S3Region           string
+	S3Bucket           string
+	S3AccessKey        string
+	S3SecretKey        string
+	S3PathStyle        bool
+	S3PublicURL        string
+
+	// Langues des contenus : DEFAULT_LOCALE et SUPPORTED_LOCALES = "fr,en,es"
+	DefaultLocale    string
+	SupportedLocales []string
+}
+
+// Load lit la configuration depuis l'environnement (et le fichier .env s'il existe).
+// Chaque appel retourne une nouvelle configuration : elle est ensuite transmise
+// explicitement aux composants qui en ont besoin.
+func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	AppConfig = &Config{
-		Port:      getEnv("PORT", "8082"),
-		MongoURI:  getEnv("MONGODB_URI", ""),
-		DBName:    getEnv("DB_NAME", "onestay"),
-		JWTSecret: getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+	cfg := &Config{
+		Port: getEnv("PORT", "8082"),
+
+		HTTPReadTimeout:    getEnvDuration("HTTP_READ_TIMEOUT", 15*time.Second),
+		HTTPWriteTimeout:   getEnvDuration("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		HTTPIdleTimeout:    getEnvDuration("HTTP_IDLE_TIMEOUT", 120*time.Second),
+		MaxRequestBodySize: getEnvInt64("MAX_REQUEST_BODY_SIZE", 1<<20),
+		ShutdownDelay:      getEnvDuration("SHUTDOWN_DELAY", 0),
+		ShutdownTimeout:    getEnvDuration("SHUTDOWN_TIMEOUT", 20*time.Second),
+
+		TLSCertFile: getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:  getEnv("TLS_KEY_FILE", ""),
+
+		MongoURI:        getEnv("MONGODB_URI", ""),
+		DBName:          getEnv("DB_NAME", "onestay"),
+		JWTSecret:       getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		FrontendURL:          getEnv("FRONTEND_URL", "http://localhost:3000"),
+		PasswordResetTTL:     getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL: getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		InvitationTTL:        getEnvDuration("INVITATION_TTL", 7*24*time.Hour),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@onestay.local"),
+		MailLogFile:  getEnv("MAIL_LOG_FILE", ""),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		FieldEncryptionKeys:      getEnv("FIELD_ENCRYPTION_KEYS", ""),
+		FieldEncryptionActiveKey: getEnv("FIELD_ENCRYPTION_ACTIVE_KEY", ""),
+
+		CalendarSyncInterval: getEnvDuration("CALENDAR_SYNC_INTERVAL", 30*time.Minute),
+
+		PublicationCheckInterval: getEnvDuration("PUBLICATION_CHECK_INTERVAL", time.Minute),
+		PublicationRules:         getEnvMap("PUBLICATION_RULES"),
+
+		StorageDriver:      getEnv("STORAGE_DRIVER", "local"),
+		MediaLocalDir:      getEnv("MEDIA_LOCAL_DIR", "./uploads"),
+		MediaBaseURL:       getEnv("MEDIA_BASE_URL", "http://localhost:8082/media"),
+		MaxImageUploadSize: getEnvInt64("MAX_IMAGE_UPLOAD_SIZE", 10<<20),
+		S3Endpoint:         getEnv("S3_ENDPOINT", "http://localhost:9000"),
+		S3Region:           getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:           getEnv("S3_BUCKET", "onestay"),
+		S3AccessKey:        getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:        getEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:        getEnv("S3_PATH_STYLE", "true") == "true",
+		S3PublicURL:        getEnv("S3_PUBLIC_URL", ""),
+
+		DefaultLocale:    getEnv("DEFAULT_LOCALE", "fr"),
+		SupportedLocales: getEnvList("SUPPORTED_LOCALES", []string{"fr", "en", "es", "de", "it"}),
+	}
+
+	if cfg.MongoURI == "" {
+		return nil, errors.New("MONGODB_URI is required")
 	}
 
-	if AppConfig.MongoURI == "" {
-		log.Fatal("MONGODB_URI is required")
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
 	}
 
-	if AppConfig.JWTSecret == "your-secret-key-change-in-production" {
+	if cfg.JWTSecret == "your-secret-key-change-in-production" {
 		log.Println("Warning: Using default JWT_SECRET. Change it in production!")
 	}
 
-	return nil
+	return cfg, nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -45,3 +167,63 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList lit une liste de valeurs séparées par des virgules
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvMap lit des paires clé=valeur séparées par des virgules
+func getEnvMap(key string) map[string]string {
+	values := make(map[string]string)
+	for _, item := range getEnvList(key, nil) {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok {
+			log.Printf("Warning: invalid entry for %s (%q), expected key=value", key, item)
+			continue
+		}
+		values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return values
+}
+
+// getEnvInt64 lit un entier (taille en octets, ...)
+func getEnvInt64(key string, defaultValue int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: invalid integer for %s (%q), using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvDuration lit une durée au format Go ("15m", "720h", ...)
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Warning: invalid duration for %s (%q), using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
@@ -5,40 +5,33 @@ import (
 	"log"
 	"time"
 
-	"onestay-back/internal/config"
-
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
-var Client *mongo.Client
-var DB *mongo.Database
-
-func Connect() error {
+// Connect ouvre une connexion à MongoDB et retourne la base demandée
+func Connect(uri, name string) (*mongo.Database, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	clientOptions := options.Client().ApplyURI(config.AppConfig.MongoURI)
-
-	client, err := mongo.Connect(clientOptions)
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := client.Ping(ctx, nil); err != nil {
-		return err
+		client.Disconnect(context.Background())
+		return nil, err
 	}
 
-	Client = client
-	DB = client.Database(config.AppConfig.DBName)
-
-	log.Printf("Connected to MongoDB successfully - Database: %s", config.AppConfig.DBName)
-	return nil
+	log.Printf("Connected to MongoDB successfully - Database: %s", name)
+	return client.Database(name), nil
 }
 
-func Disconnect() error {
+// Disconnect ferme la connexion ouverte par Connect
+func Disconnect(db *mongo.Database) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	return Client.Disconnect(ctx)
+	return db.Client().Disconnect(ctx)
 }
@@ -0,0 +1,199 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Les valeurs chiffrées sont stockées sous la forme "enc:v1:<keyID>:<base64(nonce|ciphertext)>".
+// Toute valeur sans ce préfixe est considérée comme du texte clair (données antérieures au chiffrement).
+const prefix = "enc:v1:"
+
+var (
+	ErrUnknownKey  = errors.New("fieldcrypt: clé de chiffrement inconnue")
+	ErrMalformed   = errors.New("fieldcrypt: valeur chiffrée invalide")
+	ErrNoActiveKey = errors.New("fieldcrypt: aucune clé active configurée")
+)
+
+// Keyring regroupe les clés AES-256 connues et la clé active utilisée pour chiffrer.
+// Les anciennes clés restent nécessaires pour déchiffrer jusqu'à la rotation des données.
+type Keyring struct {
+	keys     map[string]cipher.AEAD
+	activeID string
+}
+
+// NewKeyring construit un trousseau à partir de clés brutes de 32 octets
+func NewKeyring(keys map[string][]byte, activeID string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(keys)), activeID: activeID}
+
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("fieldcrypt: identifiant de clé invalide %q", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("fieldcrypt: la clé %q doit faire 32 octets (AES-256)", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+
+	if _, ok := k.keys[activeID]; !ok {
+		return nil, fmt.Errorf("fieldcrypt: la clé active %q n'est pas définie", activeID)
+	}
+
+	return k, nil
+}
+
+// ParseKeys lit une liste "id1:base64,id2:base64"
+func ParseKeys(spec string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("fieldcrypt: entrée de clé invalide %q (format attendu id:base64)", entry)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: clé %q mal encodée: %w", parts[0], err)
+		}
+		keys[strings.TrimSpace(parts[0])] = key
+	}
+
+	return keys, nil
+}
+
+// Load construit le trousseau à partir des clés configurées (FIELD_ENCRYPTION_KEYS) et de
+// l'identifiant de la clé active. Sans clé, il retourne nil : le chiffrement est désactivé et
+// les valeurs restent en clair.
+func Load(encodedKeys, activeID string) (*Keyring, error) {
+	if encodedKeys == "" {
+		log.Println("Warning: FIELD_ENCRYPTION_KEYS not set, property secrets are stored in plain text")
+		return nil, nil
+	}
+
+	keys, err := ParseKeys(encodedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if activeID == "" && len(keys) == 1 {
+		for id := range keys {
+			activeID = id
+		}
+	}
+
+	return NewKeyring(keys, activeID)
+}
+
+// ActiveKeyID retourne l'identifiant de la clé utilisée pour chiffrer
+func (k *Keyring) ActiveKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.activeID
+}
+
+// Encrypt chiffre une valeur avec la clé active. Les valeurs vides ne sont pas chiffrées, pas
+// plus que celles déjà chiffrées par ce trousseau. Un texte qui porte seulement le préfixe
+// est chiffré comme n'importe quelle saisie : sinon sa lecture échouerait avec ErrMalformed.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	if IsEncrypted(plaintext) {
+		if _, err := k.Decrypt(plaintext); err == nil {
+			return plaintext, nil
+		}
+	}
+
+	aead, ok := k.keys[k.activeID]
+	if !ok {
+		return "", ErrNoActiveKey
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.activeID))
+	return prefix + k.activeID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt déchiffre une valeur ; les valeurs en clair sont retournées telles quelles
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, payload, err := split(value)
+	if err != nil {
+		return "", err
+	}
+
+	if k == nil {
+		return "", ErrUnknownKey
+	}
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return "", ErrMalformed
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation indique si une valeur est en clair ou chiffrée avec une autre clé que la clé active
+func (k *Keyring) NeedsRotation(value string) bool {
+	if k == nil || value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	keyID, _, err := split(value)
+	return err == nil && keyID != k.activeID
+}
+
+// IsEncrypted indique si une valeur a été produite par Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func split(value string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(value, prefix), ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", ErrMalformed
+	}
+	return parts[0], parts[1], nil
+}
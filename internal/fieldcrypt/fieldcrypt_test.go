@@ -0,0 +1,55 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testKeyring(t *testing.T) *Keyring {
+	t.Helper()
+
+	k, err := NewKeyring(map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	k := testKeyring(t)
+
+	alreadyEncrypted, err := k.Encrypt("motdepasse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"texte clair", "motdepasse", "motdepasse"},
+		{"valeur vide", "", ""},
+		{"déjà chiffrée", alreadyEncrypted, "motdepasse"},
+		{"préfixe seul", "enc:v1:", "enc:v1:"},
+		{"préfixe et clé inconnue", "enc:v1:autre:AAAA", "enc:v1:autre:AAAA"},
+		{"préfixe et charge invalide", "enc:v1:k1:pas du base64", "enc:v1:k1:pas du base64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := k.Encrypt(tt.value)
+			if err != nil {
+				t.Fatalf("Encrypt : %v", err)
+			}
+			got, err := k.Decrypt(encrypted)
+			if err != nil || got != tt.want {
+				t.Errorf("Decrypt(Encrypt(%q)) = %q, %v ; attendu %q", tt.value, got, err, tt.want)
+			}
+		})
+	}
+
+	if again, _ := k.Encrypt(alreadyEncrypted); again != alreadyEncrypted {
+		t.Error("une valeur déjà chiffrée est chiffrée une seconde fois")
+	}
+}
@@ -0,0 +1,283 @@
+// Package guidebook génère le livret d'accueil imprimable (PDF) d'une propriété :
+// page de garde, sommaire et une section par rubrique activée.
+package guidebook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"onestay-back/internal/models"
+	"onestay-back/internal/qrcode"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Options paramètre la génération du livret
+type Options struct {
+	// IncludeSecrets indique que le lecteur a accès aux codes (hôte ou voyageur avec un lien valide)
+	IncludeSecrets bool
+	GeneratedAt    time.Time
+}
+
+const (
+	pageMargin   = 20.0
+	labelWidth   = 55.0
+	lineHeight   = 5.5
+	qrCodeSize   = 45.0
+	fontFamily   = "Helvetica"
+	bulletPrefix = "•  "
+)
+
+// Couleur d'accent (titres, page de garde)
+var accent = struct{ r, g, b int }{31, 94, 120}
+
+// Render écrit le livret au format PDF. Le document est calculé deux fois : le premier
+// passage sert uniquement à connaître la page de chaque section pour le sommaire.
+func Render(w io.Writer, property *models.Property, opts Options) error {
+	if opts.GeneratedAt.IsZero() {
+		opts.GeneratedAt = time.Now()
+	}
+
+	sections := buildSections(property, opts.IncludeSecrets)
+
+	pages, err := render(io.Discard, property, sections, opts, nil)
+	if err != nil {
+		return err
+	}
+	_, err = render(w, property, sections, opts, pages)
+	return err
+}
+
+type renderer struct {
+	pdf *fpdf.Fpdf
+	// tr convertit l'UTF-8 vers l'encodage cp1252 des polices standard PDF
+	tr func(string) string
+}
+
+func render(w io.Writer, property *models.Property, sections []section, opts Options, tocPages []int) ([]int, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle("Livret d'accueil - "+property.Name, true)
+	pdf.SetCreator("OneStay", true)
+	pdf.SetCreationDate(opts.GeneratedAt)
+
+	r := &renderer{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		half := r.contentWidth() / 2
+		pdf.SetY(-15)
+		pdf.SetFont(fontFamily, "I", 9)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(half, 10, r.tr(property.Name), "", 0, "L", false, 0, "")
+		pdf.CellFormat(half, 10, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	r.cover(property, opts)
+
+	links := make([]int, len(sections))
+	for i := range sections {
+		links[i] = pdf.AddLink()
+	}
+	r.tableOfContents(sections, links, tocPages)
+
+	pages := make([]int, len(sections))
+	pdf.AddPage()
+	for i, s := range sections {
+		pages[i] = r.section(i, s, links[i])
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+func (r *renderer) contentWidth() float64 {
+	width, _ := r.pdf.GetPageSize()
+	return width - 2*pageMargin
+}
+
+// bottomLimit est l'ordonnée au-delà de laquelle le contenu passe à la page suivante
+func (r *renderer) bottomLimit() float64 {
+	_, height := r.pdf.GetPageSize()
+	return height - pageMargin
+}
+
+// ensureSpace passe à la page suivante si la hauteur demandée ne tient pas
+func (r *renderer) ensureSpace(height float64) {
+	if r.pdf.GetY()+height > r.bottomLimit() {
+		r.pdf.AddPage()
+	}
+}
+
+func (r *renderer) cover(property *models.Property, opts Options) {
+	pdf := r.pdf
+	pdf.AddPage()
+	width, height := pdf.GetPageSize()
+
+	pdf.SetFillColor(accent.r, accent.g, accent.b)
+	pdf.Rect(0, 0, width, 100, "F")
+
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont(fontFamily, "", 14)
+	pdf.SetY(35)
+	pdf.CellFormat(0, 8, r.tr("LIVRET D'ACCUEIL"), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+	pdf.SetFont(fontFamily, "B", 28)
+	pdf.MultiCell(0, 12, r.tr(property.Name), "", "C", false)
+
+	pdf.SetTextColor(40, 40, 40)
+	pdf.SetFont(fontFamily, "", 12)
+	pdf.SetY(115)
+	address := []string{property.Address, strings.TrimSpace(property.ZipCode + " " + property.City), property.Country}
+	for _, line := range address {
+		if line != "" {
+			pdf.CellFormat(0, 7, r.tr(line), "", 1, "C", false, 0, "")
+		}
+	}
+
+	if description := strings.TrimSpace(property.Description); description != "" {
+		pdf.Ln(12)
+		pdf.SetFont(fontFamily, "I", 11)
+		pdf.MultiCell(0, lineHeight+0.5, r.tr(description), "", "C", false)
+	}
+
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(120, 120, 120)
+	pdf.SetY(height - pageMargin - 12)
+	if !opts.IncludeSecrets {
+		pdf.CellFormat(0, 5, r.tr("Les codes d'accès vous seront communiqués par votre hôte."), "", 1, "C", false, 0, "")
+	}
+	pdf.CellFormat(0, 5, r.tr("Édité le "+opts.GeneratedAt.Format("02/01/2006")), "", 1, "C", false, 0, "")
+}
+
+// tableOfContents imprime le sommaire ; pages vaut nil lors du premier passage
+func (r *renderer) tableOfContents(sections []section, links []int, pages []int) {
+	pdf := r.pdf
+	pdf.AddPage()
+
+	r.heading("Sommaire")
+
+	pageColumn := 20.0
+	pdf.SetFont(fontFamily, "", 12)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.SetDrawColor(220, 220, 220)
+	for i, s := range sections {
+		page := ""
+		if pages != nil {
+			page = fmt.Sprint(pages[i])
+		}
+		pdf.CellFormat(r.contentWidth()-pageColumn, 9, r.tr(s.title), "B", 0, "L", false, links[i], "")
+		pdf.CellFormat(pageColumn, 9, page, "B", 1, "R", false, links[i], "")
+	}
+}
+
+func (r *renderer) heading(title string) {
+	pdf := r.pdf
+	pdf.SetFont(fontFamily, "B", 18)
+	pdf.SetTextColor(accent.r, accent.g, accent.b)
+	pdf.CellFormat(0, 10, r.tr(title), "", 1, "L", false, 0, "")
+
+	y := pdf.GetY() + 1
+	pdf.SetDrawColor(accent.r, accent.g, accent.b)
+	pdf.SetLineWidth(0.6)
+	pdf.Line(pageMargin, y, pageMargin+r.contentWidth(), y)
+	pdf.SetLineWidth(0.2)
+	pdf.Ln(6)
+}
+
+// section imprime une rubrique et retourne la page où elle commence
+func (r *renderer) section(index int, s section, link int) int {
+	pdf := r.pdf
+
+	// Un titre n'est jamais laissé seul en bas de page
+	r.ensureSpace(40)
+	if pdf.GetY() > pageMargin {
+		pdf.Ln(4)
+	}
+
+	page := pdf.PageNo()
+	pdf.SetLink(link, -1, page)
+	pdf.Bookmark(r.tr(s.title), 0, -1)
+	r.heading(s.title)
+
+	pdf.SetTextColor(40, 40, 40)
+	for _, e := range s.entries {
+		switch {
+		case e.bullet:
+			pdf.SetFont(fontFamily, "", 11)
+			r.ensureSpace(lineHeight)
+			pdf.SetX(pageMargin + 4)
+			pdf.MultiCell(r.contentWidth()-4, lineHeight, r.tr(bulletPrefix+e.text), "", "L", false)
+		case e.label == "":
+			pdf.SetFont(fontFamily, "", 11)
+			pdf.MultiCell(0, lineHeight, r.tr(e.text), "", "L", false)
+		default:
+			r.labeledEntry(e)
+		}
+		pdf.Ln(1.5)
+	}
+
+	if s.qrCode != "" {
+		r.qrCode(index, s.qrCode, s.qrLabel)
+	}
+
+	return page
+}
+
+// labeledEntry imprime un libellé en gras dans la colonne de gauche et le texte à droite
+func (r *renderer) labeledEntry(e entry) {
+	pdf := r.pdf
+	textWidth := r.contentWidth() - labelWidth
+
+	pdf.SetFont(fontFamily, "", 11)
+	lines := len(pdf.SplitLines([]byte(r.tr(e.text)), textWidth))
+	pdf.SetFont(fontFamily, "B", 11)
+	if labelLines := len(pdf.SplitLines([]byte(r.tr(e.label)), labelWidth-3)); labelLines > lines {
+		lines = labelLines
+	}
+	r.ensureSpace(float64(lines) * lineHeight)
+
+	page, y := pdf.PageNo(), pdf.GetY()
+	pdf.MultiCell(labelWidth-3, lineHeight, r.tr(e.label), "", "L", false)
+	labelBottom := pdf.GetY()
+
+	pdf.SetFont(fontFamily, "", 11)
+	pdf.SetXY(pageMargin+labelWidth, y)
+	pdf.MultiCell(textWidth, lineHeight, r.tr(e.text), "", "L", false)
+
+	// Un texte plus long qu'une page a déjà fait avancer le curseur sur la page suivante
+	if pdf.PageNo() == page && labelBottom > pdf.GetY() {
+		pdf.SetY(labelBottom)
+	}
+}
+
+func (r *renderer) qrCode(index int, content, label string) {
+	pdf := r.pdf
+
+	png, err := qrcode.PNG(content, 512)
+	if err != nil {
+		pdf.SetError(err)
+		return
+	}
+
+	name := fmt.Sprintf("qrcode-%d", index)
+	pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+	r.ensureSpace(qrCodeSize + 14)
+	pdf.Ln(4)
+	x := pageMargin + (r.contentWidth()-qrCodeSize)/2
+	pdf.ImageOptions(name, x, pdf.GetY(), qrCodeSize, qrCodeSize, true, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	pdf.SetFont(fontFamily, "I", 9)
+	pdf.SetTextColor(120, 120, 120)
+	pdf.CellFormat(0, 6, r.tr(label), "", 1, "C", false, 0, "")
+	pdf.SetTextColor(40, 40, 40)
+}
@@ -0,0 +1,411 @@
+package guidebook
+
+import (
+	"fmt"
+	"strings"
+
+	"onestay-back/internal/models"
+	"onestay-back/internal/qrcode"
+)
+
+// entry est une ligne d'une section : un libellé facultatif suivi d'un texte,
+// ou un élément de liste à puces
+type entry struct {
+	label  string
+	text   string
+	bullet bool
+}
+
+// section regroupe les informations d'une rubrique activée de la propriété
+type section struct {
+	title   string
+	entries []entry
+	qrCode  string // Contenu d'un QR code imprimé en fin de section
+	qrLabel string
+}
+
+func (s *section) field(label, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		s.entries = append(s.entries, entry{label: label, text: value})
+	}
+}
+
+func (s *section) paragraph(value string) {
+	s.field("", value)
+}
+
+func (s *section) bullet(value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		s.entries = append(s.entries, entry{text: value, bullet: true})
+	}
+}
+
+// flag ajoute un élément de liste lorsque l'option est présente
+func (s *section) flag(enabled bool, label string) {
+	if enabled {
+		s.bullet(label)
+	}
+}
+
+func (s *section) empty() bool {
+	return len(s.entries) == 0 && s.qrCode == ""
+}
+
+func allowed(value bool) string {
+	if value {
+		return "Autorisé"
+	}
+	return "Interdit"
+}
+
+var equipmentCategories = map[string]string{
+	"bedroom":  "Chambre",
+	"bathroom": "Salle de bain",
+	"kitchen":  "Cuisine",
+	"living":   "Séjour",
+	"outdoor":  "Extérieur",
+	"baby":     "Bébé",
+	"work":     "Travail",
+	"other":    "Autre",
+}
+
+var equipmentCategoryOrder = []string{"bedroom", "bathroom", "kitchen", "living", "outdoor", "baby", "work", "other"}
+
+var contactTypes = map[string]string{
+	"host":        "Hôte",
+	"concierge":   "Conciergerie",
+	"cleaning":    "Ménage",
+	"maintenance": "Maintenance",
+	"emergency":   "Urgence",
+	"neighbor":    "Voisin",
+	"other":       "Autre",
+}
+
+// buildSections transforme les rubriques activées en sections imprimables, dans l'ordre
+// de lecture d'un voyageur. Les codes d'accès ne sont repris que s'ils sont renseignés :
+// la propriété doit avoir été expurgée en amont pour un lecteur non autorisé.
+func buildSections(p *models.Property, includeSecrets bool) []section {
+	var sections []section
+	add := func(s section) {
+		if !s.empty() {
+			sections = append(sections, s)
+		}
+	}
+
+	if c := p.CheckInOut; c != nil && c.Enabled {
+		s := section{title: "Arrivée et départ"}
+		s.field("Arrivée", "À partir de "+c.CheckInTime)
+		s.field("Départ", "Avant "+c.CheckOutTime)
+		s.flag(c.SelfCheckIn, "Arrivée autonome")
+		s.flag(c.EarlyCheckIn, "Arrivée anticipée possible sur demande")
+		s.flag(c.LateCheckOut, "Départ tardif possible sur demande")
+		s.field("Instructions d'arrivée", c.CheckInInstructions)
+		s.field("Instructions de départ", c.CheckOutInstructions)
+		s.field("Emplacement des clés", c.KeyLocation)
+		s.field("Code d'accès", c.AccessCode)
+		s.field("Boîte à clés", c.LockboxCode)
+		s.field("Code de l'immeuble", c.BuildingCode)
+		s.field("Interphone", c.IntercomCode)
+		s.field("Code du parking", c.ParkingCode)
+		s.field("Code du portail", c.GateCode)
+		add(s)
+	}
+
+	if w := p.Wifi; w != nil && w.Enabled {
+		s := section{title: "Wi-Fi"}
+		s.field("Réseau", w.NetworkName)
+		s.field("Mot de passe", w.Password)
+		s.field("Emplacement de la box", w.RouterLocation)
+		s.field("En cas de panne", w.ResetInstructions)
+		s.field("Remarques", w.Notes)
+		// Sans accès aux secrets, le mot de passe est masqué : un QR code sans mot de passe
+		// ne permettrait pas de se connecter
+		if w.NetworkName != "" && includeSecrets {
+			s.qrCode = qrcode.WifiPayload(w.NetworkName, w.Password)
+			s.qrLabel = "Scannez ce code pour vous connecter au Wi-Fi"
+		}
+		add(s)
+	}
+
+	if r := p.Rules; r != nil && r.Enabled {
+		s := section{title: "Règlement intérieur"}
+		s.field("Fumer", allowed(r.SmokingAllowed))
+		s.field("Animaux", allowed(r.PetsAllowed))
+		s.field("Fêtes", allowed(r.PartiesAllowed))
+		s.field("Enfants", allowed(r.ChildrenAllowed))
+		if r.MaxGuests != nil {
+			s.field("Voyageurs maximum", fmt.Sprint(*r.MaxGuests))
+		}
+		s.field("Heures calmes", r.QuietHours)
+		for _, rule := range r.HouseRules {
+			s.bullet(rule)
+		}
+		s.paragraph(r.AdditionalRules)
+		add(s)
+	}
+
+	if i := p.Instructions; i != nil && i.Enabled {
+		s := section{title: "Consignes d'utilisation"}
+		for _, item := range []struct {
+			label string
+			item  *models.InstructionItem
+		}{
+			{"Déchets", i.Trash},
+			{"Chauffage", i.Heating},
+			{"Climatisation", i.AirConditioning},
+			{"Eau chaude", i.HotWater},
+			{"Électroménager", i.Appliances},
+			{"Linge", i.Laundry},
+			{"Lave-vaisselle", i.Dishwasher},
+			{"Four", i.Oven},
+			{"Machine à café", i.CoffeeMachine},
+			{"Télévision", i.Television},
+			{"Système audio", i.Sound},
+			{"Volets", i.Blinds},
+			{"Alarme", i.Alarm},
+			{"Coffre-fort", i.Safe},
+			{"Piscine", i.Pool},
+			{"Spa", i.Spa},
+			{"Jardin", i.Garden},
+			{"Barbecue", i.Barbecue},
+			{"Cheminée", i.Fireplace},
+			{"Autres", i.Other},
+		} {
+			if item.item != nil && item.item.Enabled {
+				s.field(item.label, item.item.Content)
+			}
+		}
+		add(s)
+	}
+
+	if e := p.Equipment; e != nil && e.Enabled {
+		s := section{title: "Équipements"}
+		byCategory := make(map[string][]string)
+		for _, item := range e.Items {
+			category := item.Category
+			if _, ok := equipmentCategories[category]; !ok {
+				category = "other"
+			}
+			byCategory[category] = append(byCategory[category], item.Name)
+		}
+		for _, category := range equipmentCategoryOrder {
+			s.field(equipmentCategories[category], strings.Join(byCategory[category], ", "))
+		}
+		add(s)
+	}
+
+	if sec := p.Security; sec != nil && sec.Enabled {
+		s := section{title: "Sécurité"}
+		if sec.HasAlarm {
+			s.field("Alarme", sec.AlarmInstructions)
+			s.field("Code de l'alarme", sec.AlarmCode)
+		}
+		if sec.HasSafe {
+			s.field("Coffre-fort", sec.SafeLocation)
+			s.field("Code du coffre-fort", sec.SafeCode)
+		}
+		if sec.HasFireExtinguisher {
+			s.field("Extincteur", sec.FireExtinguisherLocation)
+		}
+		if sec.HasFirstAidKit {
+			s.field("Trousse de secours", sec.FirstAidKitLocation)
+		}
+		s.flag(sec.HasSmokeDetector, "Détecteur de fumée")
+		s.flag(sec.HasCarbonMonoxideDetector, "Détecteur de monoxyde de carbone")
+		s.paragraph(sec.SecurityNotes)
+		add(s)
+	}
+
+	if pk := p.Parking; pk != nil && pk.Enabled {
+		s := section{title: "Parking"}
+		if pk.Available {
+			s.field("Type", pk.Type)
+			if pk.Free {
+				s.field("Tarif", "Gratuit")
+			} else {
+				s.field("Tarif", pk.Price)
+			}
+			s.field("Instructions", pk.Instructions)
+			s.field("Code d'accès", pk.AccessCode)
+		} else {
+			s.paragraph("Pas de parking disponible sur place.")
+			s.field("Instructions", pk.Instructions)
+		}
+		add(s)
+	}
+
+	if t := p.Transport; t != nil && t.Enabled {
+		s := section{title: "Transports"}
+		s.field("Bus", t.NearestBus)
+		s.field("Métro", t.NearestMetro)
+		s.field("Train", t.NearestTrain)
+		s.field("Tramway", t.NearestTram)
+		s.field("Taxi", t.TaxiInfo)
+		s.field("Vélos", t.BikeRental)
+		s.field("Location de voiture", t.CarRental)
+		s.field("Navette aéroport", t.AirportShuttle)
+		s.field("À pied", t.WalkingInfo)
+		add(s)
+	}
+
+	if sv := p.Services; sv != nil && sv.Enabled {
+		s := section{title: "Services"}
+		s.flag(sv.LinensIncluded, "Linge de lit fourni")
+		s.flag(sv.TowelsIncluded, "Serviettes fournies")
+		s.flag(sv.ToiletryIncluded, "Produits de toilette fournis")
+		s.flag(sv.CleaningIncluded, "Ménage inclus")
+		s.flag(sv.LuggageStorage, "Consigne à bagages")
+		s.flag(sv.LaundryService, "Service de blanchisserie")
+		s.field("Fréquence du ménage", sv.CleaningFrequency)
+		if sv.BreakfastIncluded {
+			s.field("Petit-déjeuner", sv.BreakfastDetails)
+			s.flag(sv.BreakfastDetails == "", "Petit-déjeuner inclus")
+		}
+		s.field("Conciergerie", sv.ConciergeService)
+		s.field("Livraison de courses", sv.GroceryDelivery)
+		add(s)
+	}
+
+	if b := p.BabyKids; b != nil && b.Enabled {
+		s := section{title: "Bébé et enfants"}
+		s.flag(b.HasCrib, "Lit bébé")
+		s.flag(b.HasHighChair, "Chaise haute")
+		s.flag(b.HasBabyGate, "Barrière de sécurité")
+		s.flag(b.HasChildProofing, "Logement sécurisé pour les enfants")
+		s.flag(b.KidsToysAvailable, "Jouets à disposition")
+		s.field("Aires de jeux", b.NearbyPlaygrounds)
+		s.field("Baby-sitter", b.BabysitterContact)
+		s.paragraph(b.AdditionalInfo)
+		add(s)
+	}
+
+	if pt := p.Pets; pt != nil && pt.Enabled {
+		s := section{title: "Animaux"}
+		s.field("Animaux", allowed(pt.PetsAllowed))
+		if pt.PetsAllowed {
+			s.field("Supplément", pt.PetFee)
+			s.field("Règles", pt.PetRules)
+			s.field("Promenades", pt.DogWalkingAreas)
+			s.field("Vétérinaire", pt.NearbyVet)
+			s.field("Animalerie", pt.NearbyPetStore)
+			s.field("Équipements", pt.PetEquipmentAvailable)
+		}
+		add(s)
+	}
+
+	if en := p.Entertainment; en != nil && en.Enabled {
+		s := section{title: "Divertissements"}
+		if en.HasTv {
+			s.field("Télévision", en.TvChannels)
+			s.flag(en.TvChannels == "", "Télévision")
+		}
+		if en.HasNetflix {
+			s.field("Netflix", en.NetflixInstructions)
+			s.flag(en.NetflixInstructions == "", "Netflix")
+		}
+		if en.HasSpotify {
+			s.field("Spotify", en.SpotifyInstructions)
+			s.flag(en.SpotifyInstructions == "", "Spotify")
+		}
+		if en.HasGameConsole {
+			s.field("Console de jeux", en.GameConsoleDetails)
+			s.flag(en.GameConsoleDetails == "", "Console de jeux")
+		}
+		s.field("Jeux de société", en.BoardGames)
+		s.field("Livres", en.Books)
+		add(s)
+	}
+
+	if o := p.Outdoor; o != nil && o.Enabled {
+		s := section{title: "Extérieurs"}
+		for _, space := range []struct {
+			present bool
+			label   string
+			info    string
+		}{
+			{o.HasGarden, "Jardin", o.GardenInfo},
+			{o.HasTerrace, "Terrasse", o.TerraceInfo},
+			{o.HasBalcony, "Balcon", o.BalconyInfo},
+			{o.HasPool, "Piscine", o.PoolInfo},
+			{o.HasSpa, "Spa", o.SpaInfo},
+			{o.HasBarbecue, "Barbecue", o.BarbecueInfo},
+		} {
+			if space.present {
+				s.field(space.label, space.info)
+				s.flag(space.info == "", space.label)
+			}
+		}
+		if o.HasPool {
+			s.field("Règles de la piscine", o.PoolRules)
+		}
+		add(s)
+	}
+
+	if n := p.Neighborhood; n != nil && n.Enabled {
+		s := section{title: "Le quartier"}
+		s.paragraph(n.Description)
+		s.field("Voisinage", n.NeighborInfo)
+		s.field("À découvrir", n.NearbyAttractions)
+		s.field("Conseils de sécurité", n.SafetyTips)
+		add(s)
+	}
+
+	if lr := p.LocalRecommendations; lr != nil && lr.Enabled {
+		s := section{title: "Nos recommandations"}
+		for _, rec := range lr.Recommendations {
+			details := []string{rec.Description, rec.Address, rec.Phone, rec.Website}
+			if rec.Distance != "" {
+				details = append(details, "À "+rec.Distance)
+			}
+			var lines []string
+			for _, detail := range details {
+				if detail = strings.TrimSpace(detail); detail != "" {
+					lines = append(lines, detail)
+				}
+			}
+			s.entries = append(s.entries, entry{label: rec.Name, text: strings.Join(lines, "\n")})
+		}
+		add(s)
+	}
+
+	if ct := p.Contacts; ct != nil && ct.Enabled {
+		s := section{title: "Contacts utiles"}
+		for _, contact := range ct.Contacts {
+			label := contact.Name
+			if kind, ok := contactTypes[contact.Type]; ok {
+				label += " (" + kind + ")"
+			}
+			lines := []string{contact.Phone}
+			if contact.Email != "" {
+				lines = append(lines, contact.Email)
+			}
+			if contact.Notes != "" {
+				lines = append(lines, contact.Notes)
+			}
+			s.entries = append(s.entries, entry{label: label, text: strings.Join(lines, "\n")})
+		}
+		add(s)
+	}
+
+	if em := p.Emergency; em != nil && em.Enabled {
+		s := section{title: "Urgences"}
+		s.field("Numéro d'urgence", withDefault(em.EmergencyNumber, "112"))
+		s.field("Police", withDefault(em.PoliceNumber, "17"))
+		s.field("Pompiers", withDefault(em.FireNumber, "18"))
+		s.field("SAMU", withDefault(em.AmbulanceNumber, "15"))
+		s.field("Hôpital le plus proche", strings.TrimSpace(em.NearestHospital+"\n"+em.NearestHospitalAddress))
+		s.field("Pharmacie la plus proche", strings.TrimSpace(em.NearestPharmacy+"\n"+em.NearestPharmacyHours))
+		s.field("Médecin de garde", em.DoctorOnCall)
+		s.paragraph(em.AdditionalEmergencyInfo)
+		add(s)
+	}
+
+	return sections
+}
+
+func withDefault(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
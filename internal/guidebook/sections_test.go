@@ -0,0 +1,46 @@
+package guidebook
+
+import (
+	"testing"
+
+	"onestay-back/internal/models"
+)
+
+func wifiSection(t *testing.T, p *models.Property, includeSecrets bool) section {
+	t.Helper()
+
+	for _, s := range buildSections(p, includeSecrets) {
+		if s.title == "Wi-Fi" {
+			return s
+		}
+	}
+	t.Fatal("section Wi-Fi absente")
+	return section{}
+}
+
+func TestWifiQRCodeRequiresSecrets(t *testing.T) {
+	tests := []struct {
+		name           string
+		password       string
+		includeSecrets bool
+		wantQRCode     bool
+	}{
+		{"livret complet", "motdepasse", true, true},
+		{"livret public", "motdepasse", false, false},
+		{"livret public, mot de passe masqué", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &models.Property{
+				Name: "Chalet des Alpes",
+				Wifi: &models.Wifi{Enabled: true, NetworkName: "Chalet", Password: tt.password},
+			}
+
+			s := wifiSection(t, p, tt.includeSecrets)
+			if got := s.qrCode != ""; got != tt.wantQRCode {
+				t.Errorf("QR code Wi-Fi = %q, attendu présent : %v", s.qrCode, tt.wantQRCode)
+			}
+		})
+	}
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
+	"onestay-back/internal/mailer"
 	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
 	"onestay-back/internal/repository"
 	"onestay-back/internal/utils"
 
@@ -16,16 +22,34 @@ import (
 )
 
 type AuthHandler struct {
-	userRepo     *repository.UserRepository
-	roleRepo     *repository.RoleRepository
-	propertyRepo *repository.PropertyRepository
+	cfg          *config.Config
+	tokens       *utils.TokenService
+	userRepo     repository.UserStore
+	roleRepo     repository.RoleStore
+	propertyRepo repository.PropertyStore
+	templateRepo repository.SectionTemplateLinkStore
+	memberRepo   repository.PropertyMemberStore
+	orgRepo      repository.OrganizationStore
+	sessionRepo  repository.SessionStore
+	tokenRepo    repository.UserTokenStore
+	mailer       mailer.Sender
+	authz        *rbac.Service
 }
 
-func NewAuthHandler() *AuthHandler {
+func NewAuthHandler(cfg *config.Config, stores *repository.Stores, tokens *utils.TokenService, sender mailer.Sender, authz *rbac.Service) *AuthHandler {
 	return &AuthHandler{
-		userRepo:     repository.NewUserRepository(),
-		roleRepo:     repository.NewRoleRepository(),
-		propertyRepo: repository.NewPropertyRepository(),
+		cfg:          cfg,
+		tokens:       tokens,
+		userRepo:     stores.Users,
+		roleRepo:     stores.Roles,
+		propertyRepo: stores.Properties,
+		templateRepo: stores.SectionTemplateLinks,
+		memberRepo:   stores.PropertyMembers,
+		orgRepo:      stores.Organizations,
+		sessionRepo:  stores.Sessions,
+		tokenRepo:    stores.UserTokens,
+		mailer:       sender,
+		authz:        authz,
 	}
 }
 
@@ -33,10 +57,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Données invalides",
-			"details": err.Error(),
-		})
+		apierror.AbortBinding(c, err)
 		return
 	}
 
@@ -44,39 +65,27 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	exists, err := h.userRepo.ExistsByEmail(ctx, req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la vérification de l'email",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
 	if exists {
-		c.JSON(http.StatusConflict, gin.H{
-			"error": "Cet email est déjà utilisé",
-		})
+		apierror.Abort(c, apierror.EmailTaken)
 		return
 	}
 
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors du hachage du mot de passe",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
 	role, err := h.roleRepo.FindByID(ctx, req.RoleID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Rôle introuvable",
-				"role_id": req.RoleID,
-			})
+			apierror.AbortWithDetails(c, apierror.UnknownRole, gin.H{"role_id": req.RoleID})
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la recherche du rôle",
-				"details": err.Error(),
-			})
+			apierror.Internal(c, err)
 		}
 		return
 	}
@@ -90,9 +99,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	if err := h.userRepo.Create(ctx, user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la création du compte",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -113,10 +120,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Données invalides",
-			"details": err.Error(),
-		})
+		apierror.AbortBinding(c, err)
 		return
 	}
 
@@ -124,24 +128,29 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	user, err := h.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Email ou mot de passe incorrect",
-		})
+		apierror.Abort(c, apierror.InvalidCredentials)
 		return
 	}
 
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Email ou mot de passe incorrect",
-		})
+		apierror.Abort(c, apierror.InvalidCredentials)
+		return
+	}
+
+	if user.EmailVerificationPending {
+		apierror.Abort(c, apierror.EmailNotVerified)
 		return
 	}
 
-	token, err := utils.GenerateToken(user.ID, user.RoleID, user.Email)
+	session, refreshToken, err := h.createSession(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la génération du token",
-		})
+		apierror.Internal(c, err)
+		return
+	}
+
+	token, err := h.tokens.Generate(user.ID, user.RoleID, user.Email, session.ID.Hex())
+	if err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -149,35 +158,191 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	response := models.LoginResponse{
 		User: models.UserProfile{
-			ID:        user.ID,
-			Nom:       user.Nom,
-			Prenom:    user.Prenom,
-			Email:     user.Email,
-			RoleID:    user.RoleID,
-			CreatedAt: user.CreatedAt,
+			ID:            user.ID,
+			Nom:           user.Nom,
+			Prenom:        user.Prenom,
+			Email:         user.Email,
+			RoleID:        user.RoleID,
+			EmailVerified: !user.EmailVerificationPending,
+			CreatedAt:     user.CreatedAt,
 		},
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(h.tokens.TTL().Seconds()),
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// createSession ouvre une nouvelle session et retourne le refresh token en clair
+func (h *AuthHandler) createSession(c *gin.Context, user *models.User) (*models.Session, string, error) {
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session := &models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		UserAgent:        c.Request.UserAgent(),
+		IP:               c.ClientIP(),
+		ExpiresAt:        time.Now().Add(h.cfg.RefreshTokenTTL),
+	}
+
+	if err := h.sessionRepo.Create(c.Request.Context(), session); err != nil {
+		return nil, "", err
+	}
+
+	return session, refreshToken, nil
+}
+
+// RefreshToken échange un refresh token contre un nouvel access token et un nouveau refresh token
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	tokenHash := utils.HashToken(req.RefreshToken)
+
+	session, err := h.sessionRepo.FindByRefreshTokenHash(ctx, tokenHash)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			apierror.Internal(c, err)
+			return
+		}
+
+		// Un ancien refresh token est présenté : la famille est compromise, on la révoque
+		reused, err := h.sessionRepo.FindByPreviousTokenHash(ctx, tokenHash)
+		if err == nil {
+			if err := h.sessionRepo.Revoke(ctx, reused.ID, models.SessionRevokedReuseDetected); err != nil {
+				apierror.Internal(c, err)
+				return
+			}
+		}
+
+		apierror.Abort(c, apierror.RefreshTokenInvalid)
+		return
+	}
+
+	if !session.IsActive() {
+		apierror.Abort(c, apierror.SessionInvalid)
+		return
+	}
+
+	// Recharger l'utilisateur pour prendre en compte un éventuel changement de rôle
+	user, err := h.userRepo.FindByID(ctx, session.UserID.Hex())
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			h.sessionRepo.Revoke(ctx, session.ID, models.SessionRevokedUserDeleted)
+			apierror.Abort(c, apierror.SessionInvalid)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	newRefreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	rotated, err := h.sessionRepo.Rotate(ctx, session.ID, tokenHash, utils.HashToken(newRefreshToken), time.Now().Add(h.cfg.RefreshTokenTTL))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !rotated {
+		// Le token a été consommé entre-temps par une autre requête : même traitement qu'une réutilisation
+		h.sessionRepo.Revoke(ctx, session.ID, models.SessionRevokedReuseDetected)
+		apierror.Abort(c, apierror.RefreshTokenInvalid)
+		return
+	}
+
+	token, err := h.tokens.Generate(user.ID, user.RoleID, user.Email, session.ID.Hex())
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.Header("Authorization", "Bearer "+token)
+
+	c.JSON(http.StatusOK, models.RefreshTokenResponse{
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(h.tokens.TTL().Seconds()),
+	})
+}
+
+// Logout révoque la session courante
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionIDInterface, exists := c.Get("session_id")
+	if !exists {
+		apierror.Abort(c, apierror.SessionInvalid)
+		return
+	}
+
+	sessionID, ok := sessionIDInterface.(primitive.ObjectID)
+	if !ok {
+		apierror.Internal(c, nil)
+		return
+	}
+
+	if err := h.sessionRepo.Revoke(c.Request.Context(), sessionID, models.SessionRevokedLogout); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Déconnexion réussie",
+	})
+}
+
+// LogoutAll révoque toutes les sessions de l'utilisateur courant
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return
+	}
+
+	userID, ok := userIDInterface.(primitive.ObjectID)
+	if !ok {
+		apierror.Internal(c, nil)
+		return
+	}
+
+	revoked, err := h.sessionRepo.RevokeAllByUserID(c.Request.Context(), userID, models.SessionRevokedLogoutAll)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Toutes les sessions ont été fermées",
+		"revoked_sessions": revoked,
+	})
+}
+
 func (h *AuthHandler) GetRoles(c *gin.Context) {
 	ctx := c.Request.Context()
 	
 	roles, err := h.roleRepo.GetAll(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération des rôles",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 	
 	var rolesResponse []gin.H
 	for _, role := range roles {
 		rolesResponse = append(rolesResponse, gin.H{
-			"id":   role.ID,
-			"name": role.Name,
-			"slug": role.Slug,
+			"id":          role.ID,
+			"name":        role.Name,
+			"slug":        role.Slug,
+			"permissions": role.Permissions,
+			"is_system":   role.IsSystem,
 		})
 	}
 	
@@ -186,14 +351,34 @@ func (h *AuthHandler) GetRoles(c *gin.Context) {
 	})
 }
 
+// GetPermissions liste les permissions pouvant être attribuées aux rôles
+func (h *AuthHandler) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"permissions": models.AllPermissions,
+	})
+}
+
+// validatePermissions retourne les permissions inconnues du catalogue
+func validatePermissions(permissions []string) []string {
+	var invalid []string
+	for _, p := range permissions {
+		if !models.IsValidPermission(p) {
+			invalid = append(invalid, p)
+		}
+	}
+	return invalid
+}
+
 func (h *AuthHandler) CreateRole(c *gin.Context) {
 	var req models.CreateRoleRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Données invalides",
-			"details": err.Error(),
-		})
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	if invalid := validatePermissions(req.Permissions); len(invalid) > 0 {
+		apierror.AbortWithDetails(c, apierror.UnknownPermissions, gin.H{"permissions": invalid})
 		return
 	}
 
@@ -202,25 +387,19 @@ func (h *AuthHandler) CreateRole(c *gin.Context) {
 	// Vérifier si le slug existe déjà
 	exists, err := h.roleRepo.ExistsBySlug(ctx, req.Slug)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la vérification du slug",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
 	if exists {
-		c.JSON(http.StatusConflict, gin.H{
-			"error": "Un rôle avec ce slug existe déjà",
-		})
+		apierror.Abort(c, apierror.RoleSlugTaken)
 		return
 	}
 
 	// Récupérer tous les rôles pour trouver le prochain ID
 	allRoles, err := h.roleRepo.GetAll(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération des rôles",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -238,70 +417,143 @@ func (h *AuthHandler) CreateRole(c *gin.Context) {
 	// Générer le prochain ID
 	nextID := fmt.Sprintf("%d", maxID+1)
 
+	permissions := req.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
 	role := &models.Role{
-		ID:   nextID,
-		Name: req.Name,
-		Slug: req.Slug,
+		ID:          nextID,
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Permissions: permissions,
 	}
 
 	if err := h.roleRepo.Create(ctx, role); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la création du rôle",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Rôle créé avec succès",
 		"role": gin.H{
-			"id":         role.ID,
-			"name":       role.Name,
-			"slug":       role.Slug,
-			"created_at": role.CreatedAt.Format(time.RFC3339),
+			"id":          role.ID,
+			"name":        role.Name,
+			"slug":        role.Slug,
+			"permissions": role.Permissions,
+			"is_system":   role.IsSystem,
+			"created_at":  role.CreatedAt.Format(time.RFC3339),
 		},
 	})
 }
 
-func (h *AuthHandler) DeleteRole(c *gin.Context) {
+// UpdateRole modifie le nom et/ou les permissions d'un rôle
+func (h *AuthHandler) UpdateRole(c *gin.Context) {
 	roleID := c.Param("id")
 	if roleID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID du rôle manquant",
-		})
+		apierror.Abort(c, apierror.MissingID)
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
 		return
 	}
 
 	ctx := c.Request.Context()
 
-	// Empêcher la suppression des rôles système
-	if roleID == "1" || roleID == "2" || roleID == "3" || roleID == "4" {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Impossible de supprimer un rôle système",
-		})
+	role, err := h.roleRepo.FindByID(ctx, roleID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.RoleNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
 		return
 	}
 
+	updates := bson.M{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Permissions != nil {
+		if invalid := validatePermissions(*req.Permissions); len(invalid) > 0 {
+			apierror.AbortWithDetails(c, apierror.UnknownPermissions, gin.H{"permissions": invalid})
+			return
+		}
+
+		// Le super administrateur doit toujours pouvoir gérer les rôles
+		if role.Slug == models.RoleSuperAdmin && !containsString(*req.Permissions, models.PermissionRolesManage) {
+			apierror.Abort(c, apierror.RolePermissionRequired, models.PermissionRolesManage)
+			return
+		}
+		updates["permissions"] = *req.Permissions
+	}
+
+	if len(updates) == 0 {
+		apierror.Abort(c, apierror.NothingToUpdate)
+		return
+	}
+
+	if err := h.roleRepo.Update(ctx, roleID, updates); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	h.authz.Invalidate(roleID)
+
+	updatedRole, err := h.roleRepo.FindByID(ctx, roleID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rôle mis à jour avec succès",
+		"role":    updatedRole,
+	})
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *AuthHandler) DeleteRole(c *gin.Context) {
+	roleID := c.Param("id")
+	if roleID == "" {
+		apierror.Abort(c, apierror.MissingID)
+		return
+	}
+
+	ctx := c.Request.Context()
+
 	// Vérifier que le rôle existe
-	_, err := h.roleRepo.FindByID(ctx, roleID)
+	role, err := h.roleRepo.FindByID(ctx, roleID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Rôle introuvable",
-			})
+			apierror.Abort(c, apierror.RoleNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la recherche du rôle",
-			})
+			apierror.Internal(c, err)
 		}
 		return
 	}
 
+	// Empêcher la suppression des rôles système
+	if role.IsSystem {
+		apierror.Abort(c, apierror.RoleSystemProtected)
+		return
+	}
+
 	if err := h.roleRepo.Delete(ctx, roleID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la suppression du rôle",
-		})
+		apierror.Internal(c, err)
 		return
 	}
+	h.authz.Invalidate(roleID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Rôle supprimé avec succès",
@@ -314,9 +566,7 @@ func (h *AuthHandler) GetAllUsers(c *gin.Context) {
 
 	users, err := h.userRepo.GetAll(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération des utilisateurs",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -351,18 +601,13 @@ func (h *AuthHandler) GetAllUsers(c *gin.Context) {
 func (h *AuthHandler) UpdateUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID utilisateur manquant",
-		})
+		apierror.Abort(c, apierror.MissingID)
 		return
 	}
 
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Données invalides",
-			"details": err.Error(),
-		})
+		apierror.AbortBinding(c, err)
 		return
 	}
 
@@ -372,13 +617,9 @@ func (h *AuthHandler) UpdateUser(c *gin.Context) {
 	user, err := h.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Utilisateur introuvable",
-			})
+			apierror.Abort(c, apierror.UserNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la recherche de l'utilisateur",
-			})
+			apierror.Internal(c, err)
 		}
 		return
 	}
@@ -399,15 +640,11 @@ func (h *AuthHandler) UpdateUser(c *gin.Context) {
 		if req.Email != user.Email {
 			exists, err := h.userRepo.ExistsByEmail(ctx, req.Email)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Erreur lors de la vérification de l'email",
-				})
+				apierror.Internal(c, err)
 				return
 			}
 			if exists {
-				c.JSON(http.StatusConflict, gin.H{
-					"error": "Cet email est déjà utilisé",
-				})
+				apierror.Abort(c, apierror.EmailTaken)
 				return
 			}
 		}
@@ -417,9 +654,7 @@ func (h *AuthHandler) UpdateUser(c *gin.Context) {
 	if req.Password != "" {
 		hashedPassword, err := utils.HashPassword(req.Password)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors du hachage du mot de passe",
-			})
+			apierror.Internal(c, err)
 			return
 		}
 		updates["password"] = hashedPassword
@@ -430,14 +665,9 @@ func (h *AuthHandler) UpdateUser(c *gin.Context) {
 		_, err := h.roleRepo.FindByID(ctx, req.RoleID)
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": "Rôle introuvable",
-					"role_id": req.RoleID,
-				})
+				apierror.AbortWithDetails(c, apierror.UnknownRole, gin.H{"role_id": req.RoleID})
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Erreur lors de la recherche du rôle",
-				})
+				apierror.Internal(c, err)
 			}
 			return
 		}
@@ -446,38 +676,41 @@ func (h *AuthHandler) UpdateUser(c *gin.Context) {
 
 	// Si aucune mise à jour n'est demandée
 	if len(updates) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Aucune donnée à mettre à jour",
-		})
+		apierror.Abort(c, apierror.NothingToUpdate)
 		return
 	}
 
 	// Mettre à jour l'utilisateur
 	if err := h.userRepo.Update(ctx, userID, updates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la mise à jour de l'utilisateur",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
+	// Un changement de mot de passe ferme toutes les sessions de l'utilisateur
+	if req.Password != "" {
+		if _, err := h.sessionRepo.RevokeAllByUserID(ctx, user.ID, models.SessionRevokedPasswordChanged); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+	}
+
 	// Récupérer l'utilisateur mis à jour
 	updatedUser, err := h.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération de l'utilisateur mis à jour",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Utilisateur mis à jour avec succès",
 		"user": models.UserProfile{
-			ID:        updatedUser.ID,
-			Nom:       updatedUser.Nom,
-			Prenom:    updatedUser.Prenom,
-			Email:     updatedUser.Email,
-			RoleID:    updatedUser.RoleID,
-			CreatedAt: updatedUser.CreatedAt,
+			ID:            updatedUser.ID,
+			Nom:           updatedUser.Nom,
+			Prenom:        updatedUser.Prenom,
+			Email:         updatedUser.Email,
+			RoleID:        updatedUser.RoleID,
+			EmailVerified: !updatedUser.EmailVerificationPending,
+			CreatedAt:     updatedUser.CreatedAt,
 		},
 	})
 }
@@ -485,34 +718,32 @@ func (h *AuthHandler) UpdateUser(c *gin.Context) {
 func (h *AuthHandler) DeleteUser(c *gin.Context) {
 	userID := c.Param("id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID utilisateur manquant",
-		})
+		apierror.Abort(c, apierror.MissingID)
 		return
 	}
 
 	ctx := c.Request.Context()
 
 	// Vérifier que l'utilisateur existe
-	_, err := h.userRepo.FindByID(ctx, userID)
+	user, err := h.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Utilisateur introuvable",
-			})
+			apierror.Abort(c, apierror.UserNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la recherche de l'utilisateur",
-			})
+			apierror.Internal(c, err)
 		}
 		return
 	}
 
 	// Supprimer l'utilisateur
 	if err := h.userRepo.Delete(ctx, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la suppression de l'utilisateur",
-		})
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Fermer les sessions encore ouvertes
+	if _, err := h.sessionRepo.RevokeAllByUserID(ctx, user.ID, models.SessionRevokedUserDeleted); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -526,17 +757,13 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	// Récupérer l'ID de l'utilisateur depuis le contexte (défini par le middleware)
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Utilisateur non authentifié",
-		})
+		apierror.Abort(c, apierror.Unauthenticated)
 		return
 	}
 
 	userID, ok := userIDInterface.(primitive.ObjectID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération de l'ID utilisateur",
-		})
+		apierror.Internal(c, nil)
 		return
 	}
 
@@ -546,13 +773,9 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	user, err := h.userRepo.FindByID(ctx, userID.Hex())
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Utilisateur introuvable",
-			})
+			apierror.Abort(c, apierror.UserNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la récupération de l'utilisateur",
-			})
+			apierror.Internal(c, err)
 		}
 		return
 	}
@@ -584,26 +807,19 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	// Récupérer l'ID de l'utilisateur depuis le contexte
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Utilisateur non authentifié",
-		})
+		apierror.Abort(c, apierror.Unauthenticated)
 		return
 	}
 
 	userID, ok := userIDInterface.(primitive.ObjectID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération de l'ID utilisateur",
-		})
+		apierror.Internal(c, nil)
 		return
 	}
 
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Données invalides",
-			"details": err.Error(),
-		})
+		apierror.AbortBinding(c, err)
 		return
 	}
 
@@ -613,13 +829,9 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	user, err := h.userRepo.FindByID(ctx, userID.Hex())
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Utilisateur introuvable",
-			})
+			apierror.Abort(c, apierror.UserNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la recherche de l'utilisateur",
-			})
+			apierror.Internal(c, err)
 		}
 		return
 	}
@@ -637,15 +849,11 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		if req.Email != user.Email {
 			exists, err := h.userRepo.ExistsByEmail(ctx, req.Email)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Erreur lors de la vérification de l'email",
-				})
+				apierror.Internal(c, err)
 				return
 			}
 			if exists {
-				c.JSON(http.StatusConflict, gin.H{
-					"error": "Cet email est déjà utilisé par un autre compte",
-				})
+				apierror.Abort(c, apierror.EmailTaken)
 				return
 			}
 		}
@@ -654,9 +862,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	if req.Password != "" {
 		hashedPassword, err := utils.HashPassword(req.Password)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors du hachage du mot de passe",
-			})
+			apierror.Internal(c, err)
 			return
 		}
 		updates["password"] = hashedPassword
@@ -674,30 +880,37 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 
 	// Appliquer les mises à jour
 	if err := h.userRepo.Update(ctx, userID.Hex(), updates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la mise à jour du profil",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
+	// Un changement de mot de passe ferme les autres sessions de l'utilisateur
+	if req.Password != "" {
+		currentSessionID, _ := c.Get("session_id")
+		sessionID, _ := currentSessionID.(primitive.ObjectID)
+		if _, err := h.sessionRepo.RevokeOthersByUserID(ctx, userID, sessionID, models.SessionRevokedPasswordChanged); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+	}
+
 	// Récupérer l'utilisateur mis à jour
 	updatedUser, err := h.userRepo.FindByID(ctx, userID.Hex())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération du profil mis à jour",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Profil mis à jour avec succès",
 		"user": models.UserProfile{
-			ID:        updatedUser.ID,
-			Nom:       updatedUser.Nom,
-			Prenom:    updatedUser.Prenom,
-			Email:     updatedUser.Email,
-			RoleID:    updatedUser.RoleID,
-			CreatedAt: updatedUser.CreatedAt,
+			ID:            updatedUser.ID,
+			Nom:           updatedUser.Nom,
+			Prenom:        updatedUser.Prenom,
+			Email:         updatedUser.Email,
+			RoleID:        updatedUser.RoleID,
+			EmailVerified: !updatedUser.EmailVerificationPending,
+			CreatedAt:     updatedUser.CreatedAt,
 		},
 	})
 }
@@ -707,17 +920,13 @@ func (h *AuthHandler) DeleteAccount(c *gin.Context) {
 	// Récupérer l'ID de l'utilisateur depuis le contexte
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Utilisateur non authentifié",
-		})
+		apierror.Abort(c, apierror.Unauthenticated)
 		return
 	}
 
 	userID, ok := userIDInterface.(primitive.ObjectID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération de l'ID utilisateur",
-		})
+		apierror.Internal(c, nil)
 		return
 	}
 
@@ -727,31 +936,72 @@ func (h *AuthHandler) DeleteAccount(c *gin.Context) {
 	_, err := h.userRepo.FindByID(ctx, userID.Hex())
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Utilisateur introuvable",
-			})
+			apierror.Abort(c, apierror.UserNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la recherche de l'utilisateur",
-			})
+			apierror.Internal(c, err)
 		}
 		return
 	}
 
-	// Supprimer tous les logements de l'utilisateur
+	// Le dernier propriétaire d'une organisation doit d'abord passer la main ou la supprimer
+	memberships, err := h.orgRepo.FindMembershipsByUserID(ctx, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	for _, membership := range memberships {
+		if membership.Role != models.OrganizationRoleOwner {
+			continue
+		}
+		owners, err := h.orgRepo.CountOwners(ctx, membership.OrganizationID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		if owners <= 1 {
+			apierror.AbortWithDetails(c, apierror.OrganizationLastOwner, gin.H{"organizationId": membership.OrganizationID})
+			return
+		}
+	}
+
+	// Supprimer les logements personnels de l'utilisateur
 	deletedProperties, err := h.propertyRepo.DeleteByHostID(ctx, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la suppression des logements",
-		})
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Les logements gérés pour des organisations leur restent acquis
+	if err := h.handOverOrganizationProperties(ctx, userID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.templateRepo.DeleteByHostID(ctx, userID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Quitter les équipes des propriétés des autres hôtes
+	if err := h.memberRepo.DeleteByUserID(ctx, userID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.orgRepo.DeleteMembershipsByUserID(ctx, userID); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Supprimer l'utilisateur
 	if err := h.userRepo.Delete(ctx, userID.Hex()); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la suppression du compte",
-		})
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Fermer les sessions encore ouvertes
+	if _, err := h.sessionRepo.RevokeAllByUserID(ctx, userID, models.SessionRevokedUserDeleted); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -760,3 +1010,283 @@ func (h *AuthHandler) DeleteAccount(c *gin.Context) {
 		"deleted_properties": deletedProperties,
 	})
 }
+
+// handOverOrganizationProperties confie à un propriétaire de chaque organisation les
+// logements qu'un utilisateur y gérait
+func (h *AuthHandler) handOverOrganizationProperties(ctx context.Context, userID primitive.ObjectID) error {
+	organizationIDs, err := h.propertyRepo.FindOrganizationIDsByHostID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, organizationID := range organizationIDs {
+		members, err := h.orgRepo.FindMembers(ctx, organizationID)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			if member.Role == models.OrganizationRoleOwner && member.UserID != userID {
+				if err := h.propertyRepo.ReassignOrganizationHost(ctx, organizationID, userID, member.UserID); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// issueUserToken invalide les tokens précédents du même usage et en crée un nouveau, retourné en clair
+func (h *AuthHandler) issueUserToken(ctx context.Context, userID primitive.ObjectID, purpose string, ttl time.Duration) (string, error) {
+	if err := h.tokenRepo.InvalidateByUserID(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	token := &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := h.tokenRepo.Create(ctx, token); err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// ForgotPassword envoie un lien de réinitialisation du mot de passe.
+// La réponse est identique que l'email existe ou non, pour ne pas révéler les comptes.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	response := gin.H{
+		"message": "Si un compte existe pour cet email, un lien de réinitialisation a été envoyé",
+	}
+
+	user, err := h.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Erreur lors de la recherche de l'utilisateur %s: %v", req.Email, err)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	rawToken, err := h.issueUserToken(ctx, user.ID, models.TokenPurposePasswordReset, h.cfg.PasswordResetTTL)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", h.cfg.FrontendURL, rawToken)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Réinitialisation de votre mot de passe OneStay",
+		Body: fmt.Sprintf(
+			"Bonjour %s,\n\nPour choisir un nouveau mot de passe, ouvrez le lien suivant :\n%s\n\nCe lien expire dans %s et ne peut être utilisé qu'une fois.\nSi vous n'êtes pas à l'origine de cette demande, ignorez cet email.",
+			user.Prenom, link, h.cfg.PasswordResetTTL,
+		),
+	}
+
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Erreur lors de l'envoi de l'email de réinitialisation à %s: %v", user.Email, err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword change le mot de passe à partir d'un token de réinitialisation et ferme toutes les sessions
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	resetToken, err := h.tokenRepo.Consume(ctx, utils.HashToken(req.Token), models.TokenPurposePasswordReset)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.ResetLinkInvalid)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.userRepo.Update(ctx, resetToken.UserID.Hex(), bson.M{"password": hashedPassword}); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if _, err := h.sessionRepo.RevokeAllByUserID(ctx, resetToken.UserID, models.SessionRevokedPasswordChanged); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mot de passe réinitialisé avec succès",
+	})
+}
+
+// Signup permet à un visiteur de créer un compte client ou loueur.
+// Le compte reste inutilisable tant que l'adresse email n'a pas été confirmée.
+func (h *AuthHandler) Signup(c *gin.Context) {
+	var req models.SignupRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	exists, err := h.userRepo.ExistsByEmail(ctx, req.Email)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if exists {
+		apierror.Abort(c, apierror.EmailTaken)
+		return
+	}
+
+	role, err := h.roleRepo.FindBySlug(ctx, req.Role)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	user := &models.User{
+		Nom:                      req.Nom,
+		Prenom:                   req.Prenom,
+		Email:                    req.Email,
+		Password:                 hashedPassword,
+		RoleID:                   role.ID,
+		EmailVerificationPending: true,
+	}
+
+	if err := h.userRepo.Create(ctx, user); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Erreur lors de l'envoi de l'email de vérification à %s: %v", user.Email, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Compte créé. Consultez vos emails pour confirmer votre adresse",
+		"user": models.UserProfile{
+			ID:            user.ID,
+			Nom:           user.Nom,
+			Prenom:        user.Prenom,
+			Email:         user.Email,
+			RoleID:        user.RoleID,
+			EmailVerified: false,
+			CreatedAt:     user.CreatedAt,
+		},
+	})
+}
+
+// sendVerificationEmail génère un lien de confirmation et l'envoie à l'utilisateur
+func (h *AuthHandler) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	rawToken, err := h.issueUserToken(ctx, user.ID, models.TokenPurposeEmailVerification, h.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", h.cfg.FrontendURL, rawToken)
+	return h.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirmez votre adresse email OneStay",
+		Body: fmt.Sprintf(
+			"Bonjour %s,\n\nBienvenue sur OneStay ! Pour activer votre compte, confirmez votre adresse email en ouvrant le lien suivant :\n%s\n\nCe lien expire dans %s.",
+			user.Prenom, link, h.cfg.EmailVerificationTTL,
+		),
+	})
+}
+
+// VerifyEmail confirme l'adresse email à partir du token reçu par email
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	token, err := h.tokenRepo.Consume(ctx, utils.HashToken(req.Token), models.TokenPurposeEmailVerification)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.VerificationLinkInvalid)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	if err := h.userRepo.MarkEmailVerified(ctx, token.UserID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Adresse email confirmée, vous pouvez maintenant vous connecter",
+	})
+}
+
+// ResendVerification renvoie un lien de vérification aux comptes non confirmés
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	user, err := h.userRepo.FindByEmail(ctx, req.Email)
+	if err == nil && user.EmailVerificationPending {
+		if err := h.sendVerificationEmail(ctx, user); err != nil {
+			log.Printf("Erreur lors de l'envoi de l'email de vérification à %s: %v", user.Email, err)
+		}
+	} else if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("Erreur lors de la recherche de l'utilisateur %s: %v", req.Email, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Si un compte en attente de confirmation existe pour cet email, un nouveau lien a été envoyé",
+	})
+}
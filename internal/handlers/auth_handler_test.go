@@ -0,0 +1,141 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSignupRequiresEmailVerification(t *testing.T) {
+	s := newTestServer(t)
+
+	res := s.do(http.MethodPost, "/api/v1/users/signup", "", gin.H{
+		"nom":      "Martin",
+		"prenom":   "Camille",
+		"email":    "camille@example.com",
+		"password": "motdepasse",
+		"role":     models.RoleLoueur,
+	})
+	expect(t, res, http.StatusCreated, "")
+
+	// Inscription déjà utilisée
+	res = s.do(http.MethodPost, "/api/v1/users/signup", "", gin.H{
+		"nom":      "Martin",
+		"prenom":   "Camille",
+		"email":    "camille@example.com",
+		"password": "motdepasse",
+		"role":     models.RoleClient,
+	})
+	expect(t, res, http.StatusConflict, apierror.EmailTaken)
+
+	credentials := gin.H{"email": "camille@example.com", "password": "motdepasse"}
+	res = s.do(http.MethodPost, "/api/v1/auth/login", "", credentials)
+	expect(t, res, http.StatusForbidden, apierror.EmailNotVerified)
+
+	token := s.mail.lastToken(t, "camille@example.com")
+	res = s.do(http.MethodPost, "/api/v1/auth/verify-email", "", gin.H{"token": token})
+	expect(t, res, http.StatusOK, "")
+
+	// Le lien ne sert qu'une fois
+	res = s.do(http.MethodPost, "/api/v1/auth/verify-email", "", gin.H{"token": token})
+	expect(t, res, http.StatusBadRequest, apierror.VerificationLinkInvalid)
+
+	accessToken := s.login("camille@example.com")
+	res = s.do(http.MethodGet, "/api/v1/users/profile", accessToken, nil)
+	expect(t, res, http.StatusOK, "")
+	role, _ := res.Body["role"].(map[string]any)
+	if role["slug"] != models.RoleLoueur {
+		t.Errorf("rôle du profil %v, attendu %s", role["slug"], models.RoleLoueur)
+	}
+}
+
+func TestLoginRejectsInvalidCredentials(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("alice@example.com", "1")
+
+	res := s.do(http.MethodPost, "/api/v1/auth/login", "", gin.H{"email": "alice@example.com", "password": "mauvais"})
+	expect(t, res, http.StatusUnauthorized, apierror.InvalidCredentials)
+
+	res = s.do(http.MethodPost, "/api/v1/auth/login", "", gin.H{"email": "inconnu@example.com", "password": "motdepasse"})
+	expect(t, res, http.StatusUnauthorized, apierror.InvalidCredentials)
+}
+
+func TestAuthMiddlewareRejectsMissingAndRevokedTokens(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("alice@example.com", "1")
+
+	res := s.do(http.MethodGet, "/api/v1/users/profile", "", nil)
+	expect(t, res, http.StatusUnauthorized, apierror.TokenMissing)
+
+	res = s.do(http.MethodGet, "/api/v1/users/profile", "pas-un-jwt", nil)
+	expect(t, res, http.StatusUnauthorized, "")
+
+	token := s.login("alice@example.com")
+	expect(t, s.do(http.MethodPost, "/api/v1/auth/logout", token, nil), http.StatusOK, "")
+
+	// L'access token n'est plus accepté une fois la session fermée
+	res = s.do(http.MethodGet, "/api/v1/users/profile", token, nil)
+	expect(t, res, http.StatusUnauthorized, apierror.SessionInvalid)
+}
+
+func TestRolesRequirePermissions(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("client@example.com", "1")
+	s.createUser("admin@example.com", "3")
+	s.createUser("root@example.com", "4")
+
+	client := s.login("client@example.com")
+	admin := s.login("admin@example.com")
+	root := s.login("root@example.com")
+
+	expect(t, s.do(http.MethodGet, "/api/v1/auth/roles", client, nil), http.StatusForbidden, apierror.Forbidden)
+	expect(t, s.do(http.MethodGet, "/api/v1/auth/roles", admin, nil), http.StatusOK, "")
+
+	// Gérer les rôles est réservé au super administrateur
+	role := gin.H{"name": "Modérateur", "slug": "moderateur", "permissions": []string{models.PermissionUsersRead}}
+	expect(t, s.do(http.MethodPost, "/api/v1/auth/roles", admin, role), http.StatusForbidden, apierror.Forbidden)
+
+	res := s.do(http.MethodPost, "/api/v1/auth/roles", root, role)
+	expect(t, res, http.StatusCreated, "")
+	created, _ := res.Body["role"].(map[string]any)
+	if created["id"] != "5" {
+		t.Fatalf("ID du rôle créé %v, attendu 5", created["id"])
+	}
+
+	expect(t, s.do(http.MethodPost, "/api/v1/auth/roles", root, role), http.StatusConflict, apierror.RoleSlugTaken)
+
+	unknown := gin.H{"name": "Inconnu", "slug": "inconnu", "permissions": []string{"nope:nope"}}
+	expect(t, s.do(http.MethodPost, "/api/v1/auth/roles", root, unknown), http.StatusBadRequest, apierror.UnknownPermissions)
+
+	expect(t, s.do(http.MethodDelete, "/api/v1/auth/roles/3", root, nil), http.StatusForbidden, apierror.RoleSystemProtected)
+	expect(t, s.do(http.MethodDelete, "/api/v1/auth/roles/5", root, nil), http.StatusOK, "")
+	expect(t, s.do(http.MethodDelete, "/api/v1/auth/roles/5", root, nil), http.StatusNotFound, apierror.RoleNotFound)
+}
+
+func TestRoleUpdateAppliesToOpenSessions(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("root@example.com", "4")
+	user := s.createUser("modo@example.com", "1")
+
+	root := s.login("root@example.com")
+	res := s.do(http.MethodPost, "/api/v1/auth/roles", root, gin.H{"name": "Modérateur", "slug": "moderateur"})
+	expect(t, res, http.StatusCreated, "")
+
+	if err := s.stores.Users.Update(context.Background(), user.ID.Hex(), bson.M{"role_id": "5"}); err != nil {
+		t.Fatal(err)
+	}
+	modo := s.login("modo@example.com")
+	expect(t, s.do(http.MethodGet, "/api/v1/auth/roles", modo, nil), http.StatusForbidden, apierror.Forbidden)
+
+	res = s.do(http.MethodPut, "/api/v1/auth/roles/5", root, gin.H{"permissions": []string{models.PermissionRolesRead}})
+	expect(t, res, http.StatusOK, "")
+
+	// Les permissions sont relues sans que le modérateur ait à se reconnecter
+	expect(t, s.do(http.MethodGet, "/api/v1/auth/roles", modo, nil), http.StatusOK, "")
+}
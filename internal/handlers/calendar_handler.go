@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/calendarsync"
+	"onestay-back/internal/ical"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type CalendarHandler struct {
+	propertyRepo    repository.PropertyStore
+	reservationRepo repository.ReservationStore
+	blockRepo       repository.CalendarBlockStore
+	feedRepo        repository.CalendarFeedStore
+	calendarLocks   repository.CalendarLockStore
+	syncer          *calendarsync.Syncer
+	authz           *rbac.Service
+}
+
+func NewCalendarHandler(stores *repository.Stores, syncer *calendarsync.Syncer, authz *rbac.Service) *CalendarHandler {
+	return &CalendarHandler{
+		propertyRepo:    stores.Properties,
+		reservationRepo: stores.Reservations,
+		blockRepo:       stores.CalendarBlocks,
+		feedRepo:        stores.CalendarFeeds,
+		calendarLocks:   stores.CalendarLocks,
+		syncer:          syncer,
+		authz:           authz,
+	}
+}
+
+// ExportCalendar génère le flux iCal des indisponibilités (réservations et périodes bloquées).
+// Le flux d'une propriété publiée est public afin d'être importé par les autres plateformes :
+// il ne contient aucune information sur les voyageurs.
+func (h *CalendarHandler) ExportCalendar(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	property, err := findProperty(ctx, h.propertyRepo, c.Param("id"))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.PropertyNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	if !ensureVisible(c, h.authz, property) {
+		return
+	}
+
+	reservations, err := h.reservationRepo.FindByPropertyID(ctx, property.ID, "", nil, nil)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	blocks, err := h.blockRepo.FindByPropertyID(ctx, property.ID, nil, nil)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	cal := &ical.Calendar{
+		ProdID: "-//OneStay//Calendrier des disponibilités//FR",
+		Name:   property.Name,
+		Events: make([]ical.Event, 0, len(reservations)+len(blocks)),
+	}
+	for _, reservation := range reservations {
+		if reservation.Status == models.ReservationStatusCancelled {
+			continue
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:     "reservation-" + reservation.ID.Hex() + "@onestay",
+			Summary: "Réservé",
+			Start:   reservation.ArrivalDate,
+			End:     reservation.DepartureDate,
+			AllDay:  true,
+		})
+	}
+	for _, block := range blocks {
+		cal.Events = append(cal.Events, ical.Event{
+			UID:     "block-" + block.ID.Hex() + "@onestay",
+			Summary: "Indisponible",
+			Start:   block.StartDate,
+			End:     block.EndDate,
+			AllDay:  true,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, cal); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+property.Slug+`.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// GetCalendar retourne les réservations et périodes bloquées d'une propriété (filtres optionnels : from, to)
+func (h *CalendarHandler) GetCalendar(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionRead)
+	if !ok {
+		return
+	}
+
+	var from, to *time.Time
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse(models.ReservationDateLayout, value)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidDate, "from")
+			return
+		}
+		from = &date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse(models.ReservationDateLayout, value)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidDate, "to")
+			return
+		}
+		to = &date
+	}
+
+	ctx := c.Request.Context()
+
+	reservations, err := h.reservationRepo.FindByPropertyID(ctx, property.ID, "", from, to)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	blocks, err := h.blockRepo.FindByPropertyID(ctx, property.ID, from, to)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reservations": reservations,
+		"blocks":       blocks,
+	})
+}
+
+// CreateBlock bloque manuellement une période (travaux, usage personnel...)
+func (h *CalendarHandler) CreateBlock(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCalendarBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	start, err := time.Parse(models.ReservationDateLayout, req.StartDate)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidDate, "startDate")
+		return
+	}
+	end, err := time.Parse(models.ReservationDateLayout, req.EndDate)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidDate, "endDate")
+		return
+	}
+	if !end.After(start) {
+		apierror.Abort(c, apierror.InvalidDateRange)
+		return
+	}
+
+	unlock, ok := lockCalendar(c, h.calendarLocks, property.ID)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	ctx := c.Request.Context()
+
+	overlap, err := h.reservationRepo.HasOverlap(ctx, property.ID, start, end, nil)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if overlap {
+		apierror.Abort(c, apierror.ReservationOverlap)
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	block := &models.CalendarBlock{
+		PropertyID: property.ID,
+		Source:     models.CalendarBlockSourceManual,
+		Summary:    req.Summary,
+		StartDate:  start,
+		EndDate:    end,
+		CreatedBy:  &userID,
+	}
+
+	if err := h.blockRepo.Create(ctx, block); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Dates bloquées avec succès",
+		"block":   block,
+	})
+}
+
+// DeleteBlock débloque une période bloquée manuellement
+func (h *CalendarHandler) DeleteBlock(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	blockID, err := primitive.ObjectIDFromHex(c.Param("blockId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	block, err := h.blockRepo.FindByID(ctx, blockID)
+	if err != nil || block.PropertyID != property.ID {
+		apierror.Abort(c, apierror.CalendarBlockNotFound)
+		return
+	}
+
+	// Les périodes importées sont gérées par la synchronisation de leur calendrier
+	if block.Source != models.CalendarBlockSourceManual {
+		apierror.Abort(c, apierror.CalendarBlockImported)
+		return
+	}
+
+	if err := h.blockRepo.Delete(ctx, block.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dates débloquées avec succès",
+	})
+}
+
+// GetFeeds liste les calendriers externes d'une propriété
+func (h *CalendarHandler) GetFeeds(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	feeds, err := h.feedRepo.FindByPropertyID(c.Request.Context(), property.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"feeds": feeds,
+		"count": len(feeds),
+	})
+}
+
+// CreateFeed enregistre un calendrier iCal externe et lance sa première synchronisation
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCalendarFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	// Le serveur téléchargera l'URL : elle ne doit pas mener à un service interne
+	if err := calendarsync.ValidateURL(c.Request.Context(), req.URL); err != nil {
+		switch {
+		case errors.Is(err, calendarsync.ErrForbiddenAddress):
+			apierror.Abort(c, apierror.CalendarFeedHostForbidden)
+		case errors.Is(err, calendarsync.ErrUnknownHost):
+			apierror.Abort(c, apierror.CalendarFeedHostUnknown)
+		default:
+			apierror.Abort(c, apierror.CalendarFeedURLInvalid)
+		}
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	feed := &models.CalendarFeed{
+		PropertyID: property.ID,
+		Name:       req.Name,
+		URL:        req.URL,
+		CreatedBy:  userID,
+	}
+
+	if err := h.feedRepo.Create(c.Request.Context(), feed); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Première synchronisation par la tâche de fond : la plateforme distante peut être
+	// lente, et l'arrêt du serveur doit pouvoir l'interrompre
+	h.syncer.Enqueue(*feed)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Calendrier externe ajouté, synchronisation en cours",
+		"feed":    feed,
+	})
+}
+
+// SyncFeed synchronise immédiatement un calendrier externe
+func (h *CalendarHandler) SyncFeed(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	feed, ok := h.loadFeed(c, property)
+	if !ok {
+		return
+	}
+
+	count, err := h.syncer.SyncFeed(c.Request.Context(), feed)
+	if err != nil {
+		log.Printf("Synchronisation du calendrier %s: %v", feed.ID.Hex(), err)
+		apierror.Abort(c, apierror.CalendarSyncFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Calendrier synchronisé avec succès",
+		"blocks":  count,
+	})
+}
+
+// DeleteFeed supprime un calendrier externe et les périodes qu'il avait importées
+func (h *CalendarHandler) DeleteFeed(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	feed, ok := h.loadFeed(c, property)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.blockRepo.DeleteByFeedID(ctx, feed.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.feedRepo.Delete(ctx, feed.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Calendrier externe supprimé avec succès",
+	})
+}
+
+// loadFeed charge le calendrier externe de l'URL et vérifie qu'il appartient à la propriété
+func (h *CalendarHandler) loadFeed(c *gin.Context, property *models.Property) (*models.CalendarFeed, bool) {
+	feedID, err := primitive.ObjectIDFromHex(c.Param("feedId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return nil, false
+	}
+
+	feed, err := h.feedRepo.FindByID(c.Request.Context(), feedID)
+	if err != nil || feed.PropertyID != property.ID {
+		apierror.Abort(c, apierror.CalendarFeedNotFound)
+		return nil, false
+	}
+
+	return feed, true
+}
@@ -0,0 +1,44 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"onestay-back/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateFeedOnlyAcceptsPublicAddresses(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("hote@example.com", "2")
+
+	host := s.login("hote@example.com")
+	path := "/api/v1/properties/" + s.createProperty(host, "Cabane du Port") + "/calendar/feeds"
+
+	for _, url := range []string{
+		"http://127.0.0.1:27017/calendar.ics",
+		"http://169.254.169.254/latest/meta-data/",
+		"https://10.0.0.12/calendar.ics",
+		"webcal://192.168.1.1/calendar.ics",
+		"http://[::1]/calendar.ics",
+		"http://[::ffff:127.0.0.1]/calendar.ics",
+		"http://0.0.0.0/calendar.ics",
+	} {
+		res := s.do(http.MethodPost, path, host, gin.H{"name": "Airbnb", "url": url})
+		expect(t, res, http.StatusBadRequest, apierror.CalendarFeedHostForbidden)
+	}
+
+	res := s.do(http.MethodPost, path, host, gin.H{"name": "Airbnb", "url": "ftp://example.com/calendar.ics"})
+	expect(t, res, http.StatusBadRequest, apierror.CalendarFeedURLInvalid)
+
+	// Seule l'adresse publique est enregistrée ; sa synchronisation est confiée à la tâche de fond
+	res = s.do(http.MethodPost, path, host, gin.H{"name": "Airbnb", "url": "https://93.184.216.34/calendar.ics"})
+	expect(t, res, http.StatusCreated, "")
+
+	res = s.do(http.MethodGet, path, host, nil)
+	expect(t, res, http.StatusOK, "")
+	if res.Body["count"] != float64(1) {
+		t.Errorf("%v calendriers enregistrés, attendu 1", res.Body["count"])
+	}
+}
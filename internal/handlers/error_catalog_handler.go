@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"onestay-back/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetErrorCatalog liste les codes d'erreur de l'API avec leur statut HTTP et leurs messages,
+// pour que les clients puissent traduire ou traiter chaque erreur par son code
+func GetErrorCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"locales": apierror.Locales,
+		"errors":  apierror.Catalog(),
+	})
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+	"onestay-back/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type GuestLinkHandler struct {
+	cfg           *config.Config
+	propertyRepo  repository.PropertyStore
+	guestLinkRepo repository.GuestLinkStore
+	authz         *rbac.Service
+}
+
+func NewGuestLinkHandler(cfg *config.Config, stores *repository.Stores, authz *rbac.Service) *GuestLinkHandler {
+	return &GuestLinkHandler{
+		cfg:           cfg,
+		propertyRepo:  stores.Properties,
+		guestLinkRepo: stores.GuestLinks,
+		authz:         authz,
+	}
+}
+
+// CreateGuestLink crée un lien voyageur pour une fenêtre de séjour
+func (h *GuestLinkHandler) CreateGuestLink(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	var req models.CreateGuestLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	if !req.ValidUntil.After(req.ValidFrom) {
+		apierror.Abort(c, apierror.InvalidDateRange)
+		return
+	}
+	if !req.ValidUntil.After(time.Now()) {
+		apierror.Abort(c, apierror.DateInPast)
+		return
+	}
+
+	rawToken, err := utils.GenerateRandomToken(24)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	link := &models.GuestLink{
+		PropertyID: property.ID,
+		CreatedBy:  userID,
+		Label:      req.Label,
+		TokenHash:  utils.HashToken(rawToken),
+		ValidFrom:  req.ValidFrom,
+		ValidUntil: req.ValidUntil,
+	}
+
+	if req.Pin != "" {
+		pinHash, err := utils.HashPassword(req.Pin)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		link.PinHash = pinHash
+		link.HasPin = true
+	}
+
+	if err := h.guestLinkRepo.Create(c.Request.Context(), link); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Le token n'est retourné qu'une seule fois : seule son empreinte est stockée
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Lien voyageur créé avec succès",
+		"link":    link,
+		"token":   rawToken,
+		"url":     fmt.Sprintf("%s/guest/%s", h.cfg.FrontendURL, rawToken),
+	})
+}
+
+// GetGuestLinks liste les liens voyageurs d'une propriété
+func (h *GuestLinkHandler) GetGuestLinks(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+
+	links, err := h.guestLinkRepo.FindByPropertyID(c.Request.Context(), property.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"links": links,
+		"count": len(links),
+	})
+}
+
+// RevokeGuestLink révoque un lien voyageur
+func (h *GuestLinkHandler) RevokeGuestLink(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	link, ok := h.loadLink(c, property)
+	if !ok {
+		return
+	}
+
+	if err := h.guestLinkRepo.Revoke(c.Request.Context(), link.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Lien voyageur révoqué avec succès",
+	})
+}
+
+// GetGuestLinkAccesses retourne l'historique d'utilisation d'un lien voyageur
+func (h *GuestLinkHandler) GetGuestLinkAccesses(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+
+	link, ok := h.loadLink(c, property)
+	if !ok {
+		return
+	}
+
+	accesses, err := h.guestLinkRepo.FindAccessesByLinkID(c.Request.Context(), link.ID, 200)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accesses": accesses,
+		"count":    len(accesses),
+	})
+}
+
+// GetGuestProperty retourne la propriété complète, codes d'accès compris, à un voyageur
+// disposant d'un lien valide. Le PIN éventuel est lu dans le header X-Guest-Pin ou, en POST, dans le corps.
+// Les contenus sont traduits selon ?lang= ou l'en-tête Accept-Language.
+func (h *GuestLinkHandler) GetGuestProperty(c *gin.Context) {
+	link, property, ok := resolveGuestLink(c, h.guestLinkRepo, h.propertyRepo, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	locale := localizeProperty(c, h.cfg, property)
+	property.Translations = nil
+
+	c.JSON(http.StatusOK, gin.H{
+		"property": property,
+		"locale":   locale,
+		"access": gin.H{
+			"label":      link.Label,
+			"validFrom":  link.ValidFrom,
+			"validUntil": link.ValidUntil,
+		},
+	})
+}
+
+// resolveGuestLink valide un token voyageur (fenêtre, révocation, PIN), journalise la tentative
+// et retourne la propriété non expurgée. En cas d'échec, la réponse d'erreur est déjà écrite.
+func resolveGuestLink(c *gin.Context, guestLinkRepo repository.GuestLinkStore, propertyRepo repository.PropertyStore, rawToken string) (*models.GuestLink, *models.Property, bool) {
+	ctx := c.Request.Context()
+
+	link, err := guestLinkRepo.FindByTokenHash(ctx, utils.HashToken(rawToken))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.GuestLinkNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, nil, false
+	}
+
+	access := &models.GuestLinkAccess{
+		LinkID:     link.ID,
+		PropertyID: link.PropertyID,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}
+
+	deny := func(code apierror.Code, reason string) {
+		access.Reason = reason
+		guestLinkRepo.LogAccess(ctx, access)
+		apierror.AbortWithDetails(c, code, gin.H{"reason": reason})
+	}
+
+	now := time.Now()
+	switch {
+	case link.RevokedAt != nil:
+		deny(apierror.GuestLinkRevoked, "revoked")
+		return nil, nil, false
+	case link.FailedAttempts >= models.GuestLinkMaxFailedAttempts:
+		deny(apierror.GuestLinkLocked, "locked")
+		return nil, nil, false
+	case now.Before(link.ValidFrom):
+		deny(apierror.GuestLinkNotStarted, "not_started")
+		return nil, nil, false
+	case !now.Before(link.ValidUntil):
+		deny(apierror.GuestLinkExpired, "expired")
+		return nil, nil, false
+	}
+
+	if link.HasPin {
+		// Les tentatives sans PIN sont journalisées : elles révèlent un lien en cours de sondage
+		pin := guestPin(c)
+		if pin == "" {
+			deny(apierror.PinRequired, "pin_required")
+			return nil, nil, false
+		}
+		if !utils.CheckPasswordHash(pin, link.PinHash) {
+			guestLinkRepo.RecordFailedAttempt(ctx, link.ID)
+			deny(apierror.PinInvalid, "invalid_pin")
+			return nil, nil, false
+		}
+	}
+
+	property, err := propertyRepo.FindByID(ctx, link.PropertyID)
+	if err != nil || property.Status != 2 {
+		apierror.Abort(c, apierror.PropertyNotFound)
+		return nil, nil, false
+	}
+
+	access.Success = true
+	guestLinkRepo.LogAccess(ctx, access)
+	guestLinkRepo.RecordUse(ctx, link.ID)
+
+	return link, property, true
+}
+
+// guestPin lit le PIN d'un lien voyageur dans le header X-Guest-Pin ou, pour une requête
+// POST, dans le corps
+func guestPin(c *gin.Context) string {
+	if pin := c.GetHeader("X-Guest-Pin"); pin != "" {
+		return pin
+	}
+	if c.Request.Method != http.MethodPost {
+		return ""
+	}
+
+	var req models.GuestPinRequest
+	if err := c.ShouldBind(&req); err != nil {
+		return ""
+	}
+	return req.Pin
+}
+
+// loadLink charge le lien de l'URL et vérifie qu'il appartient à la propriété
+func (h *GuestLinkHandler) loadLink(c *gin.Context, property *models.Property) (*models.GuestLink, bool) {
+	linkID, err := primitive.ObjectIDFromHex(c.Param("linkId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return nil, false
+	}
+
+	link, err := h.guestLinkRepo.FindByID(c.Request.Context(), linkID)
+	if err != nil || link.PropertyID != property.ID {
+		apierror.Abort(c, apierror.GuestLinkNotFound)
+		return nil, false
+	}
+
+	return link, true
+}
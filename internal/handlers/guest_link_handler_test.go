@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGuestPinIsNeverReadFromTheURL(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("hote@example.com", "2")
+
+	host := s.login("hote@example.com")
+	propertyID := s.createProperty(host, "Cabane du Port")
+
+	id, _ := primitive.ObjectIDFromHex(propertyID)
+	if err := s.stores.Properties.Update(context.Background(), id, bson.M{"status": models.PropertyStatusPublished}); err != nil {
+		t.Fatalf("publication : %v", err)
+	}
+
+	res := s.do(http.MethodPost, "/api/v1/properties/"+propertyID+"/guest-links", host, gin.H{
+		"label":      "Famille Martin",
+		"validFrom":  time.Now().Add(-time.Hour),
+		"validUntil": time.Now().Add(48 * time.Hour),
+		"pin":        "1234",
+	})
+	expect(t, res, http.StatusCreated, "")
+	token, _ := res.Body["token"].(string)
+	link, _ := res.Body["link"].(map[string]any)
+	linkID, _ := link["_id"].(string)
+
+	guest := "/api/v1/guest/" + token
+
+	// Un PIN dans l'URL finirait dans les journaux et l'historique du navigateur : il est ignoré
+	res = s.do(http.MethodGet, guest+"?pin=1234", "", nil)
+	expect(t, res, http.StatusUnauthorized, apierror.PinRequired)
+
+	res = s.doWithHeader(http.MethodGet, guest, "", http.Header{"X-Guest-Pin": {"0000"}}, nil)
+	expect(t, res, http.StatusUnauthorized, apierror.PinInvalid)
+
+	res = s.doWithHeader(http.MethodGet, guest, "", http.Header{"X-Guest-Pin": {"1234"}}, nil)
+	expect(t, res, http.StatusOK, "")
+
+	res = s.do(http.MethodPost, guest, "", gin.H{"pin": "1234"})
+	expect(t, res, http.StatusOK, "")
+
+	res = s.do(http.MethodGet, "/api/v1/properties/"+propertyID+"/guest-links/"+linkID+"/accesses", host, nil)
+	expect(t, res, http.StatusOK, "")
+
+	accesses, _ := res.Body["accesses"].([]any)
+	var reasons []string
+	for _, a := range accesses {
+		access, _ := a.(map[string]any)
+		reason, _ := access["reason"].(string)
+		reasons = append(reasons, reason)
+	}
+	// Du plus récent au plus ancien
+	want := []string{"", "", "invalid_pin", "pin_required"}
+	if len(reasons) != len(want) {
+		t.Fatalf("accès journalisés %q, attendu %q", reasons, want)
+	}
+	for i := range want {
+		if reasons[i] != want[i] {
+			t.Fatalf("accès journalisés %q, attendu %q", reasons, want)
+		}
+	}
+}
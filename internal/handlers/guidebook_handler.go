@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/guidebook"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type GuidebookHandler struct {
+	propertyRepo  repository.PropertyStore
+	guestLinkRepo repository.GuestLinkStore
+	authz         *rbac.Service
+}
+
+func NewGuidebookHandler(stores *repository.Stores, authz *rbac.Service) *GuidebookHandler {
+	return &GuidebookHandler{
+		propertyRepo:  stores.Properties,
+		guestLinkRepo: stores.GuestLinks,
+		authz:         authz,
+	}
+}
+
+// ExportGuidebook génère le livret d'accueil PDF d'une propriété. Le livret d'une propriété
+// publiée est public mais sans les codes d'accès, qui ne sont imprimés que pour l'équipe de la propriété.
+func (h *GuidebookHandler) ExportGuidebook(c *gin.Context) {
+	property, err := findProperty(c.Request.Context(), h.propertyRepo, c.Param("id"))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.PropertyNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	isTeam, err := propertyAllows(c, h.authz, property, models.PropertyActionRead)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if property.Status == 1 && !isTeam {
+		apierror.Abort(c, apierror.PropertyNotFound)
+		return
+	}
+
+	if !isTeam {
+		property.RedactSecrets()
+	}
+
+	writeGuidebook(c, property, isTeam)
+}
+
+// ExportGuestGuidebook génère le livret complet, codes d'accès compris, pour un voyageur
+// disposant d'un lien valide (PIN éventuel dans le header X-Guest-Pin ou, en POST, dans le corps)
+func (h *GuidebookHandler) ExportGuestGuidebook(c *gin.Context) {
+	_, property, ok := resolveGuestLink(c, h.guestLinkRepo, h.propertyRepo, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	writeGuidebook(c, property, true)
+}
+
+func writeGuidebook(c *gin.Context, property *models.Property, includeSecrets bool) {
+	var buf bytes.Buffer
+	err := guidebook.Render(&buf, property, guidebook.Options{
+		IncludeSecrets: includeSecrets,
+		GeneratedAt:    time.Now(),
+	})
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Un livret avec des codes d'accès ne doit pas rester dans un cache partagé
+	if includeSecrets {
+		c.Header("Cache-Control", "private, no-store")
+	}
+	c.Header("Content-Disposition", `inline; filename="livret-`+property.Slug+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,236 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/app"
+	"onestay-back/internal/config"
+	"onestay-back/internal/mailer"
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository"
+	"onestay-back/internal/repository/memory"
+	"onestay-back/internal/seed"
+	"onestay-back/internal/storage"
+	"onestay-back/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// testConfig retourne la configuration d'une application de test
+func testConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		JWTSecret:            "secret-de-test",
+		AccessTokenTTL:       15 * time.Minute,
+		RefreshTokenTTL:      24 * time.Hour,
+		EmailVerificationTTL: time.Hour,
+		PasswordResetTTL:     time.Hour,
+		InvitationTTL:        time.Hour,
+		FrontendURL:          "http://front.test",
+		StorageDriver:        "local",
+		MediaLocalDir:        t.TempDir(),
+		DefaultLocale:        "fr",
+		SupportedLocales:     []string{"fr", "en"},
+	}
+}
+
+// outbox capture les emails envoyés par les handlers
+type outbox struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (o *outbox) Send(ctx context.Context, msg mailer.Message) error {
+	o.mu.Lock()
+	o.messages = append(o.messages, msg)
+	o.mu.Unlock()
+	return nil
+}
+
+var tokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+// lastToken retourne le token du dernier lien envoyé à une adresse
+func (o *outbox) lastToken(t *testing.T, to string) string {
+	t.Helper()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].To != to {
+			continue
+		}
+		if match := tokenPattern.FindStringSubmatch(o.messages[i].Body); match != nil {
+			return match[1]
+		}
+	}
+	t.Fatalf("aucun lien envoyé à %s", to)
+	return ""
+}
+
+// testServer est une application complète montée sur des stores en mémoire
+type testServer struct {
+	t      *testing.T
+	stores *repository.Stores
+	mail   *outbox
+	engine *gin.Engine
+}
+
+// newTestServer construit une application isolée où seuls les rôles système existent.
+// Les fonctions wrap peuvent remplacer des stores avant la construction des handlers.
+func newTestServer(t *testing.T, wrap ...func(*repository.Stores)) *testServer {
+	t.Helper()
+
+	cfg := testConfig(t)
+	stores := memory.NewStores(nil)
+	if err := seed.SeedRoles(stores.Roles); err != nil {
+		t.Fatalf("création des rôles : %v", err)
+	}
+	for _, w := range wrap {
+		w(stores)
+	}
+
+	mail := &outbox{}
+	application := app.NewWithStores(cfg, stores, app.Options{
+		Mailer:  mail,
+		Storage: storage.NewLocalStorage(cfg.MediaLocalDir, ""),
+	})
+
+	return &testServer{t: t, stores: stores, mail: mail, engine: application.Router}
+}
+
+// response est une réponse enregistrée, décodée comme objet JSON
+type response struct {
+	Status int
+	Header http.Header
+	Body   map[string]any
+}
+
+// code retourne le code d'erreur de l'enveloppe apierror
+func (r response) code() apierror.Code {
+	code, _ := r.Body["code"].(string)
+	return apierror.Code(code)
+}
+
+// do envoie une requête JSON, authentifiée si token n'est pas vide
+func (s *testServer) do(method, path, token string, body any) response {
+	s.t.Helper()
+	return s.doWithHeader(method, path, token, nil, body)
+}
+
+// doWithHeader envoie une requête comme do, avec des en-têtes supplémentaires
+func (s *testServer) doWithHeader(method, path, token string, header http.Header, body any) response {
+	s.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			s.t.Fatalf("encodage de la requête : %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rec := httptest.NewRecorder()
+	s.engine.ServeHTTP(rec, req)
+
+	res := response{Status: rec.Code, Header: rec.Header(), Body: map[string]any{}}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &res.Body); err != nil {
+			s.t.Fatalf("%s %s : réponse illisible %q", method, path, rec.Body.String())
+		}
+	}
+	return res
+}
+
+// expect vérifie le statut d'une réponse et, pour une erreur, son code
+func expect(t *testing.T, res response, status int, code apierror.Code) {
+	t.Helper()
+
+	if res.Status != status {
+		t.Fatalf("statut %d, attendu %d (réponse %v)", res.Status, status, res.Body)
+	}
+	if code != "" && res.code() != code {
+		t.Fatalf("code %q, attendu %q", res.code(), code)
+	}
+}
+
+// createUser enregistre directement un utilisateur à l'email confirmé
+func (s *testServer) createUser(email, roleID string) *models.User {
+	s.t.Helper()
+
+	hashed, err := utils.HashPassword("motdepasse")
+	if err != nil {
+		s.t.Fatalf("hachage du mot de passe : %v", err)
+	}
+
+	user := &models.User{
+		Nom:      "Test",
+		Prenom:   strings.Split(email, "@")[0],
+		Email:    email,
+		Password: hashed,
+		RoleID:   roleID,
+	}
+	if err := s.stores.Users.Create(context.Background(), user); err != nil {
+		s.t.Fatalf("création de %s : %v", email, err)
+	}
+	return user
+}
+
+// login connecte un utilisateur et retourne son access token
+func (s *testServer) login(email string) string {
+	s.t.Helper()
+
+	res := s.do(http.MethodPost, "/api/v1/auth/login", "", gin.H{"email": email, "password": "motdepasse"})
+	expect(s.t, res, http.StatusOK, "")
+
+	token := strings.TrimPrefix(res.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		s.t.Fatal("aucun access token dans l'en-tête Authorization")
+	}
+	return token
+}
+
+// createProperty crée une propriété en brouillon et retourne son ID
+func (s *testServer) createProperty(token, name string) string {
+	s.t.Helper()
+
+	res := s.do(http.MethodPost, "/api/v1/properties", token, gin.H{
+		"name":    name,
+		"address": "1 rue de la Paix",
+		"city":    "Paris",
+		"country": "France",
+	})
+	expect(s.t, res, http.StatusCreated, "")
+
+	property, _ := res.Body["property"].(map[string]any)
+	id, _ := property["_id"].(string)
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		s.t.Fatalf("ID de propriété invalide %q", id)
+	}
+	return id
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout borne la durée de l'ensemble des vérifications de /readyz
+const readinessTimeout = 3 * time.Second
+
+// HealthCheck vérifie qu'une dépendance est disponible ; nil signifie qu'elle l'est
+type HealthCheck func(ctx context.Context) error
+
+// HealthHandler expose les sondes de l'orchestrateur et les informations de build
+type HealthHandler struct {
+	checks map[string]HealthCheck
+}
+
+// NewHealthHandler crée le handler ; chaque vérification est rapportée sous son nom par /readyz
+func NewHealthHandler(checks map[string]HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// Liveness indique que le processus répond, sans consulter ses dépendances
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness indique si l'API peut recevoir du trafic : toutes les vérifications doivent
+// réussir, sinon la réponse est 503 avec le résultat de chacune. Le détail d'un échec est
+// seulement journalisé : la sonde est publique.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	results := make(map[string]string, len(h.checks))
+	ready := true
+	for name, check := range h.checks {
+		if err := check(ctx); err != nil {
+			log.Printf("readiness %s: %v", name, err)
+			results[name] = "unavailable"
+			ready = false
+		} else {
+			results[name] = "ok"
+		}
+	}
+
+	if !ready {
+		apierror.AbortWithDetails(c, apierror.ServiceUnavailable, gin.H{"checks": results})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": results})
+}
+
+// Version retourne le commit et la date de build du binaire
+func (h *HealthHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get())
+}
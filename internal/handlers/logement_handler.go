@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"onestay-back/internal/apierror"
 	"onestay-back/internal/models"
 	"onestay-back/internal/repository"
 	"onestay-back/internal/utils"
@@ -13,12 +14,14 @@ import (
 )
 
 type LogementHandler struct {
-	logementRepo *repository.LogementRepository
+	tokens       *utils.TokenService
+	logementRepo repository.LogementStore
 }
 
-func NewLogementHandler() *LogementHandler {
+func NewLogementHandler(stores *repository.Stores, tokens *utils.TokenService) *LogementHandler {
 	return &LogementHandler{
-		logementRepo: repository.NewLogementRepository(),
+		tokens:       tokens,
+		logementRepo: stores.Logements,
 	}
 }
 
@@ -26,27 +29,20 @@ func (h *LogementHandler) CreateLogement(c *gin.Context) {
 	var req models.CreateLogementRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Données invalides",
-			"details": err.Error(),
-		})
+		apierror.AbortBinding(c, err)
 		return
 	}
 
 	// Récupérer l'ID de l'utilisateur depuis le contexte (défini par le middleware)
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Utilisateur non authentifié",
-		})
+		apierror.Abort(c, apierror.Unauthenticated)
 		return
 	}
 
 	userID, ok := userIDInterface.(primitive.ObjectID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération de l'ID utilisateur",
-		})
+		apierror.Internal(c, nil)
 		return
 	}
 
@@ -55,16 +51,12 @@ func (h *LogementHandler) CreateLogement(c *gin.Context) {
 	// Vérifier que le nom du bien n'est pas déjà utilisé par cet utilisateur
 	exists, err := h.logementRepo.ExistsByNomBienAndUserID(ctx, req.NomBien, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la vérification du nom du bien",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
 	if exists {
-		c.JSON(http.StatusConflict, gin.H{
-			"error": "Un logement avec ce nom existe déjà pour votre compte",
-		})
+		apierror.Abort(c, apierror.PropertyNameTaken)
 		return
 	}
 
@@ -80,9 +72,7 @@ func (h *LogementHandler) CreateLogement(c *gin.Context) {
 	}
 
 	if err := h.logementRepo.Create(ctx, logement); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la création du logement",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -106,18 +96,14 @@ func (h *LogementHandler) GetUserLogements(c *gin.Context) {
 	// Récupérer l'ID de l'utilisateur depuis l'URL
 	userIDParam := c.Param("id")
 	if userIDParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID utilisateur manquant",
-		})
+		apierror.Abort(c, apierror.MissingID)
 		return
 	}
 
 	// Convertir l'ID de l'URL en ObjectID
 	requestedUserID, err := primitive.ObjectIDFromHex(userIDParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID utilisateur invalide",
-		})
+		apierror.Abort(c, apierror.InvalidID)
 		return
 	}
 
@@ -159,7 +145,7 @@ func (h *LogementHandler) GetUserLogements(c *gin.Context) {
 		
 		// Si un token est présent, essayer de le valider
 		if tokenString != "" {
-			claims, err := utils.ValidateToken(tokenString)
+			claims, err := h.tokens.Validate(tokenString)
 			if err == nil {
 				tokenUserID = claims.UserID
 				isOwner = requestedUserID == tokenUserID
@@ -173,9 +159,7 @@ func (h *LogementHandler) GetUserLogements(c *gin.Context) {
 	// Récupérer les logements
 	logements, err := h.logementRepo.FindByUserID(ctx, requestedUserID, includeBrouillon)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération des logements",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -0,0 +1,712 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type OrganizationHandler struct {
+	organizationRepo repository.OrganizationStore
+	propertyRepo     repository.PropertyStore
+	userRepo         repository.UserStore
+	sessionRepo      repository.SessionStore
+	authz            *rbac.Service
+}
+
+func NewOrganizationHandler(stores *repository.Stores, authz *rbac.Service) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationRepo: stores.Organizations,
+		propertyRepo:     stores.Properties,
+		userRepo:         stores.Users,
+		sessionRepo:      stores.Sessions,
+		authz:            authz,
+	}
+}
+
+// CreateOrganization crée une organisation dont l'utilisateur connecté devient propriétaire
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req models.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return
+	}
+
+	ctx := c.Request.Context()
+	name := strings.TrimSpace(req.Name)
+
+	slug, err := uniqueSlug(ctx, h.organizationRepo, name, "")
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	organization := &models.Organization{
+		Name:      name,
+		Slug:      slug,
+		CreatedBy: userID,
+	}
+	if err := h.organizationRepo.Create(ctx, organization); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	owner := &models.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         userID,
+		Role:           models.OrganizationRoleOwner,
+	}
+	if err := h.organizationRepo.AddMember(ctx, owner); err != nil {
+		// Une organisation sans propriétaire serait inaccessible
+		if deleteErr := h.organizationRepo.Delete(ctx, organization.ID); deleteErr != nil {
+			log.Printf("Erreur lors de la suppression de l'organisation %s: %v", organization.ID.Hex(), deleteErr)
+		}
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Organisation créée avec succès",
+		"organization": organization,
+		"role":         owner.Role,
+	})
+}
+
+// GetOrganizations liste les organisations de l'utilisateur connecté et indique celle
+// pour laquelle agit sa session
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	memberships, err := h.organizationRepo.FindMembershipsByUserID(ctx, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	roles := make(map[primitive.ObjectID]string, len(memberships))
+	ids := make([]primitive.ObjectID, 0, len(memberships))
+	for _, membership := range memberships {
+		roles[membership.OrganizationID] = membership.Role
+		ids = append(ids, membership.OrganizationID)
+	}
+
+	organizations, err := h.organizationRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	result := make([]models.OrganizationMembership, 0, len(organizations))
+	for i := range organizations {
+		result = append(result, models.OrganizationMembership{
+			Organization: &organizations[i],
+			Role:         roles[organizations[i].ID],
+		})
+	}
+
+	response := gin.H{
+		"organizations":        result,
+		"count":                len(result),
+		"activeOrganizationId": nil,
+	}
+	if organizationID, ok := c.Get("organization_id"); ok {
+		response["activeOrganizationId"] = organizationID
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOrganization récupère une organisation dont l'utilisateur est membre
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	organization, role, ok := h.loadOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"organization": organization,
+		"role":         role,
+	})
+}
+
+// UpdateOrganization renomme une organisation ; le slug suit le nouveau nom
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	organization, _, ok := h.loadOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	name := strings.TrimSpace(req.Name)
+
+	slug, err := uniqueSlug(ctx, h.organizationRepo, name, organization.Slug)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.organizationRepo.Update(ctx, organization.ID, bson.M{"name": name, "slug": slug}); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	updatedOrganization, err := h.organizationRepo.FindByID(ctx, organization.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Organisation mise à jour avec succès",
+		"organization": updatedOrganization,
+	})
+}
+
+// DeleteOrganization supprime une organisation ; ses propriétés reviennent à leurs hôtes
+func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
+	organization, role, ok := h.loadOrganization(c, true)
+	if !ok {
+		return
+	}
+	if role != models.OrganizationRoleOwner {
+		apierror.Abort(c, apierror.OrganizationForbidden)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	detached, err := h.propertyRepo.DetachOrganization(ctx, organization.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.sessionRepo.ClearOrganization(ctx, organization.ID, nil); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.organizationRepo.Delete(ctx, organization.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Organisation supprimée avec succès",
+		"detached_properties": detached,
+	})
+}
+
+// GetMembers liste les membres d'une organisation
+func (h *OrganizationHandler) GetMembers(c *gin.Context) {
+	organization, _, ok := h.loadOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationRepo.FindMembers(c.Request.Context(), organization.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	views := make([]models.OrganizationMemberView, 0, len(members))
+	for _, member := range members {
+		views = append(views, h.memberView(c, &member))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"members": views,
+		"count":   len(views),
+	})
+}
+
+// AddMember ajoute un utilisateur inscrit à l'organisation. Seul un propriétaire peut
+// nommer un autre propriétaire.
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	organization, role, ok := h.loadOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	var req models.AddOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	if req.Role == models.OrganizationRoleOwner && role != models.OrganizationRoleOwner {
+		apierror.Abort(c, apierror.OrganizationForbidden)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	user, err := h.userRepo.FindByEmail(ctx, strings.TrimSpace(req.Email))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.UserNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+		Role:           req.Role,
+	}
+	if err := h.organizationRepo.AddMember(ctx, member); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			apierror.Abort(c, apierror.OrganizationMemberExists)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Membre ajouté à l'organisation",
+		"member":  h.memberView(c, member),
+	})
+}
+
+// UpdateMember change le rôle d'un membre. Seul un propriétaire peut nommer ou rétrograder
+// un propriétaire, et l'organisation en garde toujours au moins un.
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	organization, role, ok := h.loadOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	member, ok := h.loadMember(c, organization)
+	if !ok {
+		return
+	}
+
+	changesOwner := member.Role == models.OrganizationRoleOwner || req.Role == models.OrganizationRoleOwner
+	if changesOwner && role != models.OrganizationRoleOwner {
+		apierror.Abort(c, apierror.OrganizationForbidden)
+		return
+	}
+	if member.Role == models.OrganizationRoleOwner && req.Role != models.OrganizationRoleOwner {
+		if !h.ensureAnotherOwner(c, organization) {
+			return
+		}
+	}
+
+	if err := h.organizationRepo.UpdateMemberRole(c.Request.Context(), organization.ID, member.UserID, req.Role); err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.OrganizationMemberNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	member.Role = req.Role
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rôle mis à jour",
+		"member":  h.memberView(c, member),
+	})
+}
+
+// RemoveMember retire un membre de l'organisation. Un membre peut aussi la quitter lui-même,
+// sauf s'il en est le dernier propriétaire.
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	organization, role, ok := h.loadOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	member, ok := h.loadMember(c, organization)
+	if !ok {
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	if member.UserID != userID {
+		manager := models.OrganizationRoleCanManage(role)
+		if !manager || (member.Role == models.OrganizationRoleOwner && role != models.OrganizationRoleOwner) {
+			apierror.Abort(c, apierror.OrganizationForbidden)
+			return
+		}
+	}
+	if member.Role == models.OrganizationRoleOwner && !h.ensureAnotherOwner(c, organization) {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.organizationRepo.RemoveMember(ctx, organization.ID, member.UserID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.OrganizationMemberNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	// Les sessions qui agissaient pour l'organisation reviennent au compte personnel
+	if err := h.sessionRepo.ClearOrganization(ctx, organization.ID, &member.UserID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Membre retiré de l'organisation",
+	})
+}
+
+// GetProperties liste le portefeuille de l'organisation, brouillons compris
+func (h *OrganizationHandler) GetProperties(c *gin.Context) {
+	organization, _, ok := h.loadOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	properties, err := h.propertyRepo.FindByOrganizationID(c.Request.Context(), organization.ID, true)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"properties": properties,
+		"count":      len(properties),
+	})
+}
+
+// SwitchOrganization choisit l'organisation pour laquelle agit la session courante ;
+// un identifiant vide revient au compte personnel
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	// Le corps est facultatif : sans organisation, la session agit au nom de l'utilisateur
+	var req models.SwitchOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return
+	}
+
+	sessionIDInterface, exists := c.Get("session_id")
+	if !exists {
+		apierror.Abort(c, apierror.SessionInvalid)
+		return
+	}
+
+	sessionID, ok := sessionIDInterface.(primitive.ObjectID)
+	if !ok {
+		apierror.Internal(c, nil)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var organization *models.Organization
+	var organizationID *primitive.ObjectID
+	if req.OrganizationID != "" {
+		id, err := primitive.ObjectIDFromHex(req.OrganizationID)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidID)
+			return
+		}
+
+		role, err := h.authz.OrganizationRole(ctx, id, userID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		if role == "" {
+			apierror.Abort(c, apierror.OrganizationNotFound)
+			return
+		}
+
+		organization, err = h.organizationRepo.FindByID(ctx, id)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				apierror.Abort(c, apierror.OrganizationNotFound)
+			} else {
+				apierror.Internal(c, err)
+			}
+			return
+		}
+		organizationID = &organization.ID
+	}
+
+	if err := h.sessionRepo.SetOrganization(ctx, sessionID, organizationID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Organisation active mise à jour",
+		"organization": organization,
+	})
+}
+
+// AssignProperty confie une propriété à une organisation, ou la rend à son hôte.
+// Il faut gérer la propriété et, pour la confier, gérer l'organisation qui la reçoit.
+func (h *OrganizationHandler) AssignProperty(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionManage)
+	if !ok {
+		return
+	}
+
+	var req models.AssignPropertyOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var organizationID *primitive.ObjectID
+	if req.OrganizationID != "" {
+		id, err := primitive.ObjectIDFromHex(req.OrganizationID)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidID)
+			return
+		}
+
+		userID, _ := currentUserID(c)
+		role, err := h.authz.OrganizationRole(ctx, id, userID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		if role == "" {
+			apierror.Abort(c, apierror.OrganizationNotFound)
+			return
+		}
+		if !models.OrganizationRoleCanManage(role) {
+			apierror.Abort(c, apierror.OrganizationForbidden)
+			return
+		}
+		organizationID = &id
+	}
+
+	if err := h.propertyRepo.SetOrganization(ctx, property.ID, organizationID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	updatedProperty, err := h.propertyRepo.FindByID(ctx, property.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Organisation de la propriété mise à jour",
+		"property": updatedProperty,
+	})
+}
+
+// GetOrganizationStats liste toutes les organisations avec leurs nombres de membres et de
+// propriétés (administration)
+func (h *OrganizationHandler) GetOrganizationStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	organizations, err := h.organizationRepo.FindAll(ctx)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	memberCounts, err := h.organizationRepo.CountMembers(ctx)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	propertyCounts, err := h.propertyRepo.CountByOrganization(ctx)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	stats := make([]models.OrganizationStats, 0, len(organizations))
+	for i := range organizations {
+		stats = append(stats, models.OrganizationStats{
+			Organization: &organizations[i],
+			Members:      memberCounts[organizations[i].ID],
+			Properties:   propertyCounts[organizations[i].ID],
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"organizations": stats,
+		"count":         len(stats),
+	})
+}
+
+// loadOrganization charge l'organisation désignée par le paramètre :id avec le rôle de
+// l'utilisateur courant. Elle est introuvable pour les non-membres ; manage exige un rôle
+// de gestion. En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *OrganizationHandler) loadOrganization(c *gin.Context, manage bool) (*models.Organization, string, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return nil, "", false
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return nil, "", false
+	}
+
+	ctx := c.Request.Context()
+
+	role, err := h.authz.OrganizationRole(ctx, id, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return nil, "", false
+	}
+	if role == "" {
+		apierror.Abort(c, apierror.OrganizationNotFound)
+		return nil, "", false
+	}
+	if manage && !models.OrganizationRoleCanManage(role) {
+		apierror.Abort(c, apierror.OrganizationForbidden)
+		return nil, "", false
+	}
+
+	organization, err := h.organizationRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.OrganizationNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, "", false
+	}
+
+	return organization, role, true
+}
+
+// loadMember charge le membre désigné par le paramètre :userId.
+// En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *OrganizationHandler) loadMember(c *gin.Context, organization *models.Organization) (*models.OrganizationMember, bool) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return nil, false
+	}
+
+	member, err := h.organizationRepo.FindMember(c.Request.Context(), organization.ID, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.OrganizationMemberNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, false
+	}
+	return member, true
+}
+
+// ensureAnotherOwner vérifie qu'un propriétaire peut perdre son rôle sans laisser
+// l'organisation sans propriétaire. En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *OrganizationHandler) ensureAnotherOwner(c *gin.Context, organization *models.Organization) bool {
+	owners, err := h.organizationRepo.CountOwners(c.Request.Context(), organization.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	if owners <= 1 {
+		apierror.Abort(c, apierror.OrganizationLastOwner)
+		return false
+	}
+	return true
+}
+
+// memberView présente un membre avec son identité ; un compte supprimé entre-temps est
+// présenté sans nom
+func (h *OrganizationHandler) memberView(c *gin.Context, member *models.OrganizationMember) models.OrganizationMemberView {
+	view := models.OrganizationMemberView{UserID: member.UserID, Role: member.Role, CreatedAt: member.CreatedAt}
+
+	user, err := h.userRepo.FindByID(c.Request.Context(), member.UserID.Hex())
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Erreur lors de la récupération de l'utilisateur %s: %v", member.UserID.Hex(), err)
+		}
+		return view
+	}
+
+	view.Nom = user.Nom
+	view.Prenom = user.Prenom
+	view.Email = user.Email
+	return view
+}
+
+// actingOrganization retourne l'organisation pour laquelle agit la session, après avoir vérifié
+// que l'utilisateur peut encore y créer des propriétés ; nil s'il agit en son nom propre.
+// En cas d'échec, la réponse d'erreur est déjà écrite.
+func actingOrganization(c *gin.Context, authz *rbac.Service) (*primitive.ObjectID, bool) {
+	value, exists := c.Get("organization_id")
+	if !exists {
+		return nil, true
+	}
+
+	organizationID, ok := value.(primitive.ObjectID)
+	if !ok {
+		apierror.Internal(c, nil)
+		return nil, false
+	}
+
+	userID, _ := currentUserID(c)
+	role, err := authz.OrganizationRole(c.Request.Context(), organizationID, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return nil, false
+	}
+	if !models.PropertyRoleAllows(models.OrganizationPropertyRoles[role], models.PropertyActionEdit) {
+		apierror.Abort(c, apierror.OrganizationForbidden)
+		return nil, false
+	}
+
+	return &organizationID, true
+}
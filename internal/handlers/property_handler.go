@@ -1,26 +1,54 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
 
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
 	"onestay-back/internal/models"
+	"onestay-back/internal/publishing"
+	"onestay-back/internal/rbac"
 	"onestay-back/internal/repository"
+	"onestay-back/internal/storage"
 	"onestay-back/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type PropertyHandler struct {
-	propertyRepo *repository.PropertyRepository
+	cfg          *config.Config
+	propertyRepo repository.PropertyStore
+	revisionRepo repository.PropertyRevisionStore
+	draftRepo    repository.PropertyDraftStore
+	templateRepo repository.SectionTemplateLinkStore
+	memberRepo   repository.PropertyMemberStore
+	propertyData []repository.PropertyDataCleaner
+	publisher    *publishing.Publisher
+	storage      storage.Storage
+	authz        *rbac.Service
 }
 
-func NewPropertyHandler() *PropertyHandler {
+func NewPropertyHandler(cfg *config.Config, stores *repository.Stores, publisher *publishing.Publisher, files storage.Storage, authz *rbac.Service) *PropertyHandler {
 	return &PropertyHandler{
-		propertyRepo: repository.NewPropertyRepository(),
+		cfg:          cfg,
+		propertyRepo: stores.Properties,
+		revisionRepo: stores.PropertyRevisions,
+		draftRepo:    stores.PropertyDrafts,
+		templateRepo: stores.SectionTemplateLinks,
+		memberRepo:   stores.PropertyMembers,
+		propertyData: stores.PropertyData,
+		publisher:    publisher,
+		storage:      files,
+		authz:        authz,
 	}
 }
 
@@ -29,27 +57,26 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 	var req models.CreatePropertyRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Données invalides",
-			"details": err.Error(),
-		})
+		apierror.AbortBinding(c, err)
 		return
 	}
 
 	// Récupérer l'ID de l'utilisateur depuis le contexte (défini par le middleware)
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Utilisateur non authentifié",
-		})
+		apierror.Abort(c, apierror.Unauthenticated)
 		return
 	}
 
 	hostID, ok := userIDInterface.(primitive.ObjectID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération de l'ID utilisateur",
-		})
+		apierror.Internal(c, nil)
+		return
+	}
+
+	// Une session agissant pour une organisation lui confie la propriété créée
+	organizationID, ok := actingOrganization(c, h.authz)
+	if !ok {
 		return
 	}
 
@@ -58,51 +85,44 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 	// Vérifier que le nom n'est pas déjà utilisé par cet hôte
 	nameExists, err := h.propertyRepo.ExistsByNameAndHostID(ctx, req.Name, hostID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la vérification du nom",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 	if nameExists {
-		c.JSON(http.StatusConflict, gin.H{
-			"error": "Vous avez déjà un logement avec ce nom",
-		})
+		apierror.Abort(c, apierror.PropertyNameTaken)
 		return
 	}
 
 	// Générer le slug à partir du nom
-	baseSlug := utils.GenerateSlug(req.Name)
-	slug := baseSlug
+	slug, err := uniqueSlug(ctx, h.propertyRepo, req.Name, "")
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
 
-	// Vérifier l'unicité du slug et ajouter un suffixe si nécessaire
-	counter := 1
-	for {
-		exists, err := h.propertyRepo.ExistsBySlug(ctx, slug)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la vérification du slug",
-			})
+	if req.DefaultLocale != "" {
+		locale, ok := parseSupportedLocale(c, h.cfg, req.DefaultLocale)
+		if !ok {
 			return
 		}
-		if !exists {
-			break
-		}
-		slug = baseSlug + "-" + strconv.Itoa(counter)
-		counter++
+		req.DefaultLocale = locale
 	}
 
 	// Créer les sous-documents par défaut si non fournis
 	property := &models.Property{
-		HostID:      hostID,
-		Status:      1, // 1 = brouillon, 2 = publié
-		Slug:        slug,
-		Name:        req.Name,
-		Description: req.Description,
-		Address:     req.Address,
-		City:        req.City,
-		Country:     req.Country,
-		ZipCode:     req.ZipCode,
-		Images:      req.Images,
+		HostID:         hostID,
+		OrganizationID: organizationID,
+		Status:         1, // 1 = brouillon, 2 = publié
+		Slug:           slug,
+		Name:           req.Name,
+		Description:    req.Description,
+		Address:        req.Address,
+		City:           req.City,
+		Country:        req.Country,
+		ZipCode:        req.ZipCode,
+		Location:       req.Location,
+		Images:         req.Images,
+		DefaultLocale:  req.DefaultLocale,
 	}
 
 	// Initialiser les sous-documents avec des valeurs par défaut si non fournis
@@ -197,84 +217,114 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 		property.Emergency = &models.Emergency{Enabled: false}
 	}
 
+	if err := property.ValidateLocations(); err != nil {
+		apierror.Abort(c, apierror.InvalidCoordinates)
+		return
+	}
+	property.ComputeRecommendationDistances()
+
 	if err := h.propertyRepo.Create(ctx, property); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la création de la propriété",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
+	recordRevision(c, h.revisionRepo, nil, property, models.PropertyRevision{Action: models.RevisionActionCreate})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "Propriété créée avec succès",
 		"property": property,
 	})
 }
 
+// SearchProperties recherche les propriétés publiées (filtres, texte libre, tri, pagination par curseur)
+func (h *PropertyHandler) SearchProperties(c *gin.Context) {
+	var query models.PropertySearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	// Les catégories d'équipement sont acceptées répétées (?equipment=a&equipment=b) ou séparées par des virgules
+	var categories []string
+	for _, value := range query.EquipmentCategories {
+		for _, category := range strings.Split(value, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				categories = append(categories, category)
+			}
+		}
+	}
+	query.EquipmentCategories = categories
+	query.Text = strings.TrimSpace(query.Text)
+
+	if query.Near != "" {
+		point, err := models.ParseLatLng(query.Near)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidNear)
+			return
+		}
+		query.NearPoint = point
+
+		// Une recherche "autour de moi" est triée par distance par défaut
+		if query.Sort == "" && query.Text == "" {
+			query.Sort = models.PropertySortDistance
+		}
+	}
+
+	if query.Sort == models.PropertySortDistance {
+		if query.NearPoint == nil {
+			apierror.Abort(c, apierror.DistanceSortRequiresNear)
+			return
+		}
+		// $geoNear n'accepte pas de recherche plein texte
+		if query.Text != "" {
+			apierror.Abort(c, apierror.DistanceSortWithText)
+			return
+		}
+	}
+
+	page, err := h.propertyRepo.FindAll(c.Request.Context(), query)
+	if err != nil {
+		if err == repository.ErrInvalidCursor {
+			apierror.Abort(c, apierror.InvalidCursor)
+			return
+		}
+		apierror.Internal(c, err)
+		return
+	}
+
+	for i := range page.Properties {
+		page.Properties[i].RedactSecrets()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"properties": page.Properties,
+		"count":      len(page.Properties),
+		"total":      page.Total,
+		"nextCursor": page.NextCursor,
+	})
+}
+
 // GetUserProperties récupère les propriétés d'un utilisateur
 func (h *PropertyHandler) GetUserProperties(c *gin.Context) {
 	// Récupérer l'ID de l'utilisateur depuis l'URL
 	userIDParam := c.Param("id")
 	if userIDParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID utilisateur manquant",
-		})
+		apierror.Abort(c, apierror.MissingID)
 		return
 	}
 
 	// Convertir l'ID de l'URL en ObjectID
 	requestedUserID, err := primitive.ObjectIDFromHex(userIDParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ID utilisateur invalide",
-		})
+		apierror.Abort(c, apierror.InvalidID)
 		return
 	}
 
 	ctx := c.Request.Context()
 
-	// Essayer de récupérer l'ID de l'utilisateur depuis le token JWT (optionnel)
-	var tokenUserID primitive.ObjectID
-	var isOwner bool
-
-	// Vérifier si un token est présent dans le contexte (défini par le middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if exists {
-		if id, ok := userIDInterface.(primitive.ObjectID); ok {
-			tokenUserID = id
-			// Vérifier si c'est le propriétaire
-			isOwner = requestedUserID == tokenUserID
-		}
-	} else {
-		// Si pas dans le contexte, essayer d'extraire le token manuellement
-		var tokenString string
-
-		// Récupérer le header Authorization
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" {
-			authHeader = strings.TrimSpace(authHeader)
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
-				tokenString = strings.TrimSpace(parts[1])
-			}
-		}
-
-		// Si pas trouvé dans Authorization, vérifier le header "Bearer" directement
-		if tokenString == "" {
-			bearerHeader := c.GetHeader("Bearer")
-			if bearerHeader != "" {
-				tokenString = strings.TrimSpace(bearerHeader)
-			}
-		}
-
-		// Si un token est présent, essayer de le valider
-		if tokenString != "" {
-			claims, err := utils.ValidateToken(tokenString)
-			if err == nil {
-				tokenUserID = claims.UserID
-				isOwner = requestedUserID == tokenUserID
-			}
-		}
-	}
+	// Seul l'utilisateur authentifié par OptionalAuth (session active) est reconnu comme propriétaire
+	tokenUserID, authenticated := currentUserID(c)
+	isOwner := authenticated && requestedUserID == tokenUserID
 
 	// Déterminer si on doit inclure les brouillons (seulement si c'est le propriétaire)
 	includeDraft := isOwner
@@ -282,13 +332,17 @@ func (h *PropertyHandler) GetUserProperties(c *gin.Context) {
 	// Récupérer les propriétés
 	properties, err := h.propertyRepo.FindByHostID(ctx, requestedUserID, includeDraft)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Erreur lors de la récupération des propriétés",
-			"details": err.Error(),
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
+	// Les informations sensibles ne sont visibles que par le propriétaire
+	if !isOwner {
+		for i := range properties {
+			properties[i].RedactSecrets()
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"properties": properties,
 		"count":      len(properties),
@@ -299,115 +353,137 @@ func (h *PropertyHandler) GetUserProperties(c *gin.Context) {
 func (h *PropertyHandler) GetProperty(c *gin.Context) {
 	identifier := c.Param("id")
 	if identifier == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Identifiant manquant",
-		})
+		apierror.Abort(c, apierror.MissingID)
 		return
 	}
 
 	ctx := c.Request.Context()
 
-	var property *models.Property
-	var err error
-
-	// Essayer d'abord comme ObjectID
-	if id, err := primitive.ObjectIDFromHex(identifier); err == nil {
-		property, err = h.propertyRepo.FindByID(ctx, id)
-	} else {
-		// Sinon, traiter comme un slug
-		property, err = h.propertyRepo.FindBySlug(ctx, identifier)
-	}
-
+	// Essayer d'abord comme ObjectID, sinon traiter comme un slug
+	property, err := findProperty(ctx, h.propertyRepo, identifier)
 	if err != nil {
-		if err.Error() == "mongo: no documents in result" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Propriété introuvable",
-			})
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.PropertyNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la récupération de la propriété",
-			})
+			apierror.Internal(c, err)
 		}
 		return
 	}
 
-	// Si la propriété est en brouillon (status = 1), vérifier que l'utilisateur est le propriétaire
-	if property.Status == 1 {
-		userIDInterface, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Propriété introuvable",
-			})
-			return
-		}
+	// L'équipe de la propriété (hôte et collaborateurs) la consulte telle quelle
+	isTeam, err := propertyAllows(c, h.authz, property, models.PropertyActionRead)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
 
-		userID, ok := userIDInterface.(primitive.ObjectID)
-		if !ok || userID != property.HostID {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Propriété introuvable",
-			})
-			return
-		}
+	// Si la propriété est en brouillon (status = 1), vérifier que l'utilisateur fait partie de l'équipe
+	if property.Status == 1 && !isTeam {
+		apierror.Abort(c, apierror.PropertyNotFound)
+		return
+	}
+
+	// Les codes d'accès ne sont révélés qu'à l'équipe (ou via un lien voyageur)
+	if !isTeam {
+		property.RedactSecrets()
+	}
+
+	// L'équipe reçoit les contenus de base pour les modifier, sauf s'il demande une langue avec ?lang=
+	locale := property.ContentLocale(h.cfg.DefaultLocale)
+	if !isTeam || c.Query("lang") != "" {
+		locale = localizeProperty(c, h.cfg, property)
+	}
+	if !isTeam {
+		property.Translations = nil
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"property": property,
+		"locale":   locale,
 	})
 }
 
-// UpdateProperty met à jour une propriété
-func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
-	identifier := c.Param("id")
-	if identifier == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Identifiant manquant",
-		})
+// GetRecommendations retourne les recommandations locales d'une propriété triées par distance.
+// Par défaut les distances partent du logement ; ?near=latitude,longitude permet au voyageur
+// de les calculer depuis sa position. Filtre optionnel : ?category=.
+func (h *PropertyHandler) GetRecommendations(c *gin.Context) {
+	property, err := findProperty(c.Request.Context(), h.propertyRepo, c.Param("id"))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.PropertyNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
 		return
 	}
 
-	ctx := c.Request.Context()
-
-	// Trouver la propriété
-	var property *models.Property
-	var err error
-
-	if id, err := primitive.ObjectIDFromHex(identifier); err == nil {
-		property, err = h.propertyRepo.FindByID(ctx, id)
-	} else {
-		property, err = h.propertyRepo.FindBySlug(ctx, identifier)
+	if !ensureVisible(c, h.authz, property) {
+		return
 	}
 
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Propriété introuvable",
-		})
-		return
+	origin := property.Location
+	if near := c.Query("near"); near != "" {
+		point, err := models.ParseLatLng(near)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidNear)
+			return
+		}
+		origin = point
 	}
 
-	// Vérifier que l'utilisateur est le propriétaire
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Utilisateur non authentifié",
-		})
-		return
+	recommendations := []models.Recommendation{}
+	if property.LocalRecommendations != nil && property.LocalRecommendations.Enabled {
+		category := c.Query("category")
+		for _, rec := range property.LocalRecommendations.Recommendations {
+			if category != "" && rec.Category != category {
+				continue
+			}
+			if origin != nil && rec.Location != nil {
+				distance := math.Round(origin.DistanceTo(rec.Location))
+				rec.DistanceMeters = &distance
+				rec.Distance = models.FormatDistance(distance)
+			}
+			recommendations = append(recommendations, rec)
+		}
 	}
 
-	userID, ok := userIDInterface.(primitive.ObjectID)
-	if !ok || userID != property.HostID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Vous n'êtes pas autorisé à modifier cette propriété",
-		})
+	// Les recommandations sans coordonnées sont placées en fin de liste, dans l'ordre saisi
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		a, b := recommendations[i].DistanceMeters, recommendations[j].DistanceMeters
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a < *b
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"recommendations": recommendations,
+		"count":           len(recommendations),
+		"origin":          origin,
+	})
+}
+
+// UpdateProperty met à jour une propriété
+func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
 		return
 	}
 
+	ctx := c.Request.Context()
+	userID, _ := currentUserID(c)
+
 	// Lire les données de mise à jour
 	var req models.UpdatePropertyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Données invalides",
-			"details": err.Error(),
-		})
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	// Une propriété publiée n'est pas modifiée en direct : les modifications s'appliquent
+	// à son brouillon, qui part du contenu en ligne
+	current, ok := workingCopy(c, h.draftRepo, property)
+	if !ok {
 		return
 	}
 
@@ -416,19 +492,10 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 	if req.Name != "" {
 		updates["name"] = req.Name
 		// Régénérer le slug si le nom change
-		baseSlug := utils.GenerateSlug(req.Name)
-		slug := baseSlug
-		counter := 1
-		for {
-			exists, err := h.propertyRepo.ExistsBySlug(ctx, slug)
-			if err == nil && !exists {
-				break
-			}
-			if slug == property.Slug {
-				break // Garder le slug actuel s'il est toujours valide
-			}
-			slug = baseSlug + "-" + strconv.Itoa(counter)
-			counter++
+		slug, err := uniqueSlug(ctx, h.propertyRepo, req.Name, current.Slug)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
 		}
 		updates["slug"] = slug
 	}
@@ -450,6 +517,18 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 	if req.Images != nil {
 		updates["images"] = req.Images
 	}
+	if req.DefaultLocale != "" {
+		locale, ok := parseSupportedLocale(c, h.cfg, req.DefaultLocale)
+		if !ok {
+			return
+		}
+		// Les contenus de base ne peuvent pas être dans une langue déjà traduite
+		if property.Translation(locale) != nil {
+			apierror.Abort(c, apierror.TranslationLocaleExists)
+			return
+		}
+		updates["defaultLocale"] = locale
+	}
 
 	// Mettre à jour les sous-documents si fournis
 	if req.CheckInOut != nil {
@@ -504,6 +583,38 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 		updates["emergency"] = req.Emergency
 	}
 
+	// Une section modifiée directement ne suit plus son modèle
+	var edited []string
+	for _, section := range models.TemplateSections {
+		if _, ok := updates[section]; ok {
+			edited = append(edited, section)
+		}
+	}
+
+	// Les distances des recommandations dépendent de la position de la propriété :
+	// elles sont recalculées dès que l'une ou l'autre change
+	if req.Location != nil || req.LocalRecommendations != nil {
+		merged := *current
+		if req.Location != nil {
+			merged.Location = req.Location
+		}
+		if req.LocalRecommendations != nil {
+			merged.LocalRecommendations = req.LocalRecommendations
+		}
+		if err := merged.ValidateLocations(); err != nil {
+			apierror.Abort(c, apierror.InvalidCoordinates)
+			return
+		}
+		merged.ComputeRecommendationDistances()
+
+		if req.Location != nil {
+			updates["location"] = merged.Location
+		}
+		if merged.LocalRecommendations != nil {
+			updates["localRecommendations"] = merged.LocalRecommendations
+		}
+	}
+
 	if len(updates) == 0 {
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Aucune modification à appliquer",
@@ -511,159 +622,359 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 		return
 	}
 
+	if err := h.templateRepo.UnlinkSections(ctx, property.ID, edited); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if property.IsPublished() {
+		if err := h.draftRepo.Save(ctx, property, updates, &userID); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+
+		draft, err := h.draftRepo.FindByPropertyID(ctx, property.ID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "Modifications enregistrées : elles seront visibles après publication",
+			"property":       property.ApplyDraft(draft.Content),
+			"pendingChanges": true,
+		})
+		return
+	}
+
 	// Appliquer les mises à jour
 	if err := h.propertyRepo.Update(ctx, property.ID, updates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la mise à jour de la propriété",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Récupérer la propriété mise à jour
 	updatedProperty, err := h.propertyRepo.FindByID(ctx, property.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération de la propriété mise à jour",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
+	recordRevision(c, h.revisionRepo, property, updatedProperty, models.PropertyRevision{Action: models.RevisionActionUpdate})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Propriété mise à jour avec succès",
 		"property": updatedProperty,
 	})
 }
 
-// PublishProperty publie une propriété (change le status de 1 à 2)
+// PublishProperty publie une propriété (change le status de 1 à 2) et met en ligne les
+// modifications en attente. Avec {"publishAt": ...}, la publication est programmée.
 func (h *PropertyHandler) PublishProperty(c *gin.Context) {
-	identifier := c.Param("id")
-	if identifier == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Identifiant manquant",
-		})
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionPublish)
+	if !ok {
 		return
 	}
 
-	ctx := c.Request.Context()
+	// Le corps est facultatif : sans date, la publication est immédiate
+	var req models.PublishPropertyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.AbortBinding(c, err)
+		return
+	}
 
-	// Trouver la propriété
-	var property *models.Property
-	var err error
+	if req.PublishAt != nil {
+		h.schedulePublication(c, property, *req.PublishAt)
+		return
+	}
 
-	if id, err := primitive.ObjectIDFromHex(identifier); err == nil {
-		property, err = h.propertyRepo.FindByID(ctx, id)
-	} else {
-		property, err = h.propertyRepo.FindBySlug(ctx, identifier)
+	// Passer le status à 2 (publié) et mettre en ligne les modifications en attente,
+	// si les règles de publication sont respectées
+	updatedProperty, ok := h.publish(c, property)
+	if !ok {
+		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Propriété publiée avec succès",
+		"property": updatedProperty,
+	})
+}
+
+// DeleteProperty supprime une propriété
+func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
+	property, err := findProperty(c.Request.Context(), h.propertyRepo, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Propriété introuvable",
-		})
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.PropertyNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
 		return
 	}
 
-	// Vérifier que l'utilisateur est le propriétaire
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Utilisateur non authentifié",
-		})
+	// Seul l'hôte propriétaire (ou un modérateur) peut supprimer la propriété
+	allowed, err := propertyAllows(c, h.authz, property, models.PropertyActionManage)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !allowed && !canModerateProperties(c, h.authz) {
+		apierror.Abort(c, apierror.PropertyForbidden)
 		return
 	}
 
-	userID, ok := userIDInterface.(primitive.ObjectID)
-	if !ok || userID != property.HostID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Vous n'êtes pas autorisé à publier cette propriété",
-		})
+	ctx := c.Request.Context()
+
+	// Supprimer la propriété
+	if err := h.propertyRepo.Delete(ctx, property.ID); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
-	// Mettre à jour le status (2 = publié)
-	now := time.Now()
-	updates := map[string]interface{}{
-		"status":      2,
-		"publishedAt": now,
+	// Supprimer les liens voyageurs, réservations et calendriers devenus inutiles
+	for _, data := range h.propertyData {
+		if err := data.DeleteByPropertyID(ctx, property.ID); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
 	}
 
-	if err := h.propertyRepo.Update(ctx, property.ID, updates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la publication de la propriété",
-		})
+	if err := h.revisionRepo.DeleteByPropertyID(ctx, property.ID); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
-	// Récupérer la propriété mise à jour
-	updatedProperty, err := h.propertyRepo.FindByID(ctx, property.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la récupération de la propriété mise à jour",
-		})
+	if err := h.draftRepo.DeleteByPropertyID(ctx, property.ID); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
+	if err := h.templateRepo.UnlinkProperty(ctx, property.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.memberRepo.DeleteByPropertyID(ctx, property.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	for _, photo := range property.Photos {
+		deleteStoredFiles(h.storage, photo.Keys())
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":  "Propriété publiée avec succès",
-		"property": updatedProperty,
+		"message": "Propriété supprimée avec succès",
 	})
 }
 
-// DeleteProperty supprime une propriété
-func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
-	identifier := c.Param("id")
-	if identifier == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Identifiant manquant",
-		})
+// DuplicateProperty crée un brouillon à partir d'une propriété. Le contenu (modifications en
+// attente comprises), les traductions et les liens vers les modèles de section sont repris ;
+// les photos envoyées, propres à chaque logement, ne le sont pas.
+func (h *PropertyHandler) DuplicateProperty(c *gin.Context) {
+	source, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionManage)
+	if !ok {
 		return
 	}
 
-	ctx := c.Request.Context()
+	// Le corps est facultatif : sans nom, la copie reprend celui de la propriété
+	var req models.DuplicatePropertyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.AbortBinding(c, err)
+		return
+	}
 
-	// Trouver la propriété
-	var property *models.Property
-	var err error
+	current, ok := workingCopy(c, h.draftRepo, source)
+	if !ok {
+		return
+	}
 
-	if id, err := primitive.ObjectIDFromHex(identifier); err == nil {
-		property, err = h.propertyRepo.FindByID(ctx, id)
+	ctx := c.Request.Context()
+
+	name := strings.TrimSpace(req.Name)
+	if name != "" {
+		exists, err := h.propertyRepo.ExistsByNameAndHostID(ctx, name, source.HostID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		if exists {
+			apierror.Abort(c, apierror.PropertyNameTaken)
+			return
+		}
 	} else {
-		property, err = h.propertyRepo.FindBySlug(ctx, identifier)
+		var err error
+		name, err = copyName(ctx, h.propertyRepo, current.Name, source.HostID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
 	}
 
+	slug, err := uniqueSlug(ctx, h.propertyRepo, name, "")
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Propriété introuvable",
-		})
+		apierror.Internal(c, err)
 		return
 	}
 
-	// Vérifier que l'utilisateur est le propriétaire
+	property := *current
+	property.Status = models.PropertyStatusDraft
+	property.Slug = slug
+	property.Name = name
+	property.Photos = nil
+	property.DistanceMeters = nil
+	property.PublishedAt = nil
+	property.ScheduledPublishAt = nil
+
+	if err := h.propertyRepo.Create(ctx, &property); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.templateRepo.CopyLinks(ctx, source.ID, property.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	recordRevision(c, h.revisionRepo, nil, &property, models.PropertyRevision{Action: models.RevisionActionDuplicate})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Propriété dupliquée avec succès",
+		"property": &property,
+		"sourceId": source.ID,
+	})
+}
+
+// canModerateProperties indique si l'utilisateur courant peut agir sur les logements des autres hôtes
+func canModerateProperties(c *gin.Context, authz *rbac.Service) bool {
+	roleID, ok := c.Get("role_id")
+	if !ok {
+		return false
+	}
+	roleIDStr, ok := roleID.(string)
+	if !ok {
+		return false
+	}
+
+	allowed, err := authz.HasPermissions(c.Request.Context(), roleIDStr, models.PermissionPropertiesModerate)
+	return err == nil && allowed
+}
+
+// currentUserID retourne l'ID de l'utilisateur authentifié, s'il y en a un
+func currentUserID(c *gin.Context) (primitive.ObjectID, bool) {
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Utilisateur non authentifié",
-		})
-		return
+		return primitive.NilObjectID, false
 	}
 
 	userID, ok := userIDInterface.(primitive.ObjectID)
-	if !ok || userID != property.HostID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Vous n'êtes pas autorisé à supprimer cette propriété",
-		})
-		return
+	return userID, ok
+}
+
+// findProperty trouve une propriété par son ID ou, à défaut, par son slug
+func findProperty(ctx context.Context, repo repository.PropertyStore, identifier string) (*models.Property, error) {
+	if id, err := primitive.ObjectIDFromHex(identifier); err == nil {
+		return repo.FindByID(ctx, id)
 	}
+	return repo.FindBySlug(ctx, identifier)
+}
 
-	// Supprimer la propriété
-	if err := h.propertyRepo.Delete(ctx, property.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Erreur lors de la suppression de la propriété",
-		})
-		return
+// loadAuthorizedProperty charge la propriété désignée par le paramètre :id et vérifie que
+// l'utilisateur courant peut y effectuer l'action. En cas d'échec, la réponse d'erreur est déjà écrite.
+func loadAuthorizedProperty(c *gin.Context, authz *rbac.Service, repo repository.PropertyStore, action models.PropertyAction) (*models.Property, bool) {
+	property, err := findProperty(c.Request.Context(), repo, c.Param("id"))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.PropertyNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, false
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Propriété supprimée avec succès",
-	})
+	if !authorizeProperty(c, authz, property, action) {
+		return nil, false
+	}
+	return property, true
+}
+
+// authorizeProperty vérifie que l'utilisateur courant peut effectuer l'action sur la propriété.
+// En cas d'échec, la réponse d'erreur est déjà écrite.
+func authorizeProperty(c *gin.Context, authz *rbac.Service, property *models.Property, action models.PropertyAction) bool {
+	allowed, err := propertyAllows(c, authz, property, action)
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	if !allowed {
+		apierror.Abort(c, apierror.PropertyForbidden)
+		return false
+	}
+	return true
+}
+
+// ensureVisible vérifie qu'une propriété en brouillon n'est consultée que par son équipe ;
+// elle est introuvable pour les autres. En cas d'échec, la réponse d'erreur est déjà écrite.
+func ensureVisible(c *gin.Context, authz *rbac.Service, property *models.Property) bool {
+	if property.IsPublished() {
+		return true
+	}
+
+	allowed, err := propertyAllows(c, authz, property, models.PropertyActionRead)
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	if !allowed {
+		apierror.Abort(c, apierror.PropertyNotFound)
+		return false
+	}
+	return true
+}
+
+// propertyAllows indique si l'utilisateur courant, éventuellement anonyme, peut effectuer
+// l'action sur la propriété selon son rôle (hôte propriétaire ou collaborateur)
+func propertyAllows(c *gin.Context, authz *rbac.Service, property *models.Property, action models.PropertyAction) (bool, error) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return false, nil
+	}
+	return authz.CanAccessProperty(c.Request.Context(), property, userID, action)
+}
+
+// slugRepository vérifie l'unicité d'un slug (propriétés, organisations)
+type slugRepository interface {
+	ExistsBySlug(ctx context.Context, slug string) (bool, error)
+}
+
+// uniqueSlug génère le slug d'un nom et ajoute un suffixe numérique tant qu'il est déjà utilisé.
+// current est le slug actuel de la propriété, conservé s'il correspond toujours au nom.
+func uniqueSlug(ctx context.Context, repo slugRepository, name, current string) (string, error) {
+	baseSlug := utils.GenerateSlug(name)
+	slug := baseSlug
+	counter := 1
+	for {
+		exists, err := repo.ExistsBySlug(ctx, slug)
+		if err != nil {
+			return "", err
+		}
+		if !exists || slug == current {
+			return slug, nil
+		}
+		slug = baseSlug + "-" + strconv.Itoa(counter)
+		counter++
+	}
+}
+
+// copyName retourne le premier nom de copie libre pour l'hôte : "Nom (copie)", "Nom (copie 2)"...
+func copyName(ctx context.Context, repo repository.PropertyStore, name string, hostID primitive.ObjectID) (string, error) {
+	candidate := name + " (copie)"
+	for counter := 2; ; counter++ {
+		exists, err := repo.ExistsByNameAndHostID(ctx, candidate, hostID)
+		if err != nil || !exists {
+			return candidate, err
+		}
+		candidate = name + " (copie " + strconv.Itoa(counter) + ")"
+	}
 }
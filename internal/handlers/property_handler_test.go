@@ -0,0 +1,190 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDraftPropertyVisibleToTeamOnly(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("hote@example.com", "2")
+	s.createUser("autre@example.com", "2")
+
+	host := s.login("hote@example.com")
+	other := s.login("autre@example.com")
+	id := s.createProperty(host, "Chalet des Alpes")
+	path := "/api/v1/properties/" + id
+
+	expect(t, s.do(http.MethodGet, path, host, nil), http.StatusOK, "")
+	expect(t, s.do(http.MethodGet, path, other, nil), http.StatusNotFound, apierror.PropertyNotFound)
+	expect(t, s.do(http.MethodGet, path, "", nil), http.StatusNotFound, apierror.PropertyNotFound)
+
+	// Une fois publiée, la propriété est visible de tous, y compris par son slug
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	if err := s.stores.Properties.Update(context.Background(), objectID, bson.M{"status": models.PropertyStatusPublished}); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, s.do(http.MethodGet, path, "", nil), http.StatusOK, "")
+	expect(t, s.do(http.MethodGet, "/api/v1/properties/chalet-des-alpes", other, nil), http.StatusOK, "")
+}
+
+func TestCreatePropertyRejectsDuplicateName(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("hote@example.com", "2")
+	s.createUser("autre@example.com", "2")
+
+	host := s.login("hote@example.com")
+	s.createProperty(host, "Villa Azur")
+
+	res := s.do(http.MethodPost, "/api/v1/properties", host, gin.H{
+		"name":    "Villa Azur",
+		"address": "2 rue de la Paix",
+		"city":    "Nice",
+		"country": "France",
+	})
+	expect(t, res, http.StatusConflict, apierror.PropertyNameTaken)
+
+	// Un autre hôte peut reprendre le nom ; le slug reçoit un suffixe
+	res = s.do(http.MethodPost, "/api/v1/properties", s.login("autre@example.com"), gin.H{
+		"name":    "Villa Azur",
+		"address": "3 rue de la Paix",
+		"city":    "Nice",
+		"country": "France",
+	})
+	expect(t, res, http.StatusCreated, "")
+	property, _ := res.Body["property"].(map[string]any)
+	if property["slug"] == "villa-azur" {
+		t.Errorf("slug %v déjà utilisé", property["slug"])
+	}
+}
+
+func TestPropertyOwnershipRules(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("hote@example.com", "2")
+	s.createUser("autre@example.com", "2")
+	editorUser := s.createUser("editeur@example.com", "2")
+
+	host := s.login("hote@example.com")
+	other := s.login("autre@example.com")
+	editor := s.login("editeur@example.com")
+	id := s.createProperty(host, "Maison du Lac")
+	path := "/api/v1/properties/" + id
+
+	update := gin.H{"description": "Au bord de l'eau"}
+	expect(t, s.do(http.MethodPut, path, other, update), http.StatusForbidden, apierror.PropertyForbidden)
+	expect(t, s.do(http.MethodDelete, path, other, nil), http.StatusForbidden, apierror.PropertyForbidden)
+
+	// Un éditeur modifie le contenu mais ne peut pas supprimer la propriété
+	propertyID, _ := primitive.ObjectIDFromHex(id)
+	member := &models.PropertyMember{PropertyID: propertyID, UserID: editorUser.ID, Role: models.PropertyRoleEditor}
+	if err := s.stores.PropertyMembers.Create(context.Background(), member); err != nil {
+		t.Fatal(err)
+	}
+
+	expect(t, s.do(http.MethodPut, path, editor, update), http.StatusOK, "")
+	expect(t, s.do(http.MethodDelete, path, editor, nil), http.StatusForbidden, apierror.PropertyForbidden)
+
+	res := s.do(http.MethodGet, path, host, nil)
+	expect(t, res, http.StatusOK, "")
+	property, _ := res.Body["property"].(map[string]any)
+	if property["description"] != "Au bord de l'eau" {
+		t.Errorf("description %v, la modification de l'éditeur est perdue", property["description"])
+	}
+
+	expect(t, s.do(http.MethodDelete, path, host, nil), http.StatusOK, "")
+	expect(t, s.do(http.MethodGet, path, host, nil), http.StatusNotFound, apierror.PropertyNotFound)
+
+	// La suppression retire aussi les collaborateurs
+	members, err := s.stores.PropertyMembers.FindByPropertyID(context.Background(), propertyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 0 {
+		t.Errorf("%d collaborateurs restants après suppression", len(members))
+	}
+}
+
+func TestModeratorCanDeleteAnyProperty(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("hote@example.com", "2")
+	s.createUser("admin@example.com", "3")
+
+	id := s.createProperty(s.login("hote@example.com"), "Studio Montmartre")
+	admin := s.login("admin@example.com")
+
+	// La modération permet de supprimer, pas de modifier
+	expect(t, s.do(http.MethodPut, "/api/v1/properties/"+id, admin, gin.H{"name": "Renommé"}), http.StatusForbidden, apierror.PropertyForbidden)
+	expect(t, s.do(http.MethodDelete, "/api/v1/properties/"+id, admin, nil), http.StatusOK, "")
+}
+
+func TestUserPropertiesIgnoreClosedSessions(t *testing.T) {
+	s := newTestServer(t)
+	hostUser := s.createUser("hote@example.com", "2")
+
+	host := s.login("hote@example.com")
+	s.createProperty(host, "Chalet des Alpes")
+	path := "/api/v1/properties/user/" + hostUser.ID.Hex()
+
+	res := s.do(http.MethodGet, path, host, nil)
+	expect(t, res, http.StatusOK, "")
+	if res.Body["count"] != float64(1) {
+		t.Fatalf("%v propriétés pour l'hôte connecté, attendu 1", res.Body["count"])
+	}
+
+	// Le token d'une session fermée ne donne plus accès aux brouillons
+	expect(t, s.do(http.MethodPost, "/api/v1/auth/logout", host, nil), http.StatusOK, "")
+	res = s.do(http.MethodGet, path, host, nil)
+	expect(t, res, http.StatusOK, "")
+	if res.Body["count"] != float64(0) {
+		t.Errorf("%v propriétés avec une session fermée, attendu 0", res.Body["count"])
+	}
+}
+
+func TestUserPropertiesRedactSecretsForClosedSessions(t *testing.T) {
+	s := newTestServer(t)
+	hostUser := s.createUser("hote@example.com", "2")
+
+	host := s.login("hote@example.com")
+	id, _ := primitive.ObjectIDFromHex(s.createProperty(host, "Chalet des Alpes"))
+	if err := s.stores.Properties.Update(context.Background(), id, bson.M{
+		"status": models.PropertyStatusPublished,
+		"wifi":   &models.Wifi{Enabled: true, NetworkName: "Chalet", Password: "motdepasse-wifi"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/v1/properties/user/" + hostUser.ID.Hex()
+
+	wifiPassword := func(res response) string {
+		t.Helper()
+		properties, _ := res.Body["properties"].([]any)
+		if len(properties) != 1 {
+			t.Fatalf("%d propriétés, attendu 1", len(properties))
+		}
+		property, _ := properties[0].(map[string]any)
+		wifi, _ := property["wifi"].(map[string]any)
+		password, _ := wifi["password"].(string)
+		return password
+	}
+
+	res := s.do(http.MethodGet, path, host, nil)
+	expect(t, res, http.StatusOK, "")
+	if got := wifiPassword(res); got != "motdepasse-wifi" {
+		t.Fatalf("mot de passe Wi-Fi %q pour l'hôte connecté", got)
+	}
+
+	// Une session fermée est traitée comme un visiteur anonyme : les secrets sont masqués
+	expect(t, s.do(http.MethodPost, "/api/v1/auth/logout", host, nil), http.StatusOK, "")
+	res = s.do(http.MethodGet, path, host, nil)
+	expect(t, res, http.StatusOK, "")
+	if got := wifiPassword(res); got != "" {
+		t.Errorf("mot de passe Wi-Fi %q exposé à une session fermée", got)
+	}
+}
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
+	"onestay-back/internal/media"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+	"onestay-back/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PropertyImageHandler struct {
+	cfg          *config.Config
+	propertyRepo repository.PropertyStore
+	revisionRepo repository.PropertyRevisionStore
+	storage      storage.Storage
+	authz        *rbac.Service
+}
+
+func NewPropertyImageHandler(cfg *config.Config, stores *repository.Stores, files storage.Storage, authz *rbac.Service) *PropertyImageHandler {
+	return &PropertyImageHandler{
+		cfg:          cfg,
+		propertyRepo: stores.Properties,
+		revisionRepo: stores.PropertyRevisions,
+		storage:      files,
+		authz:        authz,
+	}
+}
+
+const maxImageCaptionLength = 300
+
+// UploadImage reçoit une photo (multipart, champ "file", légende optionnelle "caption").
+// Le type réel est vérifié sur le contenu, les métadonnées EXIF sont supprimées et les
+// déclinaisons large, medium et thumbnail sont enregistrées dans le stockage des médias.
+func (h *PropertyImageHandler) UploadImage(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
+		return
+	}
+
+	// Refus anticipé avant le traitement de l'image ; la limite est garantie par AddPhoto
+	if len(property.Photos) >= models.MaxPropertyImages {
+		apierror.Abort(c, apierror.PhotoLimitReached, models.MaxPropertyImages)
+		return
+	}
+
+	maxSize := h.cfg.MaxImageUploadSize
+
+	// Marge de 1 Mo pour l'enveloppe multipart et la légende
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			apierror.Abort(c, apierror.FileTooLarge, maxSize>>20)
+		} else {
+			apierror.Abort(c, apierror.FileMissing)
+		}
+		return
+	}
+	if fileHeader.Size > maxSize {
+		apierror.Abort(c, apierror.FileTooLarge, maxSize>>20)
+		return
+	}
+
+	caption := strings.TrimSpace(c.PostForm("caption"))
+	if utf8.RuneCountInString(caption) > maxImageCaptionLength {
+		apierror.Abort(c, apierror.CaptionTooLong, maxImageCaptionLength)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		apierror.Abort(c, apierror.FileUnreadable)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		apierror.Abort(c, apierror.FileUnreadable)
+		return
+	}
+	if int64(len(data)) > maxSize {
+		apierror.Abort(c, apierror.FileTooLarge, maxSize>>20)
+		return
+	}
+
+	contentType, err := media.Sniff(data)
+	if err != nil {
+		apierror.Abort(c, apierror.UnsupportedMediaType)
+		return
+	}
+
+	outputs, err := media.Process(data)
+	switch {
+	case errors.Is(err, media.ErrTooManyPixels):
+		apierror.Abort(c, apierror.ImageTooLarge, media.MaxPixels/1_000_000)
+		return
+	case errors.Is(err, media.ErrInvalidImage):
+		apierror.Abort(c, apierror.ImageInvalid)
+		return
+	case err != nil:
+		apierror.Internal(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// La couverture est choisie par AddPhoto, sur la liste à jour
+	photo := models.PropertyImage{
+		ID:          primitive.NewObjectID(),
+		Caption:     caption,
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+	}
+
+	var stored []string
+	for _, output := range outputs {
+		key := fmt.Sprintf("properties/%s/%s/%s%s", property.ID.Hex(), photo.ID.Hex(), output.Variant, output.Extension)
+		if err := h.storage.Put(ctx, key, output.Data, output.ContentType); err != nil {
+			deleteStoredFiles(h.storage, stored)
+			apierror.Internal(c, err)
+			return
+		}
+		stored = append(stored, key)
+
+		variant := models.ImageVariant{
+			Key:    key,
+			URL:    h.storage.URL(key),
+			Width:  output.Width,
+			Height: output.Height,
+		}
+		switch output.Variant {
+		case "large":
+			photo.Large = variant
+		case "medium":
+			photo.Medium = variant
+		case "thumbnail":
+			photo.Thumbnail = variant
+		}
+	}
+
+	if err := h.propertyRepo.AddPhoto(ctx, property.ID, photo); err != nil {
+		deleteStoredFiles(h.storage, stored)
+		if errors.Is(err, repository.ErrTooManyPhotos) {
+			apierror.Abort(c, apierror.PhotoLimitReached, models.MaxPropertyImages)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	updated, err := h.propertyRepo.FindByID(ctx, property.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if index := slices.IndexFunc(updated.Photos, func(p models.PropertyImage) bool { return p.ID == photo.ID }); index >= 0 {
+		photo = updated.Photos[index]
+	}
+
+	h.recordPhotos(c, property, updated.Photos)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Photo ajoutée avec succès",
+		"image":   photo,
+	})
+}
+
+// ReorderImages applique un nouvel ordre d'affichage ; order doit contenir tous les IDs des photos
+func (h *PropertyImageHandler) ReorderImages(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
+		return
+	}
+
+	var req models.ReorderPropertyImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	if len(req.Order) != len(property.Photos) {
+		apierror.Abort(c, apierror.PhotoOrderInvalid)
+		return
+	}
+
+	byID := make(map[string]models.PropertyImage, len(property.Photos))
+	for _, photo := range property.Photos {
+		byID[photo.ID.Hex()] = photo
+	}
+
+	photos := make([]models.PropertyImage, 0, len(req.Order))
+	for _, id := range req.Order {
+		photo, found := byID[id]
+		if !found {
+			apierror.Abort(c, apierror.PhotoOrderInvalid)
+			return
+		}
+		delete(byID, id)
+		photos = append(photos, photo)
+	}
+
+	if !h.setPhotos(c, property, photos) {
+		return
+	}
+
+	h.recordPhotos(c, property, photos)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ordre des photos mis à jour",
+		"images":  photos,
+	})
+}
+
+// UpdateImage modifie la légende d'une photo ou la définit comme photo de couverture
+func (h *PropertyImageHandler) UpdateImage(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
+		return
+	}
+
+	index, ok := findPhoto(c, property)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePropertyImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	photos := slices.Clone(property.Photos)
+	if req.Caption != nil {
+		photos[index].Caption = strings.TrimSpace(*req.Caption)
+	}
+	if req.IsCover != nil {
+		// Une propriété avec des photos a toujours exactement une couverture
+		if !*req.IsCover && photos[index].IsCover {
+			apierror.Abort(c, apierror.CoverRequired)
+			return
+		}
+		if *req.IsCover {
+			for i := range photos {
+				photos[i].IsCover = i == index
+			}
+		}
+	}
+
+	if !h.setPhotos(c, property, photos) {
+		return
+	}
+
+	h.recordPhotos(c, property, photos)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Photo mise à jour avec succès",
+		"image":   photos[index],
+	})
+}
+
+// DeleteImage supprime une photo et ses fichiers ; si c'était la couverture, la suivante la remplace
+func (h *PropertyImageHandler) DeleteImage(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
+		return
+	}
+
+	index, ok := findPhoto(c, property)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	removed, err := h.propertyRepo.DeletePhoto(ctx, property.ID, property.Photos[index].ID)
+	if errors.Is(err, repository.ErrPhotoNotFound) {
+		apierror.Abort(c, apierror.PhotoNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	deleteStoredFiles(h.storage, removed.Keys())
+
+	updated, err := h.propertyRepo.FindByID(ctx, property.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	h.recordPhotos(c, property, updated.Photos)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Photo supprimée avec succès",
+	})
+}
+
+// setPhotos enregistre les photos modifiées à partir de property.Photos. Si elles ont changé
+// entre-temps (envoi ou modification concurrente), rien n'est écrit et la réponse est 409.
+func (h *PropertyImageHandler) setPhotos(c *gin.Context, property *models.Property, photos []models.PropertyImage) bool {
+	err := h.propertyRepo.SetPhotos(c.Request.Context(), property.ID, property.PhotosVersion, photos)
+	if errors.Is(err, repository.ErrPhotosChanged) {
+		apierror.Abort(c, apierror.PhotosChanged)
+		return false
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	return true
+}
+
+// recordPhotos enregistre dans l'historique la propriété avec ses nouvelles photos
+func (h *PropertyImageHandler) recordPhotos(c *gin.Context, property *models.Property, photos []models.PropertyImage) {
+	after := *property
+	after.Photos = photos
+	recordRevision(c, h.revisionRepo, property, &after, models.PropertyRevision{Action: models.RevisionActionImages})
+}
+
+// deleteStoredFiles supprime des fichiers du stockage ; un échec est journalisé sans bloquer la requête
+func deleteStoredFiles(store storage.Storage, keys []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("Erreur lors de la suppression du fichier %s: %v", key, err)
+		}
+	}
+}
+
+// findPhoto retrouve la photo désignée par le paramètre :imageId. En cas d'échec,
+// la réponse d'erreur est déjà écrite.
+func findPhoto(c *gin.Context, property *models.Property) (int, bool) {
+	imageID, err := primitive.ObjectIDFromHex(c.Param("imageId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return -1, false
+	}
+
+	for i, photo := range property.Photos {
+		if photo.ID == imageID {
+			return i, true
+		}
+	}
+
+	apierror.Abort(c, apierror.PhotoNotFound)
+	return -1, false
+}
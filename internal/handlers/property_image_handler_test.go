@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// racingPhotoStore ajoute une photo juste avant chaque remplacement de la liste,
+// comme un envoi concurrent arrivant entre la lecture et l'écriture
+type racingPhotoStore struct {
+	repository.PropertyStore
+}
+
+func (s racingPhotoStore) SetPhotos(ctx context.Context, propertyID primitive.ObjectID, version int64, photos []models.PropertyImage) error {
+	if err := s.PropertyStore.AddPhoto(ctx, propertyID, models.PropertyImage{ID: primitive.NewObjectID()}); err != nil {
+		return err
+	}
+	return s.PropertyStore.SetPhotos(ctx, propertyID, version, photos)
+}
+
+func TestPhotoEditsDoNotOverwriteConcurrentUploads(t *testing.T) {
+	s := newTestServer(t, func(stores *repository.Stores) {
+		stores.Properties = racingPhotoStore{stores.Properties}
+	})
+	s.createUser("hote@example.com", "2")
+
+	host := s.login("hote@example.com")
+	id := s.createProperty(host, "Chalet des Alpes")
+	path := "/api/v1/properties/" + id + "/images"
+	objectID, _ := primitive.ObjectIDFromHex(id)
+
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	for _, photoID := range []primitive.ObjectID{first, second} {
+		if err := s.stores.Properties.AddPhoto(context.Background(), objectID, models.PropertyImage{ID: photoID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res := s.do(http.MethodPut, path+"/order", host, gin.H{"order": []string{second.Hex(), first.Hex()}})
+	expect(t, res, http.StatusConflict, apierror.PhotosChanged)
+	res = s.do(http.MethodPut, path+"/"+second.Hex(), host, gin.H{"caption": "Vue sur le lac"})
+	expect(t, res, http.StatusConflict, apierror.PhotosChanged)
+
+	live, err := s.stores.Properties.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(live.Photos) != 4 || live.Photos[0].ID != first {
+		t.Fatalf("photos écrasées : %d photos, première %s", len(live.Photos), live.Photos[0].ID.Hex())
+	}
+
+	// La première photo envoyée est devenue la couverture ; supprimée, la suivante la remplace
+	covers := 0
+	for _, photo := range live.Photos {
+		if photo.IsCover {
+			covers++
+		}
+	}
+	if covers != 1 || !live.Photos[0].IsCover {
+		t.Fatalf("%d couvertures, première photo couverture : %v", covers, live.Photos[0].IsCover)
+	}
+
+	expect(t, s.do(http.MethodDelete, path+"/"+first.Hex(), host, nil), http.StatusOK, "")
+	expect(t, s.do(http.MethodDelete, path+"/"+first.Hex(), host, nil), http.StatusNotFound, apierror.PhotoNotFound)
+
+	live, err = s.stores.Properties.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(live.Photos) != 3 || live.Photos[0].ID != second || !live.Photos[0].IsCover {
+		t.Fatalf("après suppression : %d photos, couverture %v", len(live.Photos), live.Photos[0].IsCover)
+	}
+}
@@ -0,0 +1,540 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
+	"onestay-back/internal/mailer"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+	"onestay-back/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Libellés des rôles dans les emails d'invitation
+var propertyRoleLabels = map[string]string{
+	models.PropertyRoleEditor:  "éditeur",
+	models.PropertyRoleViewer:  "lecteur",
+	models.PropertyRoleCleaner: "agent de ménage",
+}
+
+type PropertyMemberHandler struct {
+	cfg          *config.Config
+	propertyRepo repository.PropertyStore
+	memberRepo   repository.PropertyMemberStore
+	userRepo     repository.UserStore
+	mailer       mailer.Sender
+	authz        *rbac.Service
+}
+
+func NewPropertyMemberHandler(cfg *config.Config, stores *repository.Stores, sender mailer.Sender, authz *rbac.Service) *PropertyMemberHandler {
+	return &PropertyMemberHandler{
+		cfg:          cfg,
+		propertyRepo: stores.Properties,
+		memberRepo:   stores.PropertyMembers,
+		userRepo:     stores.Users,
+		mailer:       sender,
+		authz:        authz,
+	}
+}
+
+// GetMembers liste l'équipe d'une propriété : l'hôte propriétaire et ses collaborateurs.
+// Les invitations en attente ne sont visibles que du propriétaire.
+func (h *PropertyMemberHandler) GetMembers(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionRead)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	members, err := h.memberRepo.FindByPropertyID(ctx, property.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Les membres d'une organisation détentrice sont listés avec l'organisation
+	views := make([]models.PropertyMemberView, 0, len(members)+1)
+	if property.OrganizationID == nil {
+		views = append(views, h.memberView(c, property.HostID, models.PropertyRoleOwner, nil))
+	}
+	for _, member := range members {
+		views = append(views, h.memberView(c, member.UserID, member.Role, &member.CreatedAt))
+	}
+
+	response := gin.H{
+		"members":        views,
+		"count":          len(views),
+		"organizationId": property.OrganizationID,
+	}
+
+	canManage, err := propertyAllows(c, h.authz, property, models.PropertyActionManage)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if canManage {
+		invitations, err := h.memberRepo.FindPendingInvitations(ctx, property.ID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		response["invitations"] = invitations
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// InviteMember invite une personne par email à rejoindre l'équipe de la propriété.
+// Une nouvelle invitation pour la même adresse remplace la précédente.
+func (h *PropertyMemberHandler) InviteMember(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionManage)
+	if !ok {
+		return
+	}
+
+	var req models.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	email := strings.TrimSpace(req.Email)
+
+	// Une personne ayant déjà un compte ne peut pas être invitée deux fois
+	user, err := h.userRepo.FindByEmail(ctx, email)
+	if err != nil && err != mongo.ErrNoDocuments {
+		apierror.Internal(c, err)
+		return
+	}
+	if user != nil {
+		if user.ID == property.HostID {
+			apierror.Abort(c, apierror.MemberAlreadyExists)
+			return
+		}
+		if _, err := h.memberRepo.FindByPropertyAndUser(ctx, property.ID, user.ID); err == nil {
+			apierror.Abort(c, apierror.MemberAlreadyExists)
+			return
+		} else if err != mongo.ErrNoDocuments {
+			apierror.Internal(c, err)
+			return
+		}
+	}
+
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	invitation := &models.PropertyInvitation{
+		PropertyID: property.ID,
+		Email:      email,
+		Role:       req.Role,
+		TokenHash:  utils.HashToken(rawToken),
+		InvitedBy:  userID,
+		ExpiresAt:  time.Now().Add(h.cfg.InvitationTTL),
+	}
+	if err := h.memberRepo.CreateInvitation(ctx, invitation); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	link := fmt.Sprintf("%s/invitations?token=%s", h.cfg.FrontendURL, rawToken)
+	msg := mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("Invitation à gérer « %s » sur OneStay", property.Name),
+		Body: fmt.Sprintf(
+			"Bonjour,\n\nVous êtes invité à rejoindre l'équipe du logement « %s » en tant que %s.\nPour répondre à l'invitation, ouvrez le lien suivant :\n%s\n\nCe lien expire dans %s.\nSi vous ne connaissez pas l'expéditeur, ignorez cet email.",
+			property.Name, propertyRoleLabels[req.Role], link, h.cfg.InvitationTTL,
+		),
+	}
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Erreur lors de l'envoi de l'invitation à %s: %v", email, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Invitation envoyée",
+		"invitation": invitation,
+	})
+}
+
+// CancelInvitation annule une invitation en attente
+func (h *PropertyMemberHandler) CancelInvitation(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionManage)
+	if !ok {
+		return
+	}
+
+	invitationID, err := primitive.ObjectIDFromHex(c.Param("invitationId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return
+	}
+
+	if err := h.memberRepo.DeleteInvitation(c.Request.Context(), property.ID, invitationID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.InvitationInvalid)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation annulée",
+	})
+}
+
+// UpdateMember change le rôle d'un collaborateur
+func (h *PropertyMemberHandler) UpdateMember(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionManage)
+	if !ok {
+		return
+	}
+
+	memberID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return
+	}
+
+	var req models.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	if err := h.memberRepo.UpdateRole(c.Request.Context(), property.ID, memberID, req.Role); err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.MemberNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rôle mis à jour",
+		"member":  h.memberView(c, memberID, req.Role, nil),
+	})
+}
+
+// RemoveMember retire un collaborateur de l'équipe. Un collaborateur peut aussi quitter
+// l'équipe lui-même.
+func (h *PropertyMemberHandler) RemoveMember(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionRead)
+	if !ok {
+		return
+	}
+
+	memberID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	if memberID != userID && !authorizeProperty(c, h.authz, property, models.PropertyActionManage) {
+		return
+	}
+
+	if err := h.memberRepo.Delete(c.Request.Context(), property.ID, memberID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.MemberNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Collaborateur retiré de l'équipe",
+	})
+}
+
+// TransferOwnership confie la propriété à l'un de ses collaborateurs. L'ancien propriétaire
+// reste dans l'équipe en tant qu'éditeur ; il peut ensuite la quitter. Une propriété détenue
+// par une organisation ne se transfère pas : elle doit d'abord être rendue à son hôte.
+func (h *PropertyMemberHandler) TransferOwnership(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionManage)
+	if !ok {
+		return
+	}
+	if property.OrganizationID != nil {
+		apierror.Abort(c, apierror.PropertyOrganizationOwned)
+		return
+	}
+
+	var req models.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	newOwnerID, err := primitive.ObjectIDFromHex(req.UserID)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if _, err := h.memberRepo.FindByPropertyAndUser(ctx, property.ID, newOwnerID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.MemberNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	// Le nouveau propriétaire ne doit pas déjà avoir un logement du même nom
+	nameExists, err := h.propertyRepo.ExistsByNameAndHostID(ctx, property.Name, newOwnerID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if nameExists {
+		apierror.Abort(c, apierror.PropertyNameTaken)
+		return
+	}
+
+	if err := h.propertyRepo.TransferOwnership(ctx, property.ID, newOwnerID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.memberRepo.Delete(ctx, property.ID, newOwnerID); err != nil && err != mongo.ErrNoDocuments {
+		apierror.Internal(c, err)
+		return
+	}
+
+	previousOwner := &models.PropertyMember{
+		PropertyID: property.ID,
+		UserID:     property.HostID,
+		Role:       models.PropertyRoleEditor,
+		InvitedBy:  &newOwnerID,
+	}
+	if err := h.memberRepo.Create(ctx, previousOwner); err != nil && !mongo.IsDuplicateKeyError(err) {
+		apierror.Internal(c, err)
+		return
+	}
+
+	updatedProperty, err := h.propertyRepo.FindByID(ctx, property.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Propriété transférée avec succès",
+		"property": updatedProperty,
+	})
+}
+
+// GetSharedProperties liste les propriétés sur lesquelles l'utilisateur connecté collabore
+func (h *PropertyMemberHandler) GetSharedProperties(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	members, err := h.memberRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	roles := make(map[primitive.ObjectID]string, len(members))
+	ids := make([]primitive.ObjectID, 0, len(members))
+	for _, member := range members {
+		roles[member.PropertyID] = member.Role
+		ids = append(ids, member.PropertyID)
+	}
+
+	properties, err := h.propertyRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	shared := make([]models.SharedProperty, 0, len(properties))
+	for i := range properties {
+		shared = append(shared, models.SharedProperty{
+			Property: &properties[i],
+			Role:     roles[properties[i].ID],
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"properties": shared,
+		"count":      len(shared),
+	})
+}
+
+// GetInvitation présente une invitation avant qu'elle soit acceptée ou refusée
+func (h *PropertyMemberHandler) GetInvitation(c *gin.Context) {
+	invitation, property, ok := h.resolveInvitation(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invitation":   invitation,
+		"propertyName": property.Name,
+		"city":         property.City,
+	})
+}
+
+// AcceptInvitation ajoute l'utilisateur connecté à l'équipe de la propriété. L'invitation
+// doit avoir été envoyée à l'adresse email de son compte.
+func (h *PropertyMemberHandler) AcceptInvitation(c *gin.Context) {
+	invitation, property, ok := h.resolveInvitation(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	user, err := h.userRepo.FindByID(ctx, userID.Hex())
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.UserNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		apierror.Abort(c, apierror.InvitationEmailMismatch)
+		return
+	}
+	if user.ID == property.HostID {
+		apierror.Abort(c, apierror.MemberAlreadyExists)
+		return
+	}
+
+	if err := h.memberRepo.RespondInvitation(ctx, invitation.ID, models.InvitationStatusAccepted); err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.InvitationInvalid)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	member := &models.PropertyMember{
+		PropertyID: property.ID,
+		UserID:     user.ID,
+		Role:       invitation.Role,
+		InvitedBy:  &invitation.InvitedBy,
+	}
+	if err := h.memberRepo.Create(ctx, member); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			apierror.Abort(c, apierror.MemberAlreadyExists)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Invitation acceptée",
+		"property": property,
+		"role":     member.Role,
+	})
+}
+
+// DeclineInvitation refuse une invitation ; le lien reçu suffit, sans connexion
+func (h *PropertyMemberHandler) DeclineInvitation(c *gin.Context) {
+	invitation, _, ok := h.resolveInvitation(c)
+	if !ok {
+		return
+	}
+
+	if err := h.memberRepo.RespondInvitation(c.Request.Context(), invitation.ID, models.InvitationStatusDeclined); err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.InvitationInvalid)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation refusée",
+	})
+}
+
+// resolveInvitation charge l'invitation désignée par le paramètre :token, encore en attente,
+// et sa propriété. En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *PropertyMemberHandler) resolveInvitation(c *gin.Context) (*models.PropertyInvitation, *models.Property, bool) {
+	ctx := c.Request.Context()
+
+	invitation, err := h.memberRepo.FindInvitationByTokenHash(ctx, utils.HashToken(c.Param("token")))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.InvitationInvalid)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, nil, false
+	}
+	if !invitation.IsPendingAt(time.Now()) {
+		apierror.Abort(c, apierror.InvitationInvalid)
+		return nil, nil, false
+	}
+
+	property, err := h.propertyRepo.FindByID(ctx, invitation.PropertyID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.InvitationInvalid)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, nil, false
+	}
+
+	return invitation, property, true
+}
+
+// memberView présente un membre de l'équipe avec son identité ; un compte supprimé
+// entre-temps est présenté sans nom
+func (h *PropertyMemberHandler) memberView(c *gin.Context, userID primitive.ObjectID, role string, createdAt *time.Time) models.PropertyMemberView {
+	view := models.PropertyMemberView{UserID: userID, Role: role, CreatedAt: createdAt}
+
+	user, err := h.userRepo.FindByID(c.Request.Context(), userID.Hex())
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Erreur lors de la récupération de l'utilisateur %s: %v", userID.Hex(), err)
+		}
+		return view
+	}
+
+	view.Nom = user.Nom
+	view.Prenom = user.Prenom
+	view.Email = user.Email
+	return view
+}
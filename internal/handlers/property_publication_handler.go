@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+	"onestay-back/internal/publishing"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// GetReadiness retourne la checklist de publication : règles bloquantes, avertissements et
+// progression. Pour une propriété publiée, les modifications en attente sont prises en compte.
+func (h *PropertyHandler) GetReadiness(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+
+	checklist, pending, err := h.publisher.Readiness(c.Request.Context(), property)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	checklist.Localize(apierror.Locale(c))
+
+	c.JSON(http.StatusOK, gin.H{
+		"ready":          checklist.Ready,
+		"progress":       checklist.Progress,
+		"errors":         checklist.Errors,
+		"warnings":       checklist.Warnings,
+		"items":          checklist.Items,
+		"pendingChanges": pending,
+	})
+}
+
+// GetDraft prévisualise une propriété publiée avec ses modifications en attente, et liste
+// ces modifications section par section. Sans brouillon, la propriété en ligne est retournée.
+func (h *PropertyHandler) GetDraft(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+
+	draft, ok := h.findDraft(c, property)
+	if !ok {
+		return
+	}
+
+	if draft == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"property":       property,
+			"pendingChanges": false,
+			"changes":        []models.SectionDiff{},
+		})
+		return
+	}
+
+	preview := property.ApplyDraft(draft.Content)
+	c.JSON(http.StatusOK, gin.H{
+		"property":       preview,
+		"pendingChanges": true,
+		"changes":        models.DiffSections(property, preview),
+		"draft":          draft,
+	})
+}
+
+// DiscardDraft abandonne les modifications en attente
+func (h *PropertyHandler) DiscardDraft(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
+		return
+	}
+
+	draft, ok := h.findDraft(c, property)
+	if !ok {
+		return
+	}
+	if draft == nil {
+		apierror.Abort(c, apierror.DraftNotFound)
+		return
+	}
+
+	if err := h.draftRepo.DeleteByPropertyID(c.Request.Context(), property.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Modifications en attente abandonnées",
+	})
+}
+
+// PublishDraft met en ligne les modifications en attente d'une propriété publiée
+func (h *PropertyHandler) PublishDraft(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionPublish)
+	if !ok {
+		return
+	}
+
+	if !property.IsPublished() {
+		apierror.Abort(c, apierror.PropertyNotPublished)
+		return
+	}
+
+	draft, ok := h.findDraft(c, property)
+	if !ok {
+		return
+	}
+	if draft == nil {
+		apierror.Abort(c, apierror.DraftNotFound)
+		return
+	}
+
+	updatedProperty, ok := h.publish(c, property)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Modifications publiées avec succès",
+		"property": updatedProperty,
+	})
+}
+
+// UnpublishProperty repasse une propriété publiée en brouillon ; ses modifications en attente
+// deviennent son contenu et une publication programmée est annulée
+func (h *PropertyHandler) UnpublishProperty(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionPublish)
+	if !ok {
+		return
+	}
+
+	if !property.IsPublished() {
+		apierror.Abort(c, apierror.PropertyNotPublished)
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	updatedProperty, err := h.publisher.Unpublish(c.Request.Context(), property, &userID)
+	if errors.Is(err, publishing.ErrDraftChanged) {
+		apierror.Abort(c, apierror.DraftChanged)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Propriété repassée en brouillon",
+		"property": updatedProperty,
+	})
+}
+
+// CancelScheduledPublication annule une publication programmée
+func (h *PropertyHandler) CancelScheduledPublication(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionPublish)
+	if !ok {
+		return
+	}
+
+	if property.ScheduledPublishAt == nil {
+		apierror.Abort(c, apierror.PublicationNotScheduled)
+		return
+	}
+
+	if err := h.propertyRepo.SchedulePublication(c.Request.Context(), property.ID, nil); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publication programmée annulée",
+	})
+}
+
+// schedulePublication programme la publication d'un brouillon, ou celle des modifications
+// en attente d'une propriété déjà publiée
+func (h *PropertyHandler) schedulePublication(c *gin.Context, property *models.Property, at time.Time) {
+	if !at.After(time.Now()) {
+		apierror.Abort(c, apierror.PublicationDateInPast)
+		return
+	}
+
+	if property.IsPublished() {
+		draft, ok := h.findDraft(c, property)
+		if !ok {
+			return
+		}
+		if draft == nil {
+			apierror.Abort(c, apierror.NothingToPublish)
+			return
+		}
+	}
+
+	// Les règles sont vérifiées à la date de publication ; la checklist actuelle
+	// permet à l'hôte de corriger la propriété d'ici là
+	checklist, _, err := h.publisher.Readiness(c.Request.Context(), property)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	checklist.Localize(apierror.Locale(c))
+
+	if err := h.propertyRepo.SchedulePublication(c.Request.Context(), property.ID, &at); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	property.ScheduledPublishAt = &at
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Publication programmée",
+		"property":  property,
+		"readiness": checklist,
+	})
+}
+
+// publish met en ligne la propriété. Si des règles de publication bloquantes ne sont pas
+// respectées, la checklist est retournée en détail de l'erreur. En cas d'échec, la réponse
+// d'erreur est déjà écrite.
+func (h *PropertyHandler) publish(c *gin.Context, property *models.Property) (*models.Property, bool) {
+	userID, _ := currentUserID(c)
+	updatedProperty, err := h.publisher.Publish(c.Request.Context(), property, &userID)
+	if err != nil {
+		var notReady *publishing.NotReadyError
+		if errors.As(err, &notReady) {
+			notReady.Checklist.Localize(apierror.Locale(c))
+			apierror.AbortWithDetails(c, apierror.PropertyNotReady, notReady.Checklist)
+		} else if errors.Is(err, publishing.ErrDraftChanged) {
+			apierror.Abort(c, apierror.DraftChanged)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, false
+	}
+	return updatedProperty, true
+}
+
+// findDraft retourne le brouillon de la propriété, nil s'il n'y en a pas.
+// En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *PropertyHandler) findDraft(c *gin.Context, property *models.Property) (*models.PropertyDraft, bool) {
+	draft, err := h.draftRepo.FindByPropertyID(c.Request.Context(), property.ID)
+	if err == mongo.ErrNoDocuments {
+		return nil, true
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return nil, false
+	}
+	return draft, true
+}
+
+// workingCopy retourne le contenu sur lequel portent les modifications : la propriété
+// avec ses modifications en attente si elle est publiée, la propriété elle-même sinon.
+// En cas d'échec, la réponse d'erreur est déjà écrite.
+func workingCopy(c *gin.Context, draftRepo repository.PropertyDraftStore, property *models.Property) (*models.Property, bool) {
+	if !property.IsPublished() {
+		return property, true
+	}
+
+	draft, err := draftRepo.FindByPropertyID(c.Request.Context(), property.ID)
+	if err == mongo.ErrNoDocuments {
+		return property, true
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return nil, false
+	}
+	return property.ApplyDraft(draft.Content), true
+}
@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// racingDraftStore modifie le brouillon juste après chaque lecture lorsqu'il est armé,
+// comme une édition concurrente arrivant pendant la publication
+type racingDraftStore struct {
+	repository.PropertyDraftStore
+	properties repository.PropertyStore
+	armed      *atomic.Bool
+}
+
+func (s racingDraftStore) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) (*models.PropertyDraft, error) {
+	draft, err := s.PropertyDraftStore.FindByPropertyID(ctx, propertyID)
+	if err != nil || !s.armed.Load() {
+		return draft, err
+	}
+	live, err := s.properties.FindByID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.PropertyDraftStore.Save(ctx, live, bson.M{"description": "Édition concurrente"}, nil); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func TestPublishKeepsDraftEditedConcurrently(t *testing.T) {
+	armed := &atomic.Bool{}
+	s := newTestServer(t, func(stores *repository.Stores) {
+		stores.PropertyDrafts = racingDraftStore{stores.PropertyDrafts, stores.Properties, armed}
+	})
+	s.createUser("hote@example.com", "2")
+
+	host := s.login("hote@example.com")
+	id := s.createProperty(host, "Chalet des Alpes")
+	path := "/api/v1/properties/" + id
+	objectID, _ := primitive.ObjectIDFromHex(id)
+
+	// La publication exige une photo
+	if err := s.stores.Properties.AddPhoto(context.Background(), objectID, models.PropertyImage{ID: primitive.NewObjectID(), IsCover: true}); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, s.do(http.MethodPost, path+"/publish", host, nil), http.StatusOK, "")
+	expect(t, s.do(http.MethodPut, path, host, gin.H{"description": "Version relue"}), http.StatusOK, "")
+
+	armed.Store(true)
+	expect(t, s.do(http.MethodPost, path+"/draft/publish", host, nil), http.StatusConflict, apierror.DraftChanged)
+	expect(t, s.do(http.MethodPost, path+"/unpublish", host, nil), http.StatusConflict, apierror.DraftChanged)
+	armed.Store(false)
+
+	live, err := s.stores.Properties.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !live.IsPublished() || live.Description == "Version relue" {
+		t.Fatalf("brouillon périmé appliqué : publiée=%v, description %q", live.IsPublished(), live.Description)
+	}
+
+	draft, err := s.stores.PropertyDrafts.FindByPropertyID(context.Background(), objectID)
+	if err != nil {
+		t.Fatalf("brouillon concurrent supprimé : %v", err)
+	}
+	if draft.Content.Description != "Édition concurrente" {
+		t.Fatalf("description en attente = %q, attendu l'édition concurrente", draft.Content.Description)
+	}
+}
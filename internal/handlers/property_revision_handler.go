@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type PropertyRevisionHandler struct {
+	cfg          *config.Config
+	propertyRepo repository.PropertyStore
+	revisionRepo repository.PropertyRevisionStore
+	draftRepo    repository.PropertyDraftStore
+	authz        *rbac.Service
+}
+
+func NewPropertyRevisionHandler(cfg *config.Config, stores *repository.Stores, authz *rbac.Service) *PropertyRevisionHandler {
+	return &PropertyRevisionHandler{
+		cfg:          cfg,
+		propertyRepo: stores.Properties,
+		revisionRepo: stores.PropertyRevisions,
+		draftRepo:    stores.PropertyDrafts,
+		authz:        authz,
+	}
+}
+
+const (
+	defaultRevisionLimit = 50
+	maxRevisionLimit     = models.MaxPropertyRevisions
+)
+
+// GetRevisions liste l'historique d'une propriété, de la révision la plus récente à la plus ancienne
+func (h *PropertyRevisionHandler) GetRevisions(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+
+	limit := defaultRevisionLimit
+	if v := c.Query("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxRevisionLimit {
+			apierror.Abort(c, apierror.InvalidRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	revisions, err := h.revisionRepo.FindByPropertyID(c.Request.Context(), property.ID, int64(limit))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revisions": revisions,
+	})
+}
+
+// GetRevision retourne une révision avec l'instantané complet de la propriété
+func (h *PropertyRevisionHandler) GetRevision(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.loadRevision(c, property, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revision": revision,
+	})
+}
+
+// DiffRevisions compare deux révisions section par section (?from=&to=).
+// Sans to, la révision from est comparée à l'état actuel de la propriété.
+func (h *PropertyRevisionHandler) DiffRevisions(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+
+	from, ok := h.loadRevision(c, property, c.Query("from"))
+	if !ok {
+		return
+	}
+
+	after := property
+	to := gin.H{"current": true}
+	if c.Query("to") != "" {
+		revision, ok := h.loadRevision(c, property, c.Query("to"))
+		if !ok {
+			return
+		}
+		after = revision.Snapshot
+		to = gin.H{"number": revision.Number, "createdAt": revision.CreatedAt}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":     gin.H{"number": from.Number, "createdAt": from.CreatedAt},
+		"to":       to,
+		"sections": models.DiffSections(from.Snapshot, after),
+	})
+}
+
+// RestoreRevision remet en place le contenu d'une révision. Par défaut toutes les sections
+// restaurables le sont ; {"sections": [...]} limite la restauration. Le statut de publication,
+// les photos et le slug (déjà partagé) ne sont jamais modifiés. Sur une propriété publiée,
+// la restauration est placée dans le brouillon comme toute modification ; sinon elle crée
+// elle-même une révision et peut donc être annulée.
+func (h *PropertyRevisionHandler) RestoreRevision(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.loadRevision(c, property, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	// Le corps est facultatif
+	var req models.RestoreRevisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	requested := req.Sections
+	if len(requested) == 0 {
+		requested = models.RestorableSections
+	}
+	var invalid []string
+	for _, section := range requested {
+		if !slices.Contains(models.RestorableSections, section) {
+			invalid = append(invalid, section)
+		}
+	}
+	if len(invalid) > 0 {
+		apierror.AbortWithDetails(c, apierror.RevisionSectionsInvalid, invalid)
+		return
+	}
+
+	// La restauration s'applique au contenu en cours d'édition, modifications en attente comprises
+	current, ok := workingCopy(c, h.draftRepo, property)
+	if !ok {
+		return
+	}
+
+	snapshot := revision.Snapshot
+	var sections []string
+	for _, section := range models.ChangedSections(current, snapshot) {
+		if slices.Contains(requested, section) {
+			sections = append(sections, section)
+		}
+	}
+	if len(sections) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Aucune modification à appliquer",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	updates := snapshot.SectionUpdates(sections)
+
+	// État obtenu après restauration, pour vérifier sa cohérence
+	restored := *current
+	restoreGeneral := slices.Contains(sections, "general")
+	restoreRecommendations := slices.Contains(sections, "localRecommendations")
+	if restoreGeneral {
+		restored.Name = snapshot.Name
+		restored.Location = snapshot.Location
+		restored.DefaultLocale = snapshot.DefaultLocale
+	}
+	if restoreRecommendations {
+		restored.LocalRecommendations = snapshot.LocalRecommendations
+	}
+	if slices.Contains(sections, "translations") {
+		restored.Translations = snapshot.Translations
+	}
+
+	// Le nom en ligne appartient à la propriété elle-même : seul un autre nom est vérifié
+	if restored.Name != current.Name && restored.Name != property.Name {
+		exists, err := h.propertyRepo.ExistsByNameAndHostID(ctx, restored.Name, property.HostID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		if exists {
+			apierror.Abort(c, apierror.PropertyNameTaken)
+			return
+		}
+	}
+
+	if restored.Translation(restored.ContentLocale(h.cfg.DefaultLocale)) != nil {
+		apierror.Abort(c, apierror.TranslationLocaleExists)
+		return
+	}
+
+	// Les distances des recommandations dépendent de la position de la propriété : elles sont
+	// recalculées sur une copie, les recommandations actuelles pouvant être conservées
+	if restored.LocalRecommendations != nil && (restoreGeneral || restoreRecommendations) {
+		recommendations := *restored.LocalRecommendations
+		recommendations.Recommendations = slices.Clone(recommendations.Recommendations)
+		restored.LocalRecommendations = &recommendations
+		restored.ComputeRecommendationDistances()
+		updates["localRecommendations"] = restored.LocalRecommendations
+	}
+
+	if property.IsPublished() {
+		userID, _ := currentUserID(c)
+		if err := h.draftRepo.Save(ctx, property, updates, &userID); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+
+		draft, err := h.draftRepo.FindByPropertyID(ctx, property.ID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "Révision restaurée : elle sera visible après publication",
+			"sections":       sections,
+			"property":       property.ApplyDraft(draft.Content),
+			"pendingChanges": true,
+		})
+		return
+	}
+
+	if err := h.propertyRepo.Update(ctx, property.ID, updates); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	updatedProperty, err := h.propertyRepo.FindByID(ctx, property.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	recordRevision(c, h.revisionRepo, property, updatedProperty, models.PropertyRevision{
+		Action:       models.RevisionActionRestore,
+		RestoredFrom: revision.Number,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Révision restaurée avec succès",
+		"sections": sections,
+		"property": updatedProperty,
+	})
+}
+
+// loadRevision charge la révision de la propriété désignée par son numéro.
+// En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *PropertyRevisionHandler) loadRevision(c *gin.Context, property *models.Property, value string) (*models.PropertyRevision, bool) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		apierror.Abort(c, apierror.RevisionInvalid)
+		return nil, false
+	}
+
+	revision, err := h.revisionRepo.FindByNumber(c.Request.Context(), property.ID, number)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.RevisionNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, false
+	}
+
+	return revision, true
+}
+
+// recordRevision enregistre l'état after dans l'historique de la propriété. L'historique ne doit
+// pas faire échouer une modification déjà appliquée : une erreur est seulement journalisée.
+func recordRevision(c *gin.Context, repo repository.PropertyRevisionStore, before, after *models.Property, revision models.PropertyRevision) {
+	if userID, ok := currentUserID(c); ok {
+		revision.AuthorID = &userID
+	}
+
+	if err := repo.Record(c.Request.Context(), before, after, &revision); err != nil {
+		log.Printf("Erreur lors de l'enregistrement de la révision de la propriété %s: %v", after.ID.Hex(), err)
+	}
+}
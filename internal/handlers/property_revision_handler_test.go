@@ -0,0 +1,68 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"onestay-back/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPublishedPropertyEditsGoToTheDraft(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("hote@example.com", "2")
+
+	host := s.login("hote@example.com")
+	id := s.createProperty(host, "Chalet des Alpes")
+	path := "/api/v1/properties/" + id
+	objectID, _ := primitive.ObjectIDFromHex(id)
+
+	// La publication exige une photo
+	if err := s.stores.Properties.AddPhoto(context.Background(), objectID, models.PropertyImage{ID: primitive.NewObjectID(), IsCover: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Révisions 2 et 3, puis publication
+	expect(t, s.do(http.MethodPut, path, host, gin.H{"description": "Première version"}), http.StatusOK, "")
+	expect(t, s.do(http.MethodPut, path, host, gin.H{"description": "Seconde version"}), http.StatusOK, "")
+	expect(t, s.do(http.MethodPost, path+"/publish", host, nil), http.StatusOK, "")
+
+	// Un brouillon existe déjà lorsque la révision est restaurée
+	expect(t, s.do(http.MethodPut, path, host, gin.H{"wifi": gin.H{"enabled": true, "networkName": "Chalet"}}), http.StatusOK, "")
+	res := s.do(http.MethodPost, path+"/revisions/2/restore", host, gin.H{"sections": []string{"general"}})
+	expect(t, res, http.StatusOK, "")
+	if res.Body["pendingChanges"] != true {
+		t.Errorf("restauration appliquée en direct sur une propriété publiée : %v", res.Body)
+	}
+
+	res = s.do(http.MethodPut, path+"/translations/en", host, gin.H{"fields": gin.H{"description": "First version"}})
+	expect(t, res, http.StatusOK, "")
+	if res.Body["pendingChanges"] != true {
+		t.Errorf("traduction appliquée en direct sur une propriété publiée : %v", res.Body)
+	}
+
+	live, err := s.stores.Properties.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if live.Description != "Seconde version" || len(live.Translations) != 0 {
+		t.Fatalf("contenu en ligne modifié avant publication : %q, %d traductions", live.Description, len(live.Translations))
+	}
+
+	// La publication du brouillon met en ligne la restauration et la traduction
+	expect(t, s.do(http.MethodPost, path+"/draft/publish", host, nil), http.StatusOK, "")
+
+	live, err = s.stores.Properties.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if live.Description != "Première version" || live.Wifi == nil || live.Wifi.NetworkName != "Chalet" {
+		t.Errorf("après publication : description %q, Wi-Fi %+v", live.Description, live.Wifi)
+	}
+	if translation := live.Translation("en"); translation == nil || len(translation.Fields) != 1 {
+		t.Errorf("traduction anglaise non publiée : %+v", live.Translations)
+	}
+}
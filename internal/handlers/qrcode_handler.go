@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
+	"onestay-back/internal/models"
+	"onestay-back/internal/qrcode"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type QRCodeHandler struct {
+	cfg           *config.Config
+	propertyRepo  repository.PropertyStore
+	guestLinkRepo repository.GuestLinkStore
+	authz         *rbac.Service
+}
+
+func NewQRCodeHandler(cfg *config.Config, stores *repository.Stores, authz *rbac.Service) *QRCodeHandler {
+	return &QRCodeHandler{
+		cfg:           cfg,
+		propertyRepo:  stores.Properties,
+		guestLinkRepo: stores.GuestLinks,
+		authz:         authz,
+	}
+}
+
+// GetWifiQRCode retourne le QR code de connexion au Wi-Fi. Il contient le mot de passe :
+// il est réservé à l'équipe de la propriété. Paramètres optionnels : format (png, svg), size, level (L, M, Q, H).
+func (h *QRCodeHandler) GetWifiQRCode(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionRead)
+	if !ok {
+		return
+	}
+
+	writeWifiQRCode(c, property)
+}
+
+// GetGuestWifiQRCode retourne le QR code de connexion au Wi-Fi à un voyageur disposant d'un lien valide
+func (h *QRCodeHandler) GetGuestWifiQRCode(c *gin.Context) {
+	_, property, ok := resolveGuestLink(c, h.guestLinkRepo, h.propertyRepo, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	writeWifiQRCode(c, property)
+}
+
+// GetGuidebookQRCode retourne le QR code pointant vers le livret d'accueil en ligne.
+// Il ne contient aucun secret : il est public pour une propriété publiée.
+func (h *QRCodeHandler) GetGuidebookQRCode(c *gin.Context) {
+	property, err := findProperty(c.Request.Context(), h.propertyRepo, c.Param("id"))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.PropertyNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return
+	}
+
+	if !ensureVisible(c, h.authz, property) {
+		return
+	}
+
+	writeQRCode(c, guidebookURL(h.cfg.FrontendURL, property), property.Slug+"-livret", false)
+}
+
+func writeWifiQRCode(c *gin.Context, property *models.Property) {
+	wifi := property.Wifi
+	if wifi == nil || !wifi.Enabled || wifi.NetworkName == "" {
+		apierror.Abort(c, apierror.WifiNotConfigured)
+		return
+	}
+
+	writeQRCode(c, qrcode.WifiPayload(wifi.NetworkName, wifi.Password), property.Slug+"-wifi", true)
+}
+
+// writeQRCode encode le contenu selon les paramètres format, size et level de la requête
+func writeQRCode(c *gin.Context, content, filename string, private bool) {
+	opts, err := qrcode.ParseOptions(c.Query("format"), c.Query("size"), c.Query("level"))
+	switch {
+	case errors.Is(err, qrcode.ErrInvalidFormat):
+		apierror.Abort(c, apierror.QRCodeInvalidFormat)
+		return
+	case errors.Is(err, qrcode.ErrInvalidSize):
+		apierror.Abort(c, apierror.QRCodeInvalidSize, qrcode.MinSize, qrcode.MaxSize)
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.QRCodeInvalidLevel)
+		return
+	}
+
+	data, err := qrcode.Encode(content, opts)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if private {
+		c.Header("Cache-Control", "private, no-store")
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, filename, opts.Format))
+	c.Data(http.StatusOK, opts.ContentType(), data)
+}
+
+// guidebookURL retourne l'adresse publique du livret d'accueil sur le front
+func guidebookURL(frontendURL string, property *models.Property) string {
+	return fmt.Sprintf("%s/guide/%s", frontendURL, property.Slug)
+}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type ReservationHandler struct {
+	propertyRepo    repository.PropertyStore
+	reservationRepo repository.ReservationStore
+	blockRepo       repository.CalendarBlockStore
+	calendarLocks   repository.CalendarLockStore
+	authz           *rbac.Service
+}
+
+func NewReservationHandler(stores *repository.Stores, authz *rbac.Service) *ReservationHandler {
+	return &ReservationHandler{
+		propertyRepo:    stores.Properties,
+		reservationRepo: stores.Reservations,
+		blockRepo:       stores.CalendarBlocks,
+		calendarLocks:   stores.CalendarLocks,
+		authz:           authz,
+	}
+}
+
+// CreateReservation crée une réservation après vérification des disponibilités et de la capacité
+func (h *ReservationHandler) CreateReservation(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	var req models.CreateReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	arrival, departure, ok := parseStayDates(c, req.ArrivalDate, req.DepartureDate)
+	if !ok {
+		return
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.ReservationStatusPending
+	}
+
+	userID, _ := currentUserID(c)
+	reservation := &models.Reservation{
+		PropertyID:     property.ID,
+		GuestName:      req.GuestName,
+		GuestEmail:     req.GuestEmail,
+		GuestPhone:     req.GuestPhone,
+		NumberOfGuests: req.NumberOfGuests,
+		ArrivalDate:    arrival,
+		DepartureDate:  departure,
+		Status:         status,
+		Notes:          req.Notes,
+		CreatedBy:      userID,
+	}
+
+	// Le calendrier reste verrouillé de la vérification des disponibilités à l'écriture
+	unlock, ok := lockCalendar(c, h.calendarLocks, property.ID)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	if !h.checkAvailability(c, property, reservation) {
+		return
+	}
+
+	if err := h.reservationRepo.Create(c.Request.Context(), reservation); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Réservation créée avec succès",
+		"reservation": reservation,
+	})
+}
+
+// GetReservations liste les réservations d'une propriété (filtres optionnels : status, from, to)
+func (h *ReservationHandler) GetReservations(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionRead)
+	if !ok {
+		return
+	}
+
+	var from, to *time.Time
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse(models.ReservationDateLayout, value)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidDate, "from")
+			return
+		}
+		from = &date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse(models.ReservationDateLayout, value)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidDate, "to")
+			return
+		}
+		to = &date
+	}
+
+	reservations, err := h.reservationRepo.FindByPropertyID(c.Request.Context(), property.ID, c.Query("status"), from, to)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reservations": reservations,
+		"count":        len(reservations),
+	})
+}
+
+// GetReservation retourne une réservation
+func (h *ReservationHandler) GetReservation(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionRead)
+	if !ok {
+		return
+	}
+
+	reservation, ok := h.loadReservation(c, property)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reservation": reservation,
+	})
+}
+
+// UpdateReservation met à jour une réservation (coordonnées, dates, nombre de voyageurs, statut)
+func (h *ReservationHandler) UpdateReservation(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	reservation, ok := h.loadReservation(c, property)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	updates := bson.M{}
+	if req.GuestName != "" {
+		updates["guestName"] = req.GuestName
+	}
+	if req.GuestEmail != "" {
+		updates["guestEmail"] = req.GuestEmail
+	}
+	if req.GuestPhone != "" {
+		updates["guestPhone"] = req.GuestPhone
+	}
+	if req.Notes != "" {
+		updates["notes"] = req.Notes
+	}
+
+	if req.Status != "" && req.Status != reservation.Status {
+		if !reservation.CanTransitionTo(req.Status) {
+			apierror.Abort(c, apierror.ReservationStatusTransition, reservation.Status, req.Status)
+			return
+		}
+		updates["status"] = req.Status
+	}
+
+	// Les dates et la capacité sont revérifiées dès que l'une d'elles change
+	datesChanged := req.ArrivalDate != "" || req.DepartureDate != ""
+	guestsChanged := req.NumberOfGuests != 0 && req.NumberOfGuests != reservation.NumberOfGuests
+	if datesChanged || guestsChanged {
+		if reservation.Status == models.ReservationStatusCancelled || reservation.Status == models.ReservationStatusCompleted {
+			apierror.Abort(c, apierror.ReservationStayLocked, reservation.Status)
+			return
+		}
+
+		arrivalStr := reservation.ArrivalDate.Format(models.ReservationDateLayout)
+		departureStr := reservation.DepartureDate.Format(models.ReservationDateLayout)
+		if req.ArrivalDate != "" {
+			arrivalStr = req.ArrivalDate
+		}
+		if req.DepartureDate != "" {
+			departureStr = req.DepartureDate
+		}
+
+		arrival, departure, ok := parseStayDates(c, arrivalStr, departureStr)
+		if !ok {
+			return
+		}
+
+		// Le verrou est conservé jusqu'à l'enregistrement des nouvelles dates
+		unlock, ok := lockCalendar(c, h.calendarLocks, property.ID)
+		if !ok {
+			return
+		}
+		defer unlock()
+
+		candidate := *reservation
+		candidate.ArrivalDate = arrival
+		candidate.DepartureDate = departure
+		if guestsChanged {
+			candidate.NumberOfGuests = req.NumberOfGuests
+		}
+
+		if !h.checkAvailability(c, property, &candidate) {
+			return
+		}
+
+		updates["arrivalDate"] = arrival
+		updates["departureDate"] = departure
+		updates["numberOfGuests"] = candidate.NumberOfGuests
+	}
+
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Aucune modification à appliquer",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.reservationRepo.Update(ctx, reservation.ID, updates); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	updatedReservation, err := h.reservationRepo.FindByID(ctx, reservation.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Réservation mise à jour avec succès",
+		"reservation": updatedReservation,
+	})
+}
+
+// DeleteReservation supprime une réservation
+func (h *ReservationHandler) DeleteReservation(c *gin.Context) {
+	property, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionOperate)
+	if !ok {
+		return
+	}
+
+	reservation, ok := h.loadReservation(c, property)
+	if !ok {
+		return
+	}
+
+	if err := h.reservationRepo.Delete(c.Request.Context(), reservation.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Réservation supprimée avec succès",
+	})
+}
+
+// checkAvailability vérifie la capacité maximale de la propriété et l'absence de chevauchement
+// avec une autre réservation ou une période bloquée. En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *ReservationHandler) checkAvailability(c *gin.Context, property *models.Property, reservation *models.Reservation) bool {
+	if property.Rules != nil && property.Rules.MaxGuests != nil && reservation.NumberOfGuests > *property.Rules.MaxGuests {
+		apierror.AbortWithDetails(c, apierror.CapacityExceeded, gin.H{"maxGuests": *property.Rules.MaxGuests}, *property.Rules.MaxGuests)
+		return false
+	}
+
+	var excludeID *primitive.ObjectID
+	if !reservation.ID.IsZero() {
+		excludeID = &reservation.ID
+	}
+
+	overlap, err := h.reservationRepo.HasOverlap(c.Request.Context(), property.ID, reservation.ArrivalDate, reservation.DepartureDate, excludeID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	if overlap {
+		apierror.Abort(c, apierror.ReservationOverlap)
+		return false
+	}
+
+	blocked, err := h.blockRepo.HasOverlap(c.Request.Context(), property.ID, reservation.ArrivalDate, reservation.DepartureDate)
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	if blocked {
+		apierror.Abort(c, apierror.DatesBlocked)
+		return false
+	}
+
+	return true
+}
+
+// lockCalendar verrouille le calendrier de la propriété jusqu'à l'appel de la fonction
+// retournée. En cas d'échec, la réponse d'erreur est déjà écrite.
+func lockCalendar(c *gin.Context, locks repository.CalendarLockStore, propertyID primitive.ObjectID) (func(), bool) {
+	unlock, err := locks.Lock(c.Request.Context(), propertyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCalendarBusy) {
+			apierror.Abort(c, apierror.CalendarBusy)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, false
+	}
+	return unlock, true
+}
+
+// loadReservation charge la réservation de l'URL et vérifie qu'elle appartient à la propriété
+func (h *ReservationHandler) loadReservation(c *gin.Context, property *models.Property) (*models.Reservation, bool) {
+	reservationID, err := primitive.ObjectIDFromHex(c.Param("reservationId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return nil, false
+	}
+
+	reservation, err := h.reservationRepo.FindByID(c.Request.Context(), reservationID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.ReservationNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, false
+	}
+
+	if reservation.PropertyID != property.ID {
+		apierror.Abort(c, apierror.ReservationNotFound)
+		return nil, false
+	}
+
+	return reservation, true
+}
+
+// parseStayDates lit les dates d'arrivée et de départ ("YYYY-MM-DD") et vérifie leur cohérence.
+// En cas d'échec, la réponse d'erreur est déjà écrite.
+func parseStayDates(c *gin.Context, arrivalStr, departureStr string) (time.Time, time.Time, bool) {
+	arrival, err := time.Parse(models.ReservationDateLayout, arrivalStr)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidDate, "arrivalDate")
+		return time.Time{}, time.Time{}, false
+	}
+
+	departure, err := time.Parse(models.ReservationDateLayout, departureStr)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidDate, "departureDate")
+		return time.Time{}, time.Time{}, false
+	}
+
+	if !departure.After(arrival) {
+		apierror.Abort(c, apierror.InvalidDateRange)
+		return time.Time{}, time.Time{}, false
+	}
+
+	return arrival, departure, true
+}
@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// slowOverlapStore ralentit la vérification des chevauchements pour que des requêtes
+// concurrentes se croisent entre la vérification et l'écriture
+type slowOverlapStore struct {
+	repository.ReservationStore
+}
+
+func (s slowOverlapStore) HasOverlap(ctx context.Context, propertyID primitive.ObjectID, arrival, departure time.Time, excludeID *primitive.ObjectID) (bool, error) {
+	overlap, err := s.ReservationStore.HasOverlap(ctx, propertyID, arrival, departure, excludeID)
+	time.Sleep(20 * time.Millisecond)
+	return overlap, err
+}
+
+func TestConcurrentReservationsCannotDoubleBook(t *testing.T) {
+	s := newTestServer(t, func(stores *repository.Stores) {
+		stores.Reservations = slowOverlapStore{stores.Reservations}
+	})
+	s.createUser("hote@example.com", "2")
+
+	host := s.login("hote@example.com")
+	id := s.createProperty(host, "Gîte des Vignes")
+	path := "/api/v1/properties/" + id + "/reservations"
+
+	const attempts = 10
+	statuses := make([]int, attempts)
+	codes := make([]apierror.Code, attempts)
+
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < attempts; i++ {
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			start.Wait()
+			res := s.do(http.MethodPost, path, host, gin.H{
+				"guestName":      "Voyageur",
+				"guestEmail":     "voyageur@example.com",
+				"numberOfGuests": 2,
+				"arrivalDate":    "2030-07-01",
+				"departureDate":  "2030-07-08",
+			})
+			statuses[i], codes[i] = res.Status, res.code()
+		}(i)
+	}
+	start.Done()
+	done.Wait()
+
+	created := 0
+	for i := range statuses {
+		switch {
+		case statuses[i] == http.StatusCreated:
+			created++
+		case codes[i] != apierror.ReservationOverlap:
+			t.Errorf("requête %d : statut %d, code %q", i, statuses[i], codes[i])
+		}
+	}
+	if created != 1 {
+		t.Fatalf("%d réservations créées pour les mêmes dates, attendu 1", created)
+	}
+
+	res := s.do(http.MethodGet, path, host, nil)
+	expect(t, res, http.StatusOK, "")
+	if res.Body["count"] != float64(1) {
+		t.Errorf("%v réservations enregistrées, attendu 1", res.Body["count"])
+	}
+}
@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// errTemplateAccessLost signale une propriété liée que l'hôte du modèle ne peut plus modifier
+var errTemplateAccessLost = errors.New("propriété liée non modifiable par l'hôte du modèle")
+
+type SectionTemplateHandler struct {
+	propertyRepo repository.PropertyStore
+	templateRepo repository.SectionTemplateStore
+	draftRepo    repository.PropertyDraftStore
+	revisionRepo repository.PropertyRevisionStore
+	authz        *rbac.Service
+}
+
+func NewSectionTemplateHandler(stores *repository.Stores, authz *rbac.Service) *SectionTemplateHandler {
+	return &SectionTemplateHandler{
+		propertyRepo: stores.Properties,
+		templateRepo: stores.SectionTemplates,
+		draftRepo:    stores.PropertyDrafts,
+		revisionRepo: stores.PropertyRevisions,
+		authz:        authz,
+	}
+}
+
+// GetTemplates liste les modèles de l'hôte connecté, éventuellement pour une section (?section=)
+func (h *SectionTemplateHandler) GetTemplates(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return
+	}
+
+	section := c.Query("section")
+	if section != "" && !models.IsTemplateSection(section) {
+		apierror.Abort(c, apierror.TemplateSectionInvalid)
+		return
+	}
+
+	templates, err := h.templateRepo.FindByHostID(c.Request.Context(), userID, section)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// GetTemplate récupère un modèle de l'hôte connecté
+func (h *SectionTemplateHandler) GetTemplate(c *gin.Context) {
+	template, ok := h.loadOwnedTemplate(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"template": template,
+	})
+}
+
+// CreateTemplate enregistre une section comme modèle, reprise d'une propriété ou saisie
+func (h *SectionTemplateHandler) CreateTemplate(c *gin.Context) {
+	var req models.CreateSectionTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthenticated)
+		return
+	}
+
+	if !models.IsTemplateSection(req.Section) {
+		apierror.Abort(c, apierror.TemplateSectionInvalid)
+		return
+	}
+
+	content, ok := h.templateContent(c, req.Section, req.PropertyID, req.Value)
+	if !ok {
+		return
+	}
+	if content == nil {
+		apierror.Abort(c, apierror.TemplateContentInvalid)
+		return
+	}
+
+	template := &models.SectionTemplate{
+		HostID:  userID,
+		Name:    strings.TrimSpace(req.Name),
+		Section: req.Section,
+		Content: content,
+	}
+	if err := h.templateRepo.Create(c.Request.Context(), template); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Modèle créé avec succès",
+		"template": template,
+	})
+}
+
+// UpdateTemplate renomme un modèle ou remplace son contenu. Un nouveau contenu est appliqué
+// aux propriétés liées ; un échec sur l'une d'elles n'interrompt pas les suivantes.
+func (h *SectionTemplateHandler) UpdateTemplate(c *gin.Context) {
+	template, ok := h.loadOwnedTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSectionTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	content, ok := h.templateContent(c, template.Section, req.PropertyID, req.Value)
+	if !ok {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" && content == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Aucune modification à appliquer",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.templateRepo.Update(ctx, template.ID, name, content); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	updatedTemplate, err := h.templateRepo.FindByID(ctx, template.ID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	propagated := 0
+	failed := []primitive.ObjectID{}
+	if content != nil {
+		for _, propertyID := range updatedTemplate.LinkedPropertyIDs {
+			if err := h.propagate(c, updatedTemplate, propertyID); err != nil {
+				log.Printf("Erreur lors de l'application du modèle %s à la propriété %s: %v", updatedTemplate.ID.Hex(), propertyID.Hex(), err)
+				failed = append(failed, propertyID)
+				continue
+			}
+			propagated++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Modèle mis à jour avec succès",
+		"template":   updatedTemplate,
+		"propagated": propagated,
+		"failed":     failed,
+	})
+}
+
+// DeleteTemplate supprime un modèle ; les propriétés où il a été appliqué conservent leur contenu
+func (h *SectionTemplateHandler) DeleteTemplate(c *gin.Context) {
+	template, ok := h.loadOwnedTemplate(c)
+	if !ok {
+		return
+	}
+
+	if err := h.templateRepo.Delete(c.Request.Context(), template.ID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Modèle supprimé avec succès",
+	})
+}
+
+// ApplyTemplate remplace la section du modèle sur des propriétés de l'hôte. Les propriétés
+// publiées reçoivent la section dans leurs modifications en attente. Avec linked, les
+// propriétés suivront les modifications ultérieures du modèle.
+func (h *SectionTemplateHandler) ApplyTemplate(c *gin.Context) {
+	template, ok := h.loadOwnedTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req models.ApplySectionTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Toutes les propriétés sont vérifiées avant d'en modifier une seule
+	var properties []*models.Property
+	for _, identifier := range req.PropertyIDs {
+		property, err := findProperty(ctx, h.propertyRepo, identifier)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				apierror.Abort(c, apierror.PropertyNotFound)
+			} else {
+				apierror.Internal(c, err)
+			}
+			return
+		}
+		if !authorizeProperty(c, h.authz, property, models.PropertyActionEdit) {
+			return
+		}
+		if !slices.ContainsFunc(properties, func(p *models.Property) bool { return p.ID == property.ID }) {
+			properties = append(properties, property)
+		}
+	}
+
+	updatedProperties := make([]*models.Property, 0, len(properties))
+	for _, property := range properties {
+		updatedProperty, err := h.applySection(c, template, property)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+
+		if req.Linked {
+			err = h.templateRepo.Link(ctx, template, property.ID)
+		} else {
+			// Appliquée sans lien, la section ne suit plus aucun modèle
+			err = h.templateRepo.UnlinkSections(ctx, property.ID, []string{template.Section})
+		}
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+
+		updatedProperties = append(updatedProperties, updatedProperty)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Modèle appliqué avec succès",
+		"properties": updatedProperties,
+		"count":      len(updatedProperties),
+		"linked":     req.Linked,
+	})
+}
+
+// UnlinkProperty détache une propriété du modèle : elle conserve son contenu actuel
+// mais ne reçoit plus les modifications du modèle
+func (h *SectionTemplateHandler) UnlinkProperty(c *gin.Context) {
+	template, ok := h.loadOwnedTemplate(c)
+	if !ok {
+		return
+	}
+
+	propertyID, err := primitive.ObjectIDFromHex(c.Param("propertyId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return
+	}
+
+	if !slices.Contains(template.LinkedPropertyIDs, propertyID) {
+		apierror.Abort(c, apierror.TemplateNotLinked)
+		return
+	}
+
+	if err := h.templateRepo.Unlink(c.Request.Context(), template.ID, propertyID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Propriété détachée du modèle",
+	})
+}
+
+// applySection remplace la section du modèle sur la propriété et retourne la propriété à jour
+// (avec ses modifications en attente si elle est publiée). Une section déjà identique n'est
+// pas réécrite.
+func (h *SectionTemplateHandler) applySection(c *gin.Context, template *models.SectionTemplate, property *models.Property) (*models.Property, error) {
+	ctx := c.Request.Context()
+
+	current := property
+	if property.IsPublished() {
+		draft, err := h.draftRepo.FindByPropertyID(ctx, property.ID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		if draft != nil {
+			current = property.ApplyDraft(draft.Content)
+		}
+	}
+
+	applied := current.ApplySection(template.Section, template.Content)
+
+	// Les distances des recommandations sont calculées depuis la propriété cible,
+	// sur une copie pour ne pas modifier le modèle
+	if template.Section == "localRecommendations" && applied.LocalRecommendations != nil {
+		recommendations := *applied.LocalRecommendations
+		recommendations.Recommendations = slices.Clone(recommendations.Recommendations)
+		applied.LocalRecommendations = &recommendations
+		applied.ComputeRecommendationDistances()
+	}
+
+	if !slices.Contains(models.ChangedSections(current, applied), template.Section) {
+		return current, nil
+	}
+
+	updates := applied.SectionUpdates([]string{template.Section})
+
+	if property.IsPublished() {
+		userID, _ := currentUserID(c)
+		if err := h.draftRepo.Save(ctx, property, updates, &userID); err != nil {
+			return nil, err
+		}
+		draft, err := h.draftRepo.FindByPropertyID(ctx, property.ID)
+		if err != nil {
+			return nil, err
+		}
+		return property.ApplyDraft(draft.Content), nil
+	}
+
+	if err := h.propertyRepo.Update(ctx, property.ID, updates); err != nil {
+		return nil, err
+	}
+
+	updatedProperty, err := h.propertyRepo.FindByID(ctx, property.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordRevision(c, h.revisionRepo, property, updatedProperty, models.PropertyRevision{Action: models.RevisionActionTemplate})
+	return updatedProperty, nil
+}
+
+// propagate applique le modèle modifié à une propriété liée, si son hôte peut toujours la modifier
+func (h *SectionTemplateHandler) propagate(c *gin.Context, template *models.SectionTemplate, propertyID primitive.ObjectID) error {
+	property, err := h.propertyRepo.FindByID(c.Request.Context(), propertyID)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := propertyAllows(c, h.authz, property, models.PropertyActionEdit)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errTemplateAccessLost
+	}
+
+	_, err = h.applySection(c, template, property)
+	return err
+}
+
+// templateContent retourne le contenu d'un modèle, repris de la section d'une propriété de
+// l'hôte (propertyID) ou lu depuis value ; nil si aucun des deux n'est fourni.
+// En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *SectionTemplateHandler) templateContent(c *gin.Context, section, propertyID string, value json.RawMessage) (*models.Property, bool) {
+	if propertyID != "" {
+		property, err := findProperty(c.Request.Context(), h.propertyRepo, propertyID)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				apierror.Abort(c, apierror.PropertyNotFound)
+			} else {
+				apierror.Internal(c, err)
+			}
+			return nil, false
+		}
+
+		if !authorizeProperty(c, h.authz, property, models.PropertyActionRead) {
+			return nil, false
+		}
+
+		current, ok := workingCopy(c, h.draftRepo, property)
+		if !ok {
+			return nil, false
+		}
+
+		content := current.SectionContent(section)
+		if !content.HasSection(section) {
+			apierror.Abort(c, apierror.TemplateContentInvalid)
+			return nil, false
+		}
+		return content, true
+	}
+
+	if len(value) == 0 {
+		return nil, true
+	}
+
+	content, err := models.ParseSectionContent(section, value)
+	if err != nil {
+		apierror.Abort(c, apierror.TemplateContentInvalid)
+		return nil, false
+	}
+	if err := content.ValidateLocations(); err != nil {
+		apierror.Abort(c, apierror.InvalidCoordinates)
+		return nil, false
+	}
+	return content, true
+}
+
+// loadOwnedTemplate charge le modèle désigné par le paramètre :id et vérifie que l'utilisateur
+// courant en est l'hôte. En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *SectionTemplateHandler) loadOwnedTemplate(c *gin.Context) (*models.SectionTemplate, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID)
+		return nil, false
+	}
+
+	template, err := h.templateRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.TemplateNotFound)
+		} else {
+			apierror.Internal(c, err)
+		}
+		return nil, false
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok || userID != template.HostID {
+		apierror.Abort(c, apierror.TemplateForbidden)
+		return nil, false
+	}
+
+	return template, true
+}
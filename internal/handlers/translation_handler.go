@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
+	"onestay-back/internal/i18n"
+	"onestay-back/internal/models"
+	"onestay-back/internal/rbac"
+	"onestay-back/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type TranslationHandler struct {
+	cfg          *config.Config
+	propertyRepo repository.PropertyStore
+	revisionRepo repository.PropertyRevisionStore
+	draftRepo    repository.PropertyDraftStore
+	authz        *rbac.Service
+}
+
+func NewTranslationHandler(cfg *config.Config, stores *repository.Stores, authz *rbac.Service) *TranslationHandler {
+	return &TranslationHandler{
+		cfg:          cfg,
+		propertyRepo: stores.Properties,
+		revisionRepo: stores.PropertyRevisions,
+		draftRepo:    stores.PropertyDrafts,
+		authz:        authz,
+	}
+}
+
+// GetTranslations liste les traductions d'une propriété et les champs traduisibles,
+// modifications en attente comprises
+func (h *TranslationHandler) GetTranslations(c *gin.Context) {
+	live, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+	property, ok := workingCopy(c, h.draftRepo, live)
+	if !ok {
+		return
+	}
+
+	fields := make([]gin.H, 0)
+	for _, field := range property.TranslatableFields() {
+		fields = append(fields, gin.H{
+			"section": field.Section,
+			"path":    field.Path,
+			"text":    *field.Value,
+		})
+	}
+
+	translations := property.Translations
+	if translations == nil {
+		translations = []models.PropertyTranslation{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"defaultLocale":    property.ContentLocale(h.cfg.DefaultLocale),
+		"supportedLocales": h.cfg.SupportedLocales,
+		"translations":     translations,
+		"fields":           fields,
+	})
+}
+
+// UpsertTranslation remplace les traductions d'une langue. Les chemins doivent désigner
+// des champs traduisibles existants ; un texte vide supprime la traduction du champ.
+// Sur une propriété publiée, la traduction est placée dans le brouillon.
+func (h *TranslationHandler) UpsertTranslation(c *gin.Context) {
+	live, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
+		return
+	}
+	property, ok := workingCopy(c, h.draftRepo, live)
+	if !ok {
+		return
+	}
+
+	locale, ok := parseSupportedLocale(c, h.cfg, c.Param("locale"))
+	if !ok {
+		return
+	}
+	if locale == property.ContentLocale(h.cfg.DefaultLocale) {
+		apierror.Abort(c, apierror.TranslationDefaultLocale)
+		return
+	}
+
+	var req models.UpsertTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	known := make(map[string]bool)
+	for _, field := range property.TranslatableFields() {
+		known[field.Path] = true
+	}
+
+	var unknown []string
+	translation := models.PropertyTranslation{Locale: locale, Fields: []models.TranslatedField{}}
+	for path, text := range req.Fields {
+		if !known[path] {
+			unknown = append(unknown, path)
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			translation.Fields = append(translation.Fields, models.TranslatedField{Path: path, Text: text})
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		apierror.AbortWithDetails(c, apierror.TranslationUnknownFields, unknown)
+		return
+	}
+	sort.Slice(translation.Fields, func(i, j int) bool {
+		return translation.Fields[i].Path < translation.Fields[j].Path
+	})
+
+	translations := slices.DeleteFunc(slices.Clone(property.Translations), func(t models.PropertyTranslation) bool {
+		return t.Locale == locale
+	})
+	translations = append(translations, translation)
+
+	after, ok := h.saveTranslations(c, live, property, translations)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Traduction enregistrée avec succès",
+		"translation":    translation,
+		"missing":        after.MissingTranslations(locale),
+		"pendingChanges": live.IsPublished(),
+	})
+}
+
+// DeleteTranslation supprime toutes les traductions d'une langue. Sur une propriété
+// publiée, la suppression est placée dans le brouillon.
+func (h *TranslationHandler) DeleteTranslation(c *gin.Context) {
+	live, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionEdit)
+	if !ok {
+		return
+	}
+	property, ok := workingCopy(c, h.draftRepo, live)
+	if !ok {
+		return
+	}
+
+	locale, ok := parseSupportedLocale(c, h.cfg, c.Param("locale"))
+	if !ok {
+		return
+	}
+	if property.Translation(locale) == nil {
+		apierror.Abort(c, apierror.TranslationNotFound)
+		return
+	}
+
+	translations := slices.DeleteFunc(slices.Clone(property.Translations), func(t models.PropertyTranslation) bool {
+		return t.Locale == locale
+	})
+
+	if _, ok := h.saveTranslations(c, live, property, translations); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Traduction supprimée avec succès",
+		"pendingChanges": live.IsPublished(),
+	})
+}
+
+// saveTranslations enregistre les traductions : dans le brouillon d'une propriété publiée,
+// en direct sinon, avec une révision. current est le contenu en cours d'édition ; la
+// propriété obtenue est retournée. En cas d'échec, la réponse d'erreur est déjà écrite.
+func (h *TranslationHandler) saveTranslations(c *gin.Context, live, current *models.Property, translations []models.PropertyTranslation) (*models.Property, bool) {
+	ctx := c.Request.Context()
+
+	after := *current
+	after.Translations = translations
+
+	if live.IsPublished() {
+		userID, _ := currentUserID(c)
+		if err := h.draftRepo.Save(ctx, live, bson.M{"translations": translations}, &userID); err != nil {
+			apierror.Internal(c, err)
+			return nil, false
+		}
+		return &after, true
+	}
+
+	if err := h.propertyRepo.SetTranslations(ctx, live.ID, translations); err != nil {
+		apierror.Internal(c, err)
+		return nil, false
+	}
+	recordRevision(c, h.revisionRepo, live, &after, models.PropertyRevision{Action: models.RevisionActionTranslations})
+	return &after, true
+}
+
+// GetMissingTranslations liste, pour chaque langue, les sections dont des champs renseignés
+// ne sont pas traduits. Par défaut toutes les langues supportées sont vérifiées ;
+// ?locale= restreint le rapport à une langue.
+func (h *TranslationHandler) GetMissingTranslations(c *gin.Context) {
+	live, ok := loadAuthorizedProperty(c, h.authz, h.propertyRepo, models.PropertyActionReview)
+	if !ok {
+		return
+	}
+	property, ok := workingCopy(c, h.draftRepo, live)
+	if !ok {
+		return
+	}
+
+	defaultLocale := property.ContentLocale(h.cfg.DefaultLocale)
+
+	var locales []string
+	if value := c.Query("locale"); value != "" {
+		locale, ok := parseSupportedLocale(c, h.cfg, value)
+		if !ok {
+			return
+		}
+		locales = []string{locale}
+	} else {
+		locales = append(locales, h.cfg.SupportedLocales...)
+		for _, translation := range property.Translations {
+			if !slices.Contains(locales, translation.Locale) {
+				locales = append(locales, translation.Locale)
+			}
+		}
+	}
+
+	report := make(map[string]gin.H)
+	for _, locale := range locales {
+		if locale == defaultLocale {
+			continue
+		}
+		missing := property.MissingTranslations(locale)
+		sections := make([]string, 0, len(missing))
+		count := 0
+		for section, paths := range missing {
+			sections = append(sections, section)
+			count += len(paths)
+		}
+		sort.Strings(sections)
+
+		report[locale] = gin.H{
+			"complete":      count == 0,
+			"missingCount":  count,
+			"sections":      sections,
+			"missingFields": missing,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"defaultLocale": defaultLocale,
+		"locales":       report,
+	})
+}
+
+// parseSupportedLocale normalise une langue et vérifie qu'elle fait partie des langues
+// supportées (SUPPORTED_LOCALES). En cas d'échec, la réponse d'erreur est déjà écrite.
+func parseSupportedLocale(c *gin.Context, cfg *config.Config, value string) (string, bool) {
+	locale, err := i18n.Normalize(value)
+	if err != nil {
+		apierror.Abort(c, apierror.LocaleInvalid)
+		return "", false
+	}
+	if !slices.Contains(cfg.SupportedLocales, locale) {
+		apierror.AbortWithDetails(c, apierror.LocaleUnsupported, cfg.SupportedLocales)
+		return "", false
+	}
+	return locale, true
+}
+
+// localizeProperty traduit les contenus selon ?lang= ou l'en-tête Accept-Language,
+// avec repli sur la langue par défaut de la propriété, et retourne la langue retenue
+func localizeProperty(c *gin.Context, cfg *config.Config, property *models.Property) string {
+	defaultLocale := property.ContentLocale(cfg.DefaultLocale)
+	locale := i18n.Negotiate(c.Query("lang"), c.GetHeader("Accept-Language"), property.Locales(cfg.DefaultLocale), defaultLocale)
+
+	property.Localize(locale)
+	c.Header("Content-Language", locale)
+	c.Header("Vary", "Accept-Language")
+	return locale
+}
@@ -0,0 +1,49 @@
+// Package i18n choisit la langue des contenus renvoyés aux voyageurs.
+// Les langues sont identifiées par leur code ISO 639-1 ("fr", "en", "es"...).
+package i18n
+
+import (
+	"errors"
+	"slices"
+
+	"golang.org/x/text/language"
+)
+
+var ErrInvalidLocale = errors.New("langue invalide (code ISO 639-1 attendu, ex. fr, en)")
+
+// Normalize ramène une étiquette de langue ("en-US", "EN", "pt_BR") à sa langue de base ("en", "pt")
+func Normalize(value string) (string, error) {
+	tag, err := language.Parse(value)
+	if err != nil {
+		return "", ErrInvalidLocale
+	}
+	base, confidence := tag.Base()
+	if confidence == language.No {
+		return "", ErrInvalidLocale
+	}
+	return base.String(), nil
+}
+
+// Negotiate choisit une langue parmi available. Un paramètre lang explicite l'emporte ;
+// sinon les préférences de l'en-tête Accept-Language sont parcourues par ordre de priorité.
+// Sans correspondance, fallback est retourné.
+func Negotiate(lang, acceptLanguage string, available []string, fallback string) string {
+	if lang != "" {
+		if locale, err := Normalize(lang); err == nil && slices.Contains(available, locale) {
+			return locale
+		}
+		return fallback
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return fallback
+	}
+	for _, tag := range tags {
+		base, confidence := tag.Base()
+		if confidence != language.No && slices.Contains(available, base.String()) {
+			return base.String()
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,67 @@
+// Package ical lit et écrit des calendriers iCalendar (RFC 5545).
+//
+// Seul le sous-ensemble utile à la synchronisation des disponibilités est pris
+// en charge : composants VEVENT, dates journée entière, fuseaux horaires (TZID),
+// durées et événements annulés. Les règles de récurrence (RRULE) sont ignorées,
+// les plateformes de location exportant des séjours ponctuels.
+package ical
+
+import (
+	"time"
+
+	// Base des fuseaux horaires embarquée, pour ne pas dépendre du système hôte
+	_ "time/tzdata"
+)
+
+// Statuts d'un événement
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event représente un événement VEVENT
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool   // DTSTART de type DATE : Start et End sont à minuit UTC
+	Status      string // TENTATIVE, CONFIRMED ou CANCELLED ; vide si non précisé
+}
+
+// IsCancelled indique si l'événement a été annulé
+func (e Event) IsCancelled() bool {
+	return e.Status == StatusCancelled
+}
+
+// Days retourne la période de nuits occupées par l'événement, sous forme de dates
+// à minuit UTC : [début, fin[. Pour un événement horaire, les dates sont celles du
+// fuseau de l'événement ; un événement tenant dans une seule journée bloque cette nuit.
+func (e Event) Days() (time.Time, time.Time) {
+	start := dateOf(e.Start)
+	end := dateOf(e.End)
+	if !end.After(start) {
+		end = start.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// Calendar représente un calendrier VCALENDAR
+type Calendar struct {
+	ProdID string
+	Name   string
+	Method string
+	Events []Event
+
+	// Skipped compte les événements ignorés à la lecture car mal formés (date ou durée
+	// illisible, ligne sans valeur...)
+	Skipped int
+}
+
+// dateOf retourne le jour calendaire de t (dans son propre fuseau) à minuit UTC
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
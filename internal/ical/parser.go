@@ -0,0 +1,376 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotCalendar     = errors.New("ical: aucun composant VCALENDAR")
+	ErrInvalidDate     = errors.New("ical: date invalide")
+	ErrInvalidDuration = errors.New("ical: durée invalide")
+	ErrMalformedLine   = errors.New("ical: ligne mal formée")
+)
+
+// contentLine représente une ligne "NOM;PARAM=valeur:VALEUR" dépliée
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse lit un calendrier iCalendar. Les heures flottantes (sans fuseau) et les
+// fuseaux inconnus sont interprétés en UTC. Un événement sans DTSTART est ignoré ;
+// un événement mal formé est ignoré et compté dans Calendar.Skipped, sans faire échouer
+// la lecture des autres.
+func Parse(r io.Reader) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cal     *Calendar
+		stack   []string
+		current *eventBuilder
+	)
+
+	for lineNo, raw := range lines {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		line, err := parseContentLine(raw)
+		if err != nil {
+			// Une ligne illisible n'invalide que l'événement qui la contient
+			if current != nil {
+				current.malformed = true
+				continue
+			}
+			return nil, fmt.Errorf("ligne %d: %w", lineNo+1, err)
+		}
+
+		switch line.name {
+		case "BEGIN":
+			component := strings.ToUpper(line.value)
+			stack = append(stack, component)
+			switch {
+			case component == "VCALENDAR" && cal == nil:
+				cal = &Calendar{}
+			case component == "VEVENT" && len(stack) == 2 && cal != nil:
+				current = &eventBuilder{}
+			}
+			continue
+		case "END":
+			if len(stack) == 0 {
+				continue
+			}
+			component := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if component == "VEVENT" && current != nil && len(stack) == 1 {
+				if event, ok, err := current.build(); err != nil || current.malformed {
+					cal.Skipped++
+				} else if ok {
+					cal.Events = append(cal.Events, event)
+				}
+				current = nil
+			}
+			continue
+		}
+
+		if len(stack) == 0 {
+			continue
+		}
+
+		// Propriétés du calendrier lui-même
+		if len(stack) == 1 && stack[0] == "VCALENDAR" && cal != nil {
+			switch line.name {
+			case "PRODID":
+				cal.ProdID = line.value
+			case "METHOD":
+				cal.Method = strings.ToUpper(line.value)
+			case "X-WR-CALNAME":
+				cal.Name = unescapeText(line.value)
+			}
+			continue
+		}
+
+		// Propriétés de l'événement courant (les VALARM imbriqués sont ignorés)
+		if current != nil && len(stack) == 2 && stack[1] == "VEVENT" {
+			current.add(line)
+		}
+	}
+
+	if cal == nil {
+		return nil, ErrNotCalendar
+	}
+
+	// Un calendrier METHOD:CANCEL annule tous les événements qu'il contient
+	if cal.Method == "CANCEL" {
+		for i := range cal.Events {
+			cal.Events[i].Status = StatusCancelled
+		}
+	}
+
+	return cal, nil
+}
+
+// unfold lit le flux et recolle les lignes repliées (continuation commençant par un espace ou une tabulation)
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) > 0 && (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += text[1:]
+			continue
+		}
+		lines = append(lines, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// parseContentLine découpe une ligne en nom, paramètres et valeur.
+// Les deux-points et points-virgules entre guillemets font partie du paramètre.
+func parseContentLine(raw string) (contentLine, error) {
+	inQuotes := false
+	colon := -1
+	for i, r := range raw {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return contentLine{}, ErrMalformedLine
+	}
+
+	head, value := raw[:colon], raw[colon+1:]
+	parts := splitParams(head)
+
+	line := contentLine{
+		name:   strings.ToUpper(strings.TrimSpace(parts[0])),
+		params: make(map[string]string, len(parts)-1),
+		value:  value,
+	}
+	for _, part := range parts[1:] {
+		key, val, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		line.params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return line, nil
+}
+
+// splitParams découpe "DTSTART;TZID=Europe/Paris;VALUE=DATE-TIME" sur les points-virgules hors guillemets
+func splitParams(head string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, r := range head {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ';' && !inQuotes:
+			parts = append(parts, head[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, head[start:])
+}
+
+// unescapeText décode les séquences d'échappement d'une valeur TEXT
+func unescapeText(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// parseDateTime interprète une valeur DATE ou DATE-TIME selon ses paramètres.
+// Le booléen indique une date journée entière.
+func parseDateTime(line contentLine) (time.Time, bool, error) {
+	value := strings.TrimSpace(line.value)
+
+	if strings.EqualFold(line.params["VALUE"], "DATE") || len(value) == 8 {
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %q", ErrInvalidDate, value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %q", ErrInvalidDate, value)
+		}
+		return t, false, nil
+	}
+
+	loc := time.UTC
+	if tzid := line.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %q", ErrInvalidDate, value)
+	}
+	return t, false, nil
+}
+
+// parseDuration interprète une durée RFC 5545 (P1D, PT2H30M, P1W, -PT15M...)
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign = -1
+		value = value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+	}
+	value = value[1:]
+
+	var (
+		total    time.Duration
+		number   string
+		timePart bool
+	)
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			timePart = true
+			continue
+		}
+
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+		}
+		number = ""
+
+		unit := time.Duration(n)
+		switch {
+		case r == 'W' && !timePart:
+			total += unit * 7 * 24 * time.Hour
+		case r == 'D' && !timePart:
+			total += unit * 24 * time.Hour
+		case r == 'H' && timePart:
+			total += unit * time.Hour
+		case r == 'M' && timePart:
+			total += unit * time.Minute
+		case r == 'S' && timePart:
+			total += unit * time.Second
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+	}
+
+	return sign * total, nil
+}
+
+// eventBuilder accumule les propriétés d'un VEVENT en cours de lecture
+type eventBuilder struct {
+	event     Event
+	start     *contentLine
+	end       *contentLine
+	duration  string
+	malformed bool
+}
+
+func (b *eventBuilder) add(line contentLine) {
+	switch line.name {
+	case "UID":
+		b.event.UID = line.value
+	case "SUMMARY":
+		b.event.Summary = unescapeText(line.value)
+	case "DESCRIPTION":
+		b.event.Description = unescapeText(line.value)
+	case "STATUS":
+		b.event.Status = strings.ToUpper(strings.TrimSpace(line.value))
+	case "DTSTART":
+		b.start = &line
+	case "DTEND":
+		b.end = &line
+	case "DURATION":
+		b.duration = line.value
+	}
+}
+
+// build finalise l'événement. Sans DTEND ni DURATION, un événement journée entière
+// dure un jour et un événement horaire est instantané (RFC 5545 §3.6.1).
+func (b *eventBuilder) build() (Event, bool, error) {
+	if b.start == nil {
+		return Event{}, false, nil
+	}
+
+	start, allDay, err := parseDateTime(*b.start)
+	if err != nil {
+		return Event{}, false, err
+	}
+	b.event.Start = start
+	b.event.AllDay = allDay
+
+	switch {
+	case b.end != nil:
+		end, _, err := parseDateTime(*b.end)
+		if err != nil {
+			return Event{}, false, err
+		}
+		b.event.End = end
+	case b.duration != "":
+		duration, err := parseDuration(b.duration)
+		if err != nil {
+			return Event{}, false, err
+		}
+		b.event.End = start.Add(duration)
+	case allDay:
+		b.event.End = start.AddDate(0, 0, 1)
+	default:
+		b.event.End = start
+	}
+
+	return b.event, true, nil
+}
@@ -0,0 +1,220 @@
+package ical
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseFixture(t *testing.T, name string) *Calendar {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ouverture de %s : %v", name, err)
+	}
+	defer f.Close()
+
+	cal, err := Parse(f)
+	if err != nil {
+		t.Fatalf("Parse(%s) : %v", name, err)
+	}
+	return cal
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func assertDays(t *testing.T, event Event, wantStart, wantEnd time.Time) {
+	t.Helper()
+
+	start, end := event.Days()
+	if !start.Equal(wantStart) || !end.Equal(wantEnd) {
+		t.Errorf("%s : Days() = [%s, %s[, attendu [%s, %s[", event.UID,
+			start.Format("2006-01-02"), end.Format("2006-01-02"),
+			wantStart.Format("2006-01-02"), wantEnd.Format("2006-01-02"))
+	}
+}
+
+func TestParseAllDayEvents(t *testing.T) {
+	cal := parseFixture(t, "airbnb.ics")
+
+	if cal.Name != "Appartement, Lyon" {
+		t.Errorf("Name = %q", cal.Name)
+	}
+	if len(cal.Events) != 2 {
+		t.Fatalf("%d événements, attendu 2 (l'événement sans DTSTART est ignoré)", len(cal.Events))
+	}
+
+	reserved := cal.Events[0]
+	if !reserved.AllDay {
+		t.Error("l'événement VALUE=DATE doit être journée entière")
+	}
+	if reserved.Summary != "Reserved" {
+		t.Errorf("Summary = %q, le SUMMARY du VALARM ne doit pas l'écraser", reserved.Summary)
+	}
+	if !strings.Contains(reserved.Description, "reservations/details/HMABCDEF12\nPhone") {
+		t.Errorf("Description mal dépliée ou mal décodée : %q", reserved.Description)
+	}
+	assertDays(t, reserved, date(2026, 3, 12), date(2026, 3, 15))
+
+	// Sans DTEND, un événement journée entière dure un jour
+	assertDays(t, cal.Events[1], date(2026, 4, 1), date(2026, 4, 2))
+}
+
+func TestParseTimeZones(t *testing.T) {
+	cal := parseFixture(t, "timezone.ics")
+
+	if len(cal.Events) != 4 {
+		t.Fatalf("%d événements, attendu 4", len(cal.Events))
+	}
+	events := make(map[string]Event, len(cal.Events))
+	for _, event := range cal.Events {
+		events[event.UID] = event
+	}
+
+	paris := events["paris-stay@booking.com"]
+	if want := time.Date(2026, 7, 10, 14, 0, 0, 0, time.UTC); !paris.Start.Equal(want) {
+		t.Errorf("Start = %s, attendu %s (16h heure de Paris)", paris.Start.UTC(), want)
+	}
+	assertDays(t, paris, date(2026, 7, 10), date(2026, 7, 14))
+
+	utc := events["utc-stay@booking.com"]
+	if want := time.Date(2026, 7, 23, 0, 0, 0, 0, time.UTC); !utc.End.Equal(want) {
+		t.Errorf("End = %s, attendu %s (DTSTART + DURATION)", utc.End, want)
+	}
+
+	// 22h à New York est déjà le lendemain en UTC : les jours suivent le fuseau de l'événement
+	assertDays(t, events["quoted-tz@booking.com"], date(2026, 8, 1), date(2026, 8, 2))
+
+	// Un fuseau inconnu est interprété en UTC plutôt que de faire échouer l'import
+	unknown := events["unknown-tz@booking.com"]
+	if want := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC); !unknown.Start.Equal(want) {
+		t.Errorf("Start = %s, attendu %s", unknown.Start, want)
+	}
+}
+
+func TestParseCancelledEvents(t *testing.T) {
+	cal := parseFixture(t, "cancelled.ics")
+
+	if len(cal.Events) != 2 {
+		t.Fatalf("%d événements, attendu 2", len(cal.Events))
+	}
+	if cal.Events[0].IsCancelled() {
+		t.Error("kept@vrbo.com ne doit pas être annulé")
+	}
+	if !cal.Events[1].IsCancelled() {
+		t.Error("dropped@vrbo.com doit être annulé (STATUS insensible à la casse)")
+	}
+
+	cancel := parseFixture(t, "method_cancel.ics")
+	if len(cancel.Events) != 1 || !cancel.Events[0].IsCancelled() {
+		t.Error("METHOD:CANCEL doit annuler tous les événements")
+	}
+}
+
+func TestParseRejectsNonCalendar(t *testing.T) {
+	if _, err := Parse(strings.NewReader("<html>Not found</html>")); err == nil {
+		t.Error("une page HTML ne doit pas être acceptée")
+	}
+	if _, err := Parse(strings.NewReader("BEGIN:VEVENT\r\nEND:VEVENT\r\n")); err != ErrNotCalendar {
+		t.Errorf("err = %v, attendu ErrNotCalendar", err)
+	}
+}
+
+func TestParseSkipsMalformedEvents(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:date-illisible",
+		"DTSTART;VALUE=DATE:2030-07-01",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:ligne-illisible",
+		"DTSTART;VALUE=DATE:20300701",
+		"LIGNE SANS VALEUR",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:valide",
+		"DTSTART;VALUE=DATE:20300710",
+		"DTEND;VALUE=DATE:20300712",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	cal, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse : %v", err)
+	}
+	if len(cal.Events) != 1 || cal.Events[0].UID != "valide" {
+		t.Errorf("événements %v, attendu le seul événement valide", cal.Events)
+	}
+	if cal.Skipped != 2 {
+		t.Errorf("Skipped = %d, attendu 2", cal.Skipped)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"P1D":     24 * time.Hour,
+		"P1W":     7 * 24 * time.Hour,
+		"PT2H30M": 2*time.Hour + 30*time.Minute,
+		"P1DT12H": 36 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+		"+PT10S":  10 * time.Second,
+	}
+	for value, want := range tests {
+		got, err := parseDuration(value)
+		if err != nil || got != want {
+			t.Errorf("parseDuration(%q) = %s, %v ; attendu %s", value, got, err, want)
+		}
+	}
+
+	for _, value := range []string{"", "P", "1D", "PT1D", "P1H", "P1"} {
+		if _, err := parseDuration(value); err == nil {
+			t.Errorf("parseDuration(%q) doit échouer", value)
+		}
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	long := strings.Repeat("Séjour réservé, ", 10)
+	in := &Calendar{
+		ProdID: "-//OneStay//Calendrier//FR",
+		Name:   "Studio; centre",
+		Events: []Event{
+			{UID: "a@onestay", Summary: long, Start: date(2026, 5, 1), End: date(2026, 5, 4), AllDay: true},
+			{UID: "b@onestay", Summary: "Ménage", Start: time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC), End: time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC), Status: StatusConfirmed},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, in); err != nil {
+		t.Fatalf("Write : %v", err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("ligne de %d octets non repliée : %q", len(line), line)
+		}
+	}
+
+	out, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse : %v", err)
+	}
+	if out.Name != in.Name || len(out.Events) != 2 {
+		t.Fatalf("calendrier relu incorrect : %+v", out)
+	}
+	if out.Events[0].Summary != long {
+		t.Errorf("Summary relu = %q", out.Events[0].Summary)
+	}
+	assertDays(t, out.Events[0], date(2026, 5, 1), date(2026, 5, 4))
+	if !out.Events[1].Start.Equal(in.Events[1].Start) || out.Events[1].Status != StatusConfirmed {
+		t.Errorf("événement horaire relu incorrect : %+v", out.Events[1])
+	}
+}
@@ -0,0 +1,82 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets est la longueur maximale d'une ligne avant repliement (RFC 5545 §3.1)
+const maxLineOctets = 75
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// Write sérialise le calendrier au format iCalendar (lignes CRLF, repliées à 75 octets).
+// Les dates des événements horaires sont écrites en UTC.
+func Write(w io.Writer, cal *Calendar) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+cal.ProdID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	if cal.Method != "" {
+		writeLine(bw, "METHOD:"+cal.Method)
+	}
+	if cal.Name != "" {
+		writeLine(bw, "X-WR-CALNAME:"+textEscaper.Replace(cal.Name))
+	}
+
+	for _, event := range cal.Events {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+event.UID)
+		writeLine(bw, "DTSTAMP:"+stamp)
+		if event.AllDay {
+			writeLine(bw, "DTSTART;VALUE=DATE:"+event.Start.Format("20060102"))
+			writeLine(bw, "DTEND;VALUE=DATE:"+event.End.Format("20060102"))
+		} else {
+			writeLine(bw, "DTSTART:"+event.Start.UTC().Format("20060102T150405Z"))
+			writeLine(bw, "DTEND:"+event.End.UTC().Format("20060102T150405Z"))
+		}
+		if event.Summary != "" {
+			writeLine(bw, "SUMMARY:"+textEscaper.Replace(event.Summary))
+		}
+		if event.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+textEscaper.Replace(event.Description))
+		}
+		if event.Status != "" {
+			writeLine(bw, "STATUS:"+event.Status)
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// writeLine écrit une ligne terminée par CRLF en la repliant sans couper de caractère UTF-8
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// La ligne de continuation commence par un espace qui compte dans la limite
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
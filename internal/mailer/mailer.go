@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"log"
+
+	"onestay-back/internal/config"
+)
+
+// Message représente un email à envoyer
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender est implémenté par chaque moyen d'envoi d'emails
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New construit le Sender correspondant à MAIL_DRIVER ("smtp" ou "log")
+func New(cfg *config.Config) Sender {
+	switch cfg.MailDriver {
+	case "smtp":
+		return NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	case "log":
+		return NewLogSender(cfg.MailLogFile)
+	default:
+		log.Printf("Warning: unknown MAIL_DRIVER %q, emails will only be logged", cfg.MailDriver)
+		return NewLogSender(cfg.MailLogFile)
+	}
+}
@@ -0,0 +1,164 @@
+// Package media prépare les photos envoyées par les hôtes : vérification du type réel,
+// redressement selon l'orientation EXIF, suppression des métadonnées et déclinaisons
+// redimensionnées.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+
+	// Décodeur WebP enregistré auprès de image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedType = errors.New("format d'image non supporté (JPEG, PNG ou WebP attendu)")
+	ErrInvalidImage    = errors.New("image illisible ou corrompue")
+	ErrTooManyPixels   = errors.New("image trop grande (50 mégapixels maximum)")
+)
+
+// MaxPixels protège contre les images compressées démesurées (bombes de décompression)
+const MaxPixels = 50_000_000
+
+const jpegQuality = 85
+
+// Variant décrit une déclinaison générée pour chaque photo
+type Variant struct {
+	Name    string
+	MaxSize int // Plus grand côté, en pixels
+}
+
+// Variants sont générées de la plus grande à la plus petite ; "large" remplace l'original,
+// qui n'est jamais conservé tel quel (il peut contenir la position GPS de la prise de vue)
+var Variants = []Variant{
+	{Name: "large", MaxSize: 2048},
+	{Name: "medium", MaxSize: 1024},
+	{Name: "thumbnail", MaxSize: 320},
+}
+
+// Output représente une déclinaison encodée, prête à être stockée
+type Output struct {
+	Variant     string
+	ContentType string
+	Extension   string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// allowedTypes liste les types MIME acceptés en entrée
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// Sniff détermine le type réel du fichier à partir de son contenu, sans se fier
+// au nom ni au Content-Type annoncés par le client
+func Sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return "", ErrUnsupportedType
+	}
+	return contentType, nil
+}
+
+// Process décode l'image, la redresse et génère toutes les déclinaisons. Les images
+// réencodées ne contiennent plus aucune métadonnée (EXIF, XMP, profils...). Les PNG
+// restent en PNG pour conserver la transparence ; le reste est converti en JPEG.
+func Process(data []byte) ([]Output, error) {
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	keepPNG := contentType == "image/png"
+	outputs := make([]Output, 0, len(Variants))
+
+	// Chaque déclinaison est calculée depuis la précédente : le redressement
+	// n'est appliqué qu'une fois, sur l'image déjà réduite
+	current := src
+	for i, variant := range Variants {
+		current = fit(current, variant.MaxSize, !keepPNG)
+		if i == 0 {
+			current = orient(current, orientation)
+		}
+
+		output := Output{
+			Variant: variant.Name,
+			Width:   current.Bounds().Dx(),
+			Height:  current.Bounds().Dy(),
+		}
+
+		var buf bytes.Buffer
+		if keepPNG {
+			err = png.Encode(&buf, current)
+			output.ContentType, output.Extension = "image/png", ".png"
+		} else {
+			err = jpeg.Encode(&buf, current, &jpeg.Options{Quality: jpegQuality})
+			output.ContentType, output.Extension = "image/jpeg", ".jpg"
+		}
+		if err != nil {
+			return nil, err
+		}
+		output.Data = buf.Bytes()
+
+		outputs = append(outputs, output)
+	}
+
+	return outputs, nil
+}
+
+// fit réduit l'image pour que son plus grand côté ne dépasse pas maxSize (jamais d'agrandissement).
+// Pour une sortie JPEG, la transparence est aplatie sur fond blanc.
+func fit(src image.Image, maxSize int, opaque bool) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			height = max(1, height*maxSize/width)
+			width = maxSize
+		} else {
+			width = max(1, width*maxSize/height)
+			height = maxSize
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if opaque {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	}
+
+	if width == bounds.Dx() && height == bounds.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	}
+	return dst
+}
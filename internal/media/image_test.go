@@ -0,0 +1,180 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("lecture de %s : %v", name, err)
+	}
+	return data
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+		wantErr error
+	}{
+		{"landscape.jpg", "image/jpeg", nil},
+		{"transparent.png", "image/png", nil},
+		{"pixel.webp", "image/webp", nil},
+		{"header.gif", "", ErrUnsupportedType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := Sniff(readFixture(t, tt.fixture))
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Sniff = %q, %v ; attendu %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	tests := []struct {
+		fixture string
+		wantErr error
+	}{
+		{"64mpx-header.png", ErrTooManyPixels},
+		{"truncated.jpg", ErrInvalidImage},
+		{"header.gif", ErrUnsupportedType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			if _, err := Process(readFixture(t, tt.fixture)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Process : %v, attendu %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExifOrientation(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    int
+	}{
+		{"landscape.jpg", 1},
+		{"orientation6.jpg", 6},
+		{"truncated.jpg", 1},
+		{"transparent.png", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			if got := exifOrientation(readFixture(t, tt.fixture)); got != tt.want {
+				t.Errorf("exifOrientation = %d, attendu %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// La photo 40x20 (gauche rouge, droite bleue) marquée Orientation 6 doit ressortir
+// en 20x40, rouge en haut, sans segment EXIF
+func TestProcessAppliesOrientation(t *testing.T) {
+	outputs, err := Process(readFixture(t, "orientation6.jpg"))
+	if err != nil {
+		t.Fatalf("Process : %v", err)
+	}
+
+	large := outputs[0]
+	if large.Width != 20 || large.Height != 40 {
+		t.Fatalf("large : %dx%d, attendu 20x40", large.Width, large.Height)
+	}
+	if exifOrientation(large.Data) != 1 || bytes.Contains(large.Data, []byte("Exif\x00\x00")) {
+		t.Error("les métadonnées EXIF sont conservées")
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(large.Data))
+	if err != nil {
+		t.Fatalf("décodage : %v", err)
+	}
+	if !reddish(img.At(10, 5)) || reddish(img.At(10, 35)) {
+		t.Errorf("image mal redressée : haut %v, bas %v", img.At(10, 5), img.At(10, 35))
+	}
+}
+
+func reddish(c color.Color) bool {
+	r, _, b, _ := c.RGBA()
+	return r > b
+}
+
+func TestProcessVariantSizes(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		encode        func(*bytes.Buffer, image.Image) error
+		wantType      string
+		want          [][2]int // large, medium, thumbnail
+	}{
+		{
+			name: "paysage réduit", width: 3000, height: 1500,
+			encode:   func(w *bytes.Buffer, m image.Image) error { return jpeg.Encode(w, m, nil) },
+			wantType: "image/jpeg",
+			want:     [][2]int{{2048, 1024}, {1024, 512}, {320, 160}},
+		},
+		{
+			name: "portrait PNG", width: 600, height: 1200,
+			encode:   func(w *bytes.Buffer, m image.Image) error { return png.Encode(w, m) },
+			wantType: "image/png",
+			want:     [][2]int{{600, 1200}, {512, 1024}, {160, 320}},
+		},
+		{
+			name: "petite image jamais agrandie", width: 100, height: 80,
+			encode:   func(w *bytes.Buffer, m image.Image) error { return jpeg.Encode(w, m, nil) },
+			wantType: "image/jpeg",
+			want:     [][2]int{{100, 80}, {100, 80}, {100, 80}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.encode(&buf, image.NewGray(image.Rect(0, 0, tt.width, tt.height))); err != nil {
+				t.Fatal(err)
+			}
+
+			outputs, err := Process(buf.Bytes())
+			if err != nil {
+				t.Fatalf("Process : %v", err)
+			}
+			if len(outputs) != len(Variants) {
+				t.Fatalf("%d déclinaisons, attendu %d", len(outputs), len(Variants))
+			}
+			for i, output := range outputs {
+				if output.Variant != Variants[i].Name || output.ContentType != tt.wantType {
+					t.Errorf("déclinaison %d : %s %s, attendu %s %s", i, output.Variant, output.ContentType, Variants[i].Name, tt.wantType)
+				}
+				if output.Width != tt.want[i][0] || output.Height != tt.want[i][1] {
+					t.Errorf("%s : %dx%d, attendu %dx%d", output.Variant, output.Width, output.Height, tt.want[i][0], tt.want[i][1])
+				}
+			}
+		})
+	}
+}
+
+func TestProcessConvertsWebPToJPEG(t *testing.T) {
+	outputs, err := Process(readFixture(t, "pixel.webp"))
+	if err != nil {
+		t.Fatalf("Process : %v", err)
+	}
+	for _, output := range outputs {
+		if output.ContentType != "image/jpeg" || output.Width != 1 || output.Height != 1 {
+			t.Errorf("%s : %s %dx%d, attendu image/jpeg 1x1", output.Variant, output.ContentType, output.Width, output.Height)
+		}
+	}
+}
@@ -1,54 +1,47 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository"
 	"onestay-back/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(tokens *utils.TokenService, sessionRepo repository.SessionStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var tokenString string
-		
-		// Récupérer le header Authorization (format standard: "Bearer <token>")
-		authHeader := c.GetHeader("Authorization")
-		
-		if authHeader != "" {
-			// Extraire le token du header "Bearer <token>"
-			authHeader = strings.TrimSpace(authHeader)
-			parts := strings.SplitN(authHeader, " ", 2)
-			
-			if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
-				tokenString = strings.TrimSpace(parts[1])
-			}
-		}
-		
-		// Si pas trouvé dans Authorization, vérifier le header "Bearer" directement (Bruno)
+		tokenString := extractToken(c)
+
 		if tokenString == "" {
-			bearerHeader := c.GetHeader("Bearer")
-			if bearerHeader != "" {
-				tokenString = strings.TrimSpace(bearerHeader)
-			}
+			apierror.Abort(c, apierror.TokenMissing)
+			return
 		}
-		
-		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token manquant",
-			})
-			c.Abort()
+
+		claims, err := tokens.Validate(tokenString)
+		if err != nil {
+			apierror.Abort(c, apierror.TokenInvalid)
 			return
 		}
 
-		claims, err := utils.ValidateToken(tokenString)
+		// Vérifier que la session rattachée au token n'a pas été révoquée
+		sessionID, err := primitive.ObjectIDFromHex(claims.SessionID)
+		if err != nil {
+			apierror.Abort(c, apierror.SessionInvalid)
+			return
+		}
+
+		session, err := sessionRepo.FindActive(c.Request.Context(), sessionID)
+		if err == mongo.ErrNoDocuments {
+			apierror.Abort(c, apierror.SessionInvalid)
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token invalide ou expiré",
-				"details": err.Error(),
-			})
-			c.Abort()
+			apierror.Internal(c, err)
 			return
 		}
 
@@ -56,7 +49,68 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role_id", claims.RoleID)
+		c.Set("session_id", sessionID)
+		setOrganization(c, session)
+
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware renseigne l'utilisateur dans le contexte si un token valide est fourni,
+// sans bloquer les requêtes anonymes (lectures publiques)
+func OptionalAuthMiddleware(tokens *utils.TokenService, sessionRepo repository.SessionStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := extractToken(c)
+		if tokenString == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := tokens.Validate(tokenString)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		sessionID, err := primitive.ObjectIDFromHex(claims.SessionID)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		session, err := sessionRepo.FindActive(c.Request.Context(), sessionID)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Set("email", claims.Email)
+		c.Set("role_id", claims.RoleID)
+		c.Set("session_id", sessionID)
+		setOrganization(c, session)
 
 		c.Next()
 	}
 }
+
+// setOrganization renseigne l'organisation pour laquelle agit la session, s'il y en a une
+func setOrganization(c *gin.Context, session *models.Session) {
+	if session.OrganizationID != nil {
+		c.Set("organization_id", *session.OrganizationID)
+	}
+}
+
+// extractToken récupère le token depuis le header Authorization ("Bearer <token>")
+// ou, à défaut, depuis le header "Bearer" directement (Bruno)
+func extractToken(c *gin.Context) string {
+	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+	if authHeader != "" {
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+
+	return strings.TrimSpace(c.GetHeader("Bearer"))
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rawBodyKey conserve le corps d'origine pour qu'une route puisse relever la limite globale
+const rawBodyKey = "raw_body"
+
+// BodyLimit limite la taille du corps des requêtes ; au-delà, la lecture échoue avec une
+// *http.MaxBytesError. Appliqué sur une route après la limite globale, il la remplace.
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := c.Request.Body
+		if raw, exists := c.Get(rawBodyKey); exists {
+			body = raw.(io.ReadCloser)
+		} else {
+			c.Set(rawBodyKey, body)
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, body, limit)
+		c.Next()
+	}
+}
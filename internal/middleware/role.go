@@ -1,70 +1,36 @@
 package middleware
 
 import (
-	"net/http"
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/rbac"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func RequireAdmin() gin.HandlerFunc {
+// RequirePermission vérifie que le rôle de l'utilisateur possède toutes les permissions demandées
+func RequirePermission(authz *rbac.Service, permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		roleID, exists := c.Get("role_id")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Rôle non trouvé",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthenticated)
 			return
 		}
 
 		roleIDStr, ok := roleID.(string)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la vérification du rôle",
-			})
-			c.Abort()
+			apierror.Internal(c, nil)
 			return
 		}
 
-		// Vérifier que le rôle est admin (ID: "3") ou superadmin (ID: "4")
-		if roleIDStr != "3" && roleIDStr != "4" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Accès refusé. Seuls les administrateurs peuvent accéder à cette ressource",
-			})
-			c.Abort()
+		allowed, err := authz.HasPermissions(c.Request.Context(), roleIDStr, permissions...)
+		if err != nil && err != mongo.ErrNoDocuments {
+			apierror.Internal(c, err)
 			return
 		}
 
-		c.Next()
-	}
-}
-
-func RequireSuperAdmin() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		roleID, exists := c.Get("role_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Rôle non trouvé",
-			})
-			c.Abort()
-			return
-		}
-
-		roleIDStr, ok := roleID.(string)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Erreur lors de la vérification du rôle",
-			})
-			c.Abort()
-			return
-		}
-
-		// Vérifier que le rôle est superadmin (ID: "4")
-		if roleIDStr != "4" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Accès refusé. Seuls les super administrateurs peuvent accéder à cette ressource",
-			})
-			c.Abort()
+		if !allowed {
+			apierror.AbortWithDetails(c, apierror.Forbidden, gin.H{"permissions": permissions})
 			return
 		}
 
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Origines d'une période bloquée
+const (
+	CalendarBlockSourceManual = "manual"
+	CalendarBlockSourceICal   = "ical"
+)
+
+// CalendarBlock représente une période indisponible hors réservation : blocage manuel
+// de l'hôte ou événement importé d'un calendrier externe
+type CalendarBlock struct {
+	ID          primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	PropertyID  primitive.ObjectID  `json:"propertyId" bson:"propertyId"`
+	Source      string              `json:"source" bson:"source"`
+	FeedID      *primitive.ObjectID `json:"feedId,omitempty" bson:"feedId,omitempty"`
+	ExternalUID string              `json:"externalUid,omitempty" bson:"externalUid,omitempty"`
+	Summary     string              `json:"summary,omitempty" bson:"summary,omitempty"`
+	StartDate   time.Time           `json:"startDate" bson:"startDate"` // Minuit UTC de la première nuit bloquée
+	EndDate     time.Time           `json:"endDate" bson:"endDate"`     // Minuit UTC du jour de libération
+	CreatedBy   *primitive.ObjectID `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Résultat de la dernière synchronisation d'un calendrier externe
+const (
+	CalendarFeedSyncOK    = "ok"
+	CalendarFeedSyncError = "error"
+)
+
+// CalendarFeed représente un calendrier iCal externe (Airbnb, Booking...) importé périodiquement
+type CalendarFeed struct {
+	ID             primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	PropertyID     primitive.ObjectID `json:"propertyId" bson:"propertyId"`
+	Name           string             `json:"name" bson:"name"`
+	URL            string             `json:"url" bson:"url"`
+	LastSyncedAt   *time.Time         `json:"lastSyncedAt,omitempty" bson:"lastSyncedAt,omitempty"`
+	LastSyncStatus string             `json:"lastSyncStatus,omitempty" bson:"lastSyncStatus,omitempty"`
+	LastSyncError  string             `json:"lastSyncError,omitempty" bson:"lastSyncError,omitempty"`
+	EventCount     int                `json:"eventCount" bson:"eventCount"`
+	CreatedBy      primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// CreateCalendarBlockRequest représente la requête de blocage manuel de dates
+type CreateCalendarBlockRequest struct {
+	StartDate string `json:"startDate" binding:"required"` // Format "YYYY-MM-DD"
+	EndDate   string `json:"endDate" binding:"required"`   // Format "YYYY-MM-DD", exclu
+	Summary   string `json:"summary,omitempty"`
+}
+
+// CreateCalendarFeedRequest représente la requête d'ajout d'un calendrier externe
+type CreateCalendarFeedRequest struct {
+	Name string `json:"name" binding:"required"`
+	URL  string `json:"url" binding:"required,url"`
+}
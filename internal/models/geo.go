@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusMeters est le rayon moyen de la Terre utilisé pour les distances à vol d'oiseau
+const earthRadiusMeters = 6371008.8
+
+var ErrInvalidCoordinates = errors.New("coordonnées invalides : latitude entre -90 et 90, longitude entre -180 et 180")
+
+// GeoPoint représente un point GeoJSON. Attention à l'ordre GeoJSON : [longitude, latitude].
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type" binding:"omitempty,eq=Point"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+// NewGeoPoint crée un point à partir d'une latitude et d'une longitude
+func NewGeoPoint(lat, lng float64) *GeoPoint {
+	return &GeoPoint{Type: "Point", Coordinates: []float64{lng, lat}}
+}
+
+// ParseLatLng lit un point au format "latitude,longitude"
+func ParseLatLng(value string) (*GeoPoint, error) {
+	latStr, lngStr, found := strings.Cut(value, ",")
+	if !found {
+		return nil, ErrInvalidCoordinates
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return nil, ErrInvalidCoordinates
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+	if err != nil {
+		return nil, ErrInvalidCoordinates
+	}
+
+	point := NewGeoPoint(lat, lng)
+	if err := point.Validate(); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
+
+func (p *GeoPoint) Lat() float64 { return p.Coordinates[1] }
+func (p *GeoPoint) Lng() float64 { return p.Coordinates[0] }
+
+// Validate vérifie que le point est un Point GeoJSON valide (MongoDB refuse sinon l'indexation).
+// Un type vide est complété en "Point".
+func (p *GeoPoint) Validate() error {
+	if p.Type == "" {
+		p.Type = "Point"
+	}
+	if p.Type != "Point" || len(p.Coordinates) != 2 {
+		return ErrInvalidCoordinates
+	}
+	if math.Abs(p.Lat()) > 90 || math.Abs(p.Lng()) > 180 {
+		return ErrInvalidCoordinates
+	}
+	return nil
+}
+
+// DistanceTo retourne la distance à vol d'oiseau en mètres (formule de haversine)
+func (p *GeoPoint) DistanceTo(other *GeoPoint) float64 {
+	lat1 := p.Lat() * math.Pi / 180
+	lat2 := other.Lat() * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (other.Lng() - p.Lng()) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// FormatDistance formate une distance pour l'affichage ("350 m", "1,2 km", "15 km")
+func FormatDistance(meters float64) string {
+	switch {
+	case meters < 1000:
+		return fmt.Sprintf("%d m", int(math.Round(meters/10)*10))
+	case meters < 10000:
+		return strings.Replace(fmt.Sprintf("%.1f km", meters/1000), ".", ",", 1)
+	default:
+		return fmt.Sprintf("%d km", int(math.Round(meters/1000)))
+	}
+}
+
+// ValidateLocations vérifie les coordonnées de la propriété et de ses recommandations
+func (p *Property) ValidateLocations() error {
+	if p.Location != nil {
+		if err := p.Location.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.LocalRecommendations != nil {
+		for i := range p.LocalRecommendations.Recommendations {
+			if location := p.LocalRecommendations.Recommendations[i].Location; location != nil {
+				if err := location.Validate(); err != nil {
+					return fmt.Errorf("recommandation %q : %w", p.LocalRecommendations.Recommendations[i].Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ComputeRecommendationDistances calcule la distance entre la propriété et chaque
+// recommandation géolocalisée. Les recommandations sans coordonnées conservent
+// la distance saisie à la main.
+func (p *Property) ComputeRecommendationDistances() {
+	if p.LocalRecommendations == nil {
+		return
+	}
+
+	for i := range p.LocalRecommendations.Recommendations {
+		rec := &p.LocalRecommendations.Recommendations[i]
+		rec.DistanceMeters = nil
+		if p.Location == nil || rec.Location == nil {
+			continue
+		}
+
+		distance := math.Round(p.Location.DistanceTo(rec.Location))
+		rec.DistanceMeters = &distance
+		rec.Distance = FormatDistance(distance)
+	}
+}
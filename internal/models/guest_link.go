@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GuestLink représente un lien d'accès voyageur donnant accès aux informations
+// sensibles d'une propriété pendant la durée d'un séjour
+type GuestLink struct {
+	ID             primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	PropertyID     primitive.ObjectID `json:"propertyId" bson:"propertyId"`
+	CreatedBy      primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	Label          string             `json:"label,omitempty" bson:"label,omitempty"` // Ex: nom du voyageur
+	TokenHash      string             `json:"-" bson:"tokenHash"`
+	PinHash        string             `json:"-" bson:"pinHash,omitempty"`
+	HasPin         bool               `json:"hasPin" bson:"hasPin"`
+	ValidFrom      time.Time          `json:"validFrom" bson:"validFrom"`
+	ValidUntil     time.Time          `json:"validUntil" bson:"validUntil"`
+	RevokedAt      *time.Time         `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+	UseCount       int                `json:"useCount" bson:"useCount"`
+	FailedAttempts int                `json:"failedAttempts" bson:"failedAttempts"`
+	LastUsedAt     *time.Time         `json:"lastUsedAt,omitempty" bson:"lastUsedAt,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// GuestLinkMaxFailedAttempts est le nombre de PIN erronés au-delà duquel le lien est bloqué
+const GuestLinkMaxFailedAttempts = 10
+
+// IsActiveAt indique si le lien est utilisable à l'instant donné
+func (l *GuestLink) IsActiveAt(t time.Time) bool {
+	return l.RevokedAt == nil &&
+		l.FailedAttempts < GuestLinkMaxFailedAttempts &&
+		!t.Before(l.ValidFrom) &&
+		t.Before(l.ValidUntil)
+}
+
+// GuestLinkAccess représente une utilisation (réussie ou non) d'un lien voyageur
+type GuestLinkAccess struct {
+	ID         primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	LinkID     primitive.ObjectID `json:"linkId" bson:"linkId"`
+	PropertyID primitive.ObjectID `json:"propertyId" bson:"propertyId"`
+	Success    bool               `json:"success" bson:"success"`
+	Reason     string             `json:"reason,omitempty" bson:"reason,omitempty"` // not_started, expired, revoked, locked, pin_required, invalid_pin
+	IP         string             `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent  string             `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	AccessedAt time.Time          `json:"accessedAt" bson:"accessedAt"`
+}
+
+// GuestPinRequest porte le PIN d'un lien voyageur dans le corps d'un POST (JSON ou formulaire).
+// Le PIN n'est jamais lu dans l'URL, qui finit dans les journaux et l'historique du navigateur.
+type GuestPinRequest struct {
+	Pin string `json:"pin" form:"pin"`
+}
+
+// CreateGuestLinkRequest représente la requête de création d'un lien voyageur
+type CreateGuestLinkRequest struct {
+	Label      string    `json:"label,omitempty"`
+	ValidFrom  time.Time `json:"validFrom" binding:"required"`
+	ValidUntil time.Time `json:"validUntil" binding:"required"`
+	Pin        string    `json:"pin,omitempty" binding:"omitempty,numeric,min=4,max=8"`
+}
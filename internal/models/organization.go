@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Rôles d'un utilisateur dans une organisation (agence de gestion locative)
+const (
+	OrganizationRoleOwner  = "owner"  // Gère l'organisation, ses membres et la supprime
+	OrganizationRoleAdmin  = "admin"  // Gère les membres et le portefeuille de propriétés
+	OrganizationRoleMember = "member" // Crée et modifie les propriétés de l'organisation
+	OrganizationRoleViewer = "viewer" // Consulte les propriétés de l'organisation
+)
+
+// OrganizationPropertyRoles donne le rôle dont dispose un membre de l'organisation sur
+// chacune des propriétés qu'elle détient
+var OrganizationPropertyRoles = map[string]string{
+	OrganizationRoleOwner:  PropertyRoleOwner,
+	OrganizationRoleAdmin:  PropertyRoleOwner,
+	OrganizationRoleMember: PropertyRoleEditor,
+	OrganizationRoleViewer: PropertyRoleViewer,
+}
+
+// OrganizationRoleCanManage indique si un rôle permet de gérer les membres et le portefeuille
+func OrganizationRoleCanManage(role string) bool {
+	return role == OrganizationRoleOwner || role == OrganizationRoleAdmin
+}
+
+// Organization est une agence qui détient un portefeuille de propriétés
+type Organization struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Slug      string             `json:"slug" bson:"slug"`
+	CreatedBy primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// OrganizationMember est l'appartenance d'un utilisateur à une organisation
+type OrganizationMember struct {
+	ID             primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	OrganizationID primitive.ObjectID `json:"organizationId" bson:"organizationId"`
+	UserID         primitive.ObjectID `json:"userId" bson:"userId"`
+	Role           string             `json:"role" bson:"role"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// OrganizationMemberView est un membre tel que présenté aux autres membres de l'organisation
+type OrganizationMemberView struct {
+	UserID    primitive.ObjectID `json:"userId"`
+	Role      string             `json:"role"`
+	Nom       string             `json:"nom"`
+	Prenom    string             `json:"prenom"`
+	Email     string             `json:"email"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// OrganizationMembership est une organisation dont l'utilisateur est membre, avec son rôle
+type OrganizationMembership struct {
+	Organization *Organization `json:"organization"`
+	Role         string        `json:"role"`
+}
+
+// OrganizationPropertyCount compte les propriétés d'une organisation
+type OrganizationPropertyCount struct {
+	Total     int64 `json:"total" bson:"total"`
+	Published int64 `json:"published" bson:"published"`
+}
+
+// OrganizationStats présente une organisation aux administrateurs de la plateforme
+type OrganizationStats struct {
+	Organization *Organization             `json:"organization"`
+	Members      int64                     `json:"members"`
+	Properties   OrganizationPropertyCount `json:"properties"`
+}
+
+// CreateOrganizationRequest crée une organisation dont l'utilisateur devient propriétaire
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=100"`
+}
+
+// UpdateOrganizationRequest renomme une organisation
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=100"`
+}
+
+// AddOrganizationMemberRequest ajoute un utilisateur inscrit à une organisation
+type AddOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner admin member viewer"`
+}
+
+// UpdateOrganizationMemberRequest change le rôle d'un membre
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member viewer"`
+}
+
+// SwitchOrganizationRequest choisit l'organisation pour laquelle agit la session ;
+// vide pour agir en son nom propre
+type SwitchOrganizationRequest struct {
+	OrganizationID string `json:"organizationId"`
+}
+
+// AssignPropertyOrganizationRequest confie une propriété à une organisation ;
+// vide pour la rendre à son hôte
+type AssignPropertyOrganizationRequest struct {
+	OrganizationID string `json:"organizationId"`
+}
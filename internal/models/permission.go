@@ -0,0 +1,54 @@
+package models
+
+// Permissions attribuables aux rôles
+const (
+	PermissionUsersRead          = "users:read"
+	PermissionUsersWrite         = "users:write"
+	PermissionRolesRead          = "roles:read"
+	PermissionRolesManage        = "roles:manage"
+	PermissionPropertiesModerate = "properties:moderate"
+)
+
+// Permission décrit une permission exposée par l'API
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AllPermissions liste toutes les permissions connues
+var AllPermissions = []Permission{
+	{PermissionUsersRead, "Consulter la liste des utilisateurs"},
+	{PermissionUsersWrite, "Créer, modifier et supprimer des utilisateurs"},
+	{PermissionRolesRead, "Consulter les rôles"},
+	{PermissionRolesManage, "Créer, modifier et supprimer des rôles"},
+	{PermissionPropertiesModerate, "Consulter et supprimer les logements de tous les hôtes"},
+}
+
+// IsValidPermission vérifie qu'une permission fait partie du catalogue
+func IsValidPermission(name string) bool {
+	for _, p := range AllPermissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRolePermissions donne les permissions initiales des rôles système
+var DefaultRolePermissions = map[string][]string{
+	RoleClient: {},
+	RoleLoueur: {},
+	RoleAdmin: {
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionRolesRead,
+		PermissionPropertiesModerate,
+	},
+	RoleSuperAdmin: {
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionRolesRead,
+		PermissionRolesManage,
+		PermissionPropertiesModerate,
+	},
+}
@@ -10,6 +10,7 @@ import (
 type Property struct {
 	ID                   primitive.ObjectID    `json:"_id" bson:"_id,omitempty"`
 	HostID               primitive.ObjectID    `json:"hostId" bson:"hostId" binding:"required"`
+	OrganizationID       *primitive.ObjectID   `json:"organizationId,omitempty" bson:"organizationId,omitempty"` // Organisation détentrice ; absente pour une propriété personnelle
 	Status               int                   `json:"status" bson:"status"` // 1 = brouillon, 2 = publié
 	Slug                 string                `json:"slug" bson:"slug" binding:"required"`
 	Name                 string                `json:"name" bson:"name" binding:"required"`
@@ -18,7 +19,11 @@ type Property struct {
 	City                 string                `json:"city" bson:"city" binding:"required"`
 	Country              string                `json:"country" bson:"country" binding:"required"`
 	ZipCode              string                `json:"zipCode,omitempty" bson:"zipCode,omitempty"`
+	Location             *GeoPoint             `json:"location,omitempty" bson:"location,omitempty"`
+	DistanceMeters       *float64              `json:"distanceMeters,omitempty" bson:"-"` // Calculée lors d'une recherche par proximité, non stockée
 	Images               []string              `json:"images,omitempty" bson:"images,omitempty"`
+	Photos               []PropertyImage       `json:"photos,omitempty" bson:"photos,omitempty"` // Photos envoyées via /images, dans l'ordre d'affichage
+	PhotosVersion        int64                 `json:"-" bson:"photosVersion,omitempty"` // Incrémenté à chaque modification des photos
 	CheckInOut           *CheckInOut            `json:"checkInOut" bson:"checkInOut" binding:"required"`
 	Wifi                 *Wifi                 `json:"wifi" bson:"wifi" binding:"required"`
 	Equipment            *Equipment            `json:"equipment" bson:"equipment" binding:"required"`
@@ -36,9 +41,12 @@ type Property struct {
 	Outdoor              *Outdoor             `json:"outdoor" bson:"outdoor" binding:"required"`
 	Neighborhood         *Neighborhood        `json:"neighborhood" bson:"neighborhood" binding:"required"`
 	Emergency            *Emergency            `json:"emergency" bson:"emergency" binding:"required"`
+	DefaultLocale        string                `json:"defaultLocale,omitempty" bson:"defaultLocale,omitempty"` // Langue des contenus saisis ; défaut : DEFAULT_LOCALE
+	Translations         []PropertyTranslation `json:"translations,omitempty" bson:"translations,omitempty"`
 	CreatedAt            time.Time             `json:"createdAt" bson:"createdAt"`
 	UpdatedAt            time.Time             `json:"updatedAt" bson:"updatedAt"`
 	PublishedAt          *time.Time            `json:"publishedAt,omitempty" bson:"publishedAt,omitempty"`
+	ScheduledPublishAt   *time.Time            `json:"scheduledPublishAt,omitempty" bson:"scheduledPublishAt,omitempty"` // Publication programmée (brouillon ou modifications en attente)
 }
 
 // CheckInOut représente les informations d'arrivée et de départ
@@ -154,6 +162,8 @@ type Recommendation struct {
 	Website     string  `json:"website,omitempty" bson:"website,omitempty"`
 	Distance    string  `json:"distance,omitempty" bson:"distance,omitempty"`
 	Rating      *float64 `json:"rating,omitempty" bson:"rating,omitempty"` // 1-5
+	Location       *GeoPoint `json:"location,omitempty" bson:"location,omitempty"`
+	DistanceMeters *float64  `json:"distanceMeters,omitempty" bson:"distanceMeters,omitempty"` // Calculée depuis la propriété si les deux sont géolocalisées
 }
 
 // LocalRecommendations représente les recommandations locales
@@ -312,7 +322,9 @@ type CreatePropertyRequest struct {
 	City        string   `json:"city" binding:"required"`
 	Country     string   `json:"country" binding:"required"`
 	ZipCode     string   `json:"zipCode,omitempty"`
+	Location    *GeoPoint `json:"location,omitempty"`
 	Images      []string `json:"images,omitempty"`
+	DefaultLocale string `json:"defaultLocale,omitempty"`
 	// Tous les sous-documents optionnels pour la création
 	CheckInOut           *CheckInOut            `json:"checkInOut,omitempty"`
 	Wifi                 *Wifi                 `json:"wifi,omitempty"`
@@ -341,7 +353,9 @@ type UpdatePropertyRequest struct {
 	City        string   `json:"city,omitempty"`
 	Country     string   `json:"country,omitempty"`
 	ZipCode     string   `json:"zipCode,omitempty"`
+	Location    *GeoPoint `json:"location,omitempty"`
 	Images      []string `json:"images,omitempty"`
+	DefaultLocale string `json:"defaultLocale,omitempty"`
 	// Tous les sous-documents optionnels
 	CheckInOut           *CheckInOut            `json:"checkInOut,omitempty"`
 	Wifi                 *Wifi                 `json:"wifi,omitempty"`
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Statuts d'une propriété
+const (
+	PropertyStatusDraft     = 1
+	PropertyStatusPublished = 2
+)
+
+// DraftSections sont les sections qu'une modification d'une propriété publiée place dans son
+// brouillon. Les photos ont leurs propres routes et restent modifiées en direct.
+var DraftSections = []string{
+	"general",
+	"checkInOut", "wifi", "equipment", "instructions", "rules", "contacts",
+	"localRecommendations", "parking", "transport", "security", "services",
+	"babyKids", "pets", "entertainment", "outdoor", "neighborhood", "emergency",
+	"translations",
+}
+
+// PropertyDraft contient les modifications en attente d'une propriété publiée.
+// Content est la version complète des sections de DraftSections, slug compris.
+type PropertyDraft struct {
+	ID         primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	PropertyID primitive.ObjectID  `json:"propertyId" bson:"propertyId"`
+	Content    *Property           `json:"-" bson:"content"`
+	AuthorID   *primitive.ObjectID `json:"authorId,omitempty" bson:"authorId,omitempty"` // Dernier auteur d'une modification
+	Version    int64               `json:"version" bson:"version"`                       // Incrémenté à chaque modification
+	CreatedAt  time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// PublishPropertyRequest programme éventuellement la publication à une date future
+type PublishPropertyRequest struct {
+	PublishAt *time.Time `json:"publishAt"`
+}
+
+// IsPublished indique si la propriété est visible des voyageurs
+func (p *Property) IsPublished() bool {
+	return p.Status == PropertyStatusPublished
+}
+
+// ApplyDraft retourne la propriété telle qu'elle sera une fois le brouillon publié
+func (p *Property) ApplyDraft(content *Property) *Property {
+	merged := *p
+	merged.Slug = content.Slug
+	merged.Name = content.Name
+	merged.Description = content.Description
+	merged.Address = content.Address
+	merged.City = content.City
+	merged.Country = content.Country
+	merged.ZipCode = content.ZipCode
+	merged.Location = content.Location
+	merged.Images = content.Images
+	merged.DefaultLocale = content.DefaultLocale
+	merged.CheckInOut = content.CheckInOut
+	merged.Wifi = content.Wifi
+	merged.Equipment = content.Equipment
+	merged.Instructions = content.Instructions
+	merged.Rules = content.Rules
+	merged.Contacts = content.Contacts
+	merged.LocalRecommendations = content.LocalRecommendations
+	merged.Parking = content.Parking
+	merged.Transport = content.Transport
+	merged.Security = content.Security
+	merged.Services = content.Services
+	merged.BabyKids = content.BabyKids
+	merged.Pets = content.Pets
+	merged.Entertainment = content.Entertainment
+	merged.Outdoor = content.Outdoor
+	merged.Neighborhood = content.Neighborhood
+	merged.Emergency = content.Emergency
+	merged.Translations = content.Translations
+	return &merged
+}
+
+// DraftUpdates retourne le contenu d'un brouillon prêt pour un $set sur la propriété
+func (p *Property) DraftUpdates() map[string]interface{} {
+	updates := p.SectionUpdates(DraftSections)
+	updates["slug"] = p.Slug
+	return updates
+}
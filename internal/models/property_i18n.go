@@ -0,0 +1,291 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TranslatedField est la traduction d'un champ texte, identifié par son chemin
+// ("description", "instructions.trash", "localRecommendations.<id>.description"...)
+type TranslatedField struct {
+	Path string `json:"path" bson:"path"`
+	Text string `json:"text" bson:"text"`
+}
+
+// PropertyTranslation regroupe les traductions d'une langue. Les chemins sont stockés
+// en liste plutôt qu'en clés de document : ils contiennent des points.
+type PropertyTranslation struct {
+	Locale string            `json:"locale" bson:"locale"`
+	Fields []TranslatedField `json:"fields" bson:"fields"`
+}
+
+// TranslatableField désigne un champ texte traduisible de la propriété
+type TranslatableField struct {
+	Section string
+	Path    string
+	Value   *string
+}
+
+// UpsertTranslationRequest remplace les traductions d'une langue (chemin → texte)
+type UpsertTranslationRequest struct {
+	Fields map[string]string `json:"fields" binding:"required"`
+}
+
+// TranslatableFields retourne des pointeurs vers les champs texte libres de la propriété.
+// Les codes d'accès, noms propres, téléphones et valeurs énumérées ne sont pas traduisibles.
+// Les éléments de liste sont identifiés par leur id (équipements, contacts, recommandations)
+// ou, à défaut, par leur position (règles de la maison).
+func (p *Property) TranslatableFields() []TranslatableField {
+	var fields []TranslatableField
+	add := func(section, path string, value *string) {
+		fields = append(fields, TranslatableField{Section: section, Path: path, Value: value})
+	}
+
+	add("general", "description", &p.Description)
+
+	if c := p.CheckInOut; c != nil {
+		add("checkInOut", "checkInOut.checkInInstructions", &c.CheckInInstructions)
+		add("checkInOut", "checkInOut.checkOutInstructions", &c.CheckOutInstructions)
+	}
+
+	if w := p.Wifi; w != nil {
+		add("wifi", "wifi.routerLocation", &w.RouterLocation)
+		add("wifi", "wifi.resetInstructions", &w.ResetInstructions)
+		add("wifi", "wifi.notes", &w.Notes)
+	}
+
+	if e := p.Equipment; e != nil {
+		for i := range e.Items {
+			add("equipment", "equipment."+e.Items[i].ID+".name", &e.Items[i].Name)
+		}
+	}
+
+	if in := p.Instructions; in != nil {
+		for _, item := range []struct {
+			key  string
+			item *InstructionItem
+		}{
+			{"trash", in.Trash},
+			{"heating", in.Heating},
+			{"airConditioning", in.AirConditioning},
+			{"hotWater", in.HotWater},
+			{"appliances", in.Appliances},
+			{"laundry", in.Laundry},
+			{"dishwasher", in.Dishwasher},
+			{"oven", in.Oven},
+			{"coffeeMachine", in.CoffeeMachine},
+			{"television", in.Television},
+			{"sound", in.Sound},
+			{"blinds", in.Blinds},
+			{"alarm", in.Alarm},
+			{"safe", in.Safe},
+			{"pool", in.Pool},
+			{"spa", in.Spa},
+			{"garden", in.Garden},
+			{"barbecue", in.Barbecue},
+			{"fireplace", in.Fireplace},
+			{"other", in.Other},
+		} {
+			if item.item != nil {
+				add("instructions", "instructions."+item.key, &item.item.Content)
+			}
+		}
+	}
+
+	if r := p.Rules; r != nil {
+		add("rules", "rules.quietHours", &r.QuietHours)
+		for i := range r.HouseRules {
+			add("rules", fmt.Sprintf("rules.houseRules.%d", i), &r.HouseRules[i])
+		}
+		add("rules", "rules.additionalRules", &r.AdditionalRules)
+	}
+
+	if ct := p.Contacts; ct != nil {
+		for i := range ct.Contacts {
+			add("contacts", "contacts."+ct.Contacts[i].ID+".notes", &ct.Contacts[i].Notes)
+		}
+	}
+
+	if lr := p.LocalRecommendations; lr != nil {
+		for i := range lr.Recommendations {
+			add("localRecommendations", "localRecommendations."+lr.Recommendations[i].ID+".description", &lr.Recommendations[i].Description)
+		}
+	}
+
+	if pk := p.Parking; pk != nil {
+		add("parking", "parking.price", &pk.Price)
+		add("parking", "parking.instructions", &pk.Instructions)
+	}
+
+	if t := p.Transport; t != nil {
+		add("transport", "transport.nearestBus", &t.NearestBus)
+		add("transport", "transport.nearestMetro", &t.NearestMetro)
+		add("transport", "transport.nearestTrain", &t.NearestTrain)
+		add("transport", "transport.nearestTram", &t.NearestTram)
+		add("transport", "transport.taxiInfo", &t.TaxiInfo)
+		add("transport", "transport.bikeRental", &t.BikeRental)
+		add("transport", "transport.carRental", &t.CarRental)
+		add("transport", "transport.airportShuttle", &t.AirportShuttle)
+		add("transport", "transport.walkingInfo", &t.WalkingInfo)
+	}
+
+	if s := p.Security; s != nil {
+		add("security", "security.alarmInstructions", &s.AlarmInstructions)
+		add("security", "security.safeLocation", &s.SafeLocation)
+		add("security", "security.fireExtinguisherLocation", &s.FireExtinguisherLocation)
+		add("security", "security.firstAidKitLocation", &s.FirstAidKitLocation)
+		add("security", "security.securityNotes", &s.SecurityNotes)
+	}
+
+	if s := p.Services; s != nil {
+		add("services", "services.cleaningFrequency", &s.CleaningFrequency)
+		add("services", "services.breakfastDetails", &s.BreakfastDetails)
+		add("services", "services.conciergeService", &s.ConciergeService)
+		add("services", "services.groceryDelivery", &s.GroceryDelivery)
+	}
+
+	if b := p.BabyKids; b != nil {
+		add("babyKids", "babyKids.nearbyPlaygrounds", &b.NearbyPlaygrounds)
+		add("babyKids", "babyKids.additionalInfo", &b.AdditionalInfo)
+	}
+
+	if pt := p.Pets; pt != nil {
+		add("pets", "pets.petFee", &pt.PetFee)
+		add("pets", "pets.petRules", &pt.PetRules)
+		add("pets", "pets.dogWalkingAreas", &pt.DogWalkingAreas)
+		add("pets", "pets.petEquipmentAvailable", &pt.PetEquipmentAvailable)
+	}
+
+	if e := p.Entertainment; e != nil {
+		add("entertainment", "entertainment.tvChannels", &e.TvChannels)
+		add("entertainment", "entertainment.netflixInstructions", &e.NetflixInstructions)
+		add("entertainment", "entertainment.spotifyInstructions", &e.SpotifyInstructions)
+		add("entertainment", "entertainment.gameConsoleDetails", &e.GameConsoleDetails)
+		add("entertainment", "entertainment.boardGames", &e.BoardGames)
+		add("entertainment", "entertainment.books", &e.Books)
+	}
+
+	if o := p.Outdoor; o != nil {
+		add("outdoor", "outdoor.gardenInfo", &o.GardenInfo)
+		add("outdoor", "outdoor.terraceInfo", &o.TerraceInfo)
+		add("outdoor", "outdoor.balconyInfo", &o.BalconyInfo)
+		add("outdoor", "outdoor.poolInfo", &o.PoolInfo)
+		add("outdoor", "outdoor.poolRules", &o.PoolRules)
+		add("outdoor", "outdoor.spaInfo", &o.SpaInfo)
+		add("outdoor", "outdoor.barbecueInfo", &o.BarbecueInfo)
+	}
+
+	if n := p.Neighborhood; n != nil {
+		add("neighborhood", "neighborhood.description", &n.Description)
+		add("neighborhood", "neighborhood.neighborInfo", &n.NeighborInfo)
+		add("neighborhood", "neighborhood.nearbyAttractions", &n.NearbyAttractions)
+		add("neighborhood", "neighborhood.safetyTips", &n.SafetyTips)
+	}
+
+	if e := p.Emergency; e != nil {
+		add("emergency", "emergency.nearestPharmacyHours", &e.NearestPharmacyHours)
+		add("emergency", "emergency.additionalEmergencyInfo", &e.AdditionalEmergencyInfo)
+	}
+
+	return fields
+}
+
+// Translation retourne les traductions d'une langue, nil si elle n'existe pas
+func (p *Property) Translation(locale string) *PropertyTranslation {
+	for i := range p.Translations {
+		if p.Translations[i].Locale == locale {
+			return &p.Translations[i]
+		}
+	}
+	return nil
+}
+
+// Locales retourne la langue par défaut suivie des langues traduites
+func (p *Property) Locales(defaultLocale string) []string {
+	locales := []string{p.ContentLocale(defaultLocale)}
+	for _, translation := range p.Translations {
+		if translation.Locale != locales[0] {
+			locales = append(locales, translation.Locale)
+		}
+	}
+	return locales
+}
+
+// ContentLocale retourne la langue dans laquelle les contenus de base ont été saisis
+func (p *Property) ContentLocale(defaultLocale string) string {
+	if p.DefaultLocale != "" {
+		return p.DefaultLocale
+	}
+	return defaultLocale
+}
+
+// Localize remplace les textes par leur traduction dans la langue demandée.
+// Un champ non traduit conserve le texte de la langue par défaut.
+func (p *Property) Localize(locale string) {
+	translation := p.Translation(locale)
+	if translation == nil {
+		return
+	}
+
+	texts := make(map[string]string, len(translation.Fields))
+	for _, field := range translation.Fields {
+		texts[field.Path] = field.Text
+	}
+
+	for _, field := range p.TranslatableFields() {
+		if text, ok := texts[field.Path]; ok && text != "" {
+			*field.Value = text
+		}
+	}
+}
+
+// MissingTranslations retourne, section par section, les chemins des champs renseignés
+// dans la langue par défaut mais pas dans locale. Seules les sections activées comptent.
+func (p *Property) MissingTranslations(locale string) map[string][]string {
+	translated := make(map[string]bool)
+	if translation := p.Translation(locale); translation != nil {
+		for _, field := range translation.Fields {
+			if field.Text != "" {
+				translated[field.Path] = true
+			}
+		}
+	}
+
+	enabled := p.enabledSections()
+	missing := make(map[string][]string)
+	for _, field := range p.TranslatableFields() {
+		if !enabled[field.Section] || strings.TrimSpace(*field.Value) == "" || translated[field.Path] {
+			continue
+		}
+		missing[field.Section] = append(missing[field.Section], field.Path)
+	}
+	for section := range missing {
+		sort.Strings(missing[section])
+	}
+	return missing
+}
+
+// enabledSections indique les sections affichées aux voyageurs
+func (p *Property) enabledSections() map[string]bool {
+	return map[string]bool{
+		"general":              true,
+		"checkInOut":           p.CheckInOut != nil && p.CheckInOut.Enabled,
+		"wifi":                 p.Wifi != nil && p.Wifi.Enabled,
+		"equipment":            p.Equipment != nil && p.Equipment.Enabled,
+		"instructions":         p.Instructions != nil && p.Instructions.Enabled,
+		"rules":                p.Rules != nil && p.Rules.Enabled,
+		"contacts":             p.Contacts != nil && p.Contacts.Enabled,
+		"localRecommendations": p.LocalRecommendations != nil && p.LocalRecommendations.Enabled,
+		"parking":              p.Parking != nil && p.Parking.Enabled,
+		"transport":            p.Transport != nil && p.Transport.Enabled,
+		"security":             p.Security != nil && p.Security.Enabled,
+		"services":             p.Services != nil && p.Services.Enabled,
+		"babyKids":             p.BabyKids != nil && p.BabyKids.Enabled,
+		"pets":                 p.Pets != nil && p.Pets.Enabled,
+		"entertainment":        p.Entertainment != nil && p.Entertainment.Enabled,
+		"outdoor":              p.Outdoor != nil && p.Outdoor.Enabled,
+		"neighborhood":         p.Neighborhood != nil && p.Neighborhood.Enabled,
+		"emergency":            p.Emergency != nil && p.Emergency.Enabled,
+	}
+}
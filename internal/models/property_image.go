@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxPropertyImages limite le nombre de photos d'une propriété
+const MaxPropertyImages = 50
+
+// ImageVariant représente une déclinaison redimensionnée d'une photo
+type ImageVariant struct {
+	Key    string `json:"-" bson:"key"` // Clé dans le stockage des médias
+	URL    string `json:"url" bson:"url"`
+	Width  int    `json:"width" bson:"width"`
+	Height int    `json:"height" bson:"height"`
+}
+
+// PropertyImage représente une photo envoyée par l'hôte. L'ordre du tableau Property.Photos
+// est l'ordre d'affichage.
+type PropertyImage struct {
+	ID          primitive.ObjectID `json:"id" bson:"id"`
+	Caption     string             `json:"caption,omitempty" bson:"caption,omitempty"`
+	IsCover     bool               `json:"isCover" bson:"isCover"`
+	ContentType string             `json:"contentType" bson:"contentType"`
+	Large       ImageVariant       `json:"large" bson:"large"`
+	Medium      ImageVariant       `json:"medium" bson:"medium"`
+	Thumbnail   ImageVariant       `json:"thumbnail" bson:"thumbnail"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// Keys retourne les clés de stockage de toutes les déclinaisons
+func (i *PropertyImage) Keys() []string {
+	return []string{i.Large.Key, i.Medium.Key, i.Thumbnail.Key}
+}
+
+// UpdatePropertyImageRequest représente la modification d'une photo
+type UpdatePropertyImageRequest struct {
+	Caption *string `json:"caption,omitempty" binding:"omitempty,max=300"`
+	IsCover *bool   `json:"isCover,omitempty"`
+}
+
+// ReorderPropertyImagesRequest donne le nouvel ordre complet des photos
+type ReorderPropertyImagesRequest struct {
+	Order []string `json:"order" binding:"required"`
+}
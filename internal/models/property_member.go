@@ -0,0 +1,119 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Rôles d'un utilisateur sur une propriété. Le propriétaire est l'hôte désigné par
+// Property.HostID, ou découle du rôle dans l'organisation détentrice ; les autres rôles
+// sont attribués aux collaborateurs.
+const (
+	PropertyRoleOwner   = "owner"
+	PropertyRoleEditor  = "editor"
+	PropertyRoleViewer  = "viewer"
+	PropertyRoleCleaner = "cleaner" // Personnel de ménage : accès au logement et au calendrier
+)
+
+// PropertyAction est une action soumise à autorisation sur une propriété
+type PropertyAction string
+
+const (
+	PropertyActionRead    PropertyAction = "read"    // Consulter la propriété (secrets compris), son calendrier et ses réservations
+	PropertyActionReview  PropertyAction = "review"  // Consulter les modifications en attente, l'historique, les traductions et les liens voyageurs
+	PropertyActionEdit    PropertyAction = "edit"    // Modifier le contenu, les photos et les traductions
+	PropertyActionPublish PropertyAction = "publish" // Publier, programmer ou dépublier
+	PropertyActionOperate PropertyAction = "operate" // Gérer les réservations, le calendrier et les liens voyageurs
+	PropertyActionManage  PropertyAction = "manage"  // Gérer les collaborateurs, dupliquer, transférer ou supprimer la propriété
+)
+
+// PropertyRoleActions donne les actions autorisées pour chaque rôle
+var PropertyRoleActions = map[string][]PropertyAction{
+	PropertyRoleOwner: {
+		PropertyActionRead, PropertyActionReview, PropertyActionEdit,
+		PropertyActionPublish, PropertyActionOperate, PropertyActionManage,
+	},
+	PropertyRoleEditor: {
+		PropertyActionRead, PropertyActionReview, PropertyActionEdit,
+		PropertyActionPublish, PropertyActionOperate,
+	},
+	PropertyRoleViewer:  {PropertyActionRead, PropertyActionReview},
+	PropertyRoleCleaner: {PropertyActionRead},
+}
+
+// PropertyRoleAllows indique si un rôle autorise une action
+func PropertyRoleAllows(role string, action PropertyAction) bool {
+	return slices.Contains(PropertyRoleActions[role], action)
+}
+
+// PropertyMember est un collaborateur d'une propriété
+type PropertyMember struct {
+	ID         primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	PropertyID primitive.ObjectID  `json:"propertyId" bson:"propertyId"`
+	UserID     primitive.ObjectID  `json:"userId" bson:"userId"`
+	Role       string              `json:"role" bson:"role"`
+	InvitedBy  *primitive.ObjectID `json:"invitedBy,omitempty" bson:"invitedBy,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// PropertyMemberView est un collaborateur tel que présenté à l'équipe de la propriété
+type PropertyMemberView struct {
+	UserID    primitive.ObjectID `json:"userId"`
+	Role      string             `json:"role"`
+	Nom       string             `json:"nom"`
+	Prenom    string             `json:"prenom"`
+	Email     string             `json:"email"`
+	CreatedAt *time.Time         `json:"createdAt,omitempty"` // Absent pour le propriétaire
+}
+
+// Statuts d'une invitation
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+)
+
+// PropertyInvitation invite une personne, par email, à collaborer sur une propriété.
+// Seule l'empreinte du token envoyé est stockée.
+type PropertyInvitation struct {
+	ID          primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	PropertyID  primitive.ObjectID `json:"propertyId" bson:"propertyId"`
+	Email       string             `json:"email" bson:"email"`
+	Role        string             `json:"role" bson:"role"`
+	TokenHash   string             `json:"-" bson:"tokenHash"`
+	Status      string             `json:"status" bson:"status"`
+	InvitedBy   primitive.ObjectID `json:"invitedBy" bson:"invitedBy"`
+	ExpiresAt   time.Time          `json:"expiresAt" bson:"expiresAt"`
+	RespondedAt *time.Time         `json:"respondedAt,omitempty" bson:"respondedAt,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// IsPendingAt indique si l'invitation peut encore être acceptée ou refusée
+func (i *PropertyInvitation) IsPendingAt(t time.Time) bool {
+	return i.Status == InvitationStatusPending && t.Before(i.ExpiresAt)
+}
+
+// InviteMemberRequest invite un collaborateur sur une propriété
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=editor viewer cleaner"`
+}
+
+// UpdateMemberRequest change le rôle d'un collaborateur
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer cleaner"`
+}
+
+// TransferOwnershipRequest transfère la propriété à l'un de ses collaborateurs
+type TransferOwnershipRequest struct {
+	UserID string `json:"userId" binding:"required"`
+}
+
+// SharedProperty est une propriété sur laquelle l'utilisateur collabore, avec son rôle
+type SharedProperty struct {
+	Property *Property `json:"property"`
+	Role     string    `json:"role"`
+}
@@ -0,0 +1,280 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions à l'origine d'une révision
+const (
+	RevisionActionInitial      = "initial" // État de la propriété avant la première révision enregistrée
+	RevisionActionCreate       = "create"
+	RevisionActionUpdate       = "update"
+	RevisionActionPublish      = "publish"
+	RevisionActionUnpublish    = "unpublish"
+	RevisionActionImages       = "images"
+	RevisionActionTranslations = "translations"
+	RevisionActionRestore      = "restore"
+	RevisionActionDuplicate    = "duplicate" // Création par duplication d'une autre propriété
+	RevisionActionTemplate     = "template"  // Application d'un modèle de section
+)
+
+// MaxPropertyRevisions est le nombre de révisions conservées par propriété ; les plus anciennes sont purgées
+const MaxPropertyRevisions = 100
+
+// PropertyRevision est un instantané complet d'une propriété, enregistré après chaque modification
+type PropertyRevision struct {
+	ID           primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	PropertyID   primitive.ObjectID  `json:"propertyId" bson:"propertyId"`
+	Number       int                 `json:"number" bson:"number"` // Numéro croissant, propre à chaque propriété
+	Action       string              `json:"action" bson:"action"`
+	AuthorID     *primitive.ObjectID `json:"authorId,omitempty" bson:"authorId,omitempty"`
+	Sections     []string            `json:"sections" bson:"sections"`                             // Sections modifiées depuis la révision précédente
+	RestoredFrom int                 `json:"restoredFrom,omitempty" bson:"restoredFrom,omitempty"` // Révision restaurée (action "restore")
+	Snapshot     *Property           `json:"snapshot,omitempty" bson:"snapshot,omitempty"`
+	CreatedAt    time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+// RestoreRevisionRequest limite éventuellement la restauration à certaines sections
+type RestoreRevisionRequest struct {
+	Sections []string `json:"sections"`
+}
+
+// FieldChange décrit un champ modifié entre deux révisions (nil : champ absent)
+type FieldChange struct {
+	Path   string `json:"path"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// SectionDiff regroupe les champs modifiés d'une section
+type SectionDiff struct {
+	Section string        `json:"section"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// RevisionSections liste les sections comparées, dans l'ordre d'affichage.
+// "general" regroupe les informations principales, "publication" le statut.
+var RevisionSections = []string{
+	"general", "publication", "photos",
+	"checkInOut", "wifi", "equipment", "instructions", "rules", "contacts",
+	"localRecommendations", "parking", "transport", "security", "services",
+	"babyKids", "pets", "entertainment", "outdoor", "neighborhood", "emergency",
+	"translations",
+}
+
+// RestorableSections sont les sections qu'une restauration peut remettre en place.
+// Le statut de publication et les photos, dont les fichiers ont pu être supprimés, en sont exclus.
+var RestorableSections = []string{
+	"general",
+	"checkInOut", "wifi", "equipment", "instructions", "rules", "contacts",
+	"localRecommendations", "parking", "transport", "security", "services",
+	"babyKids", "pets", "entertainment", "outdoor", "neighborhood", "emergency",
+	"translations",
+}
+
+// SectionValue retourne le contenu d'une section, tel qu'il est comparé entre deux révisions
+func (p *Property) SectionValue(section string) any {
+	switch section {
+	case "general":
+		return map[string]any{
+			"name":          p.Name,
+			"description":   p.Description,
+			"address":       p.Address,
+			"city":          p.City,
+			"country":       p.Country,
+			"zipCode":       p.ZipCode,
+			"location":      p.Location,
+			"images":        p.Images,
+			"defaultLocale": p.DefaultLocale,
+		}
+	case "publication":
+		return map[string]any{"status": p.Status, "publishedAt": p.PublishedAt}
+	case "photos":
+		return p.Photos
+	case "checkInOut":
+		return p.CheckInOut
+	case "wifi":
+		return p.Wifi
+	case "equipment":
+		return p.Equipment
+	case "instructions":
+		return p.Instructions
+	case "rules":
+		return p.Rules
+	case "contacts":
+		return p.Contacts
+	case "localRecommendations":
+		return p.LocalRecommendations
+	case "parking":
+		return p.Parking
+	case "transport":
+		return p.Transport
+	case "security":
+		return p.Security
+	case "services":
+		return p.Services
+	case "babyKids":
+		return p.BabyKids
+	case "pets":
+		return p.Pets
+	case "entertainment":
+		return p.Entertainment
+	case "outdoor":
+		return p.Outdoor
+	case "neighborhood":
+		return p.Neighborhood
+	case "emergency":
+		return p.Emergency
+	case "translations":
+		return p.Translations
+	}
+	return nil
+}
+
+// SectionUpdates retourne les champs des sections demandées, prêts pour un $set
+// (restauration d'une révision, publication d'un brouillon)
+func (p *Property) SectionUpdates(sections []string) map[string]interface{} {
+	updates := make(map[string]interface{})
+	for _, section := range sections {
+		switch section {
+		case "general":
+			updates["name"] = p.Name
+			updates["description"] = p.Description
+			updates["address"] = p.Address
+			updates["city"] = p.City
+			updates["country"] = p.Country
+			updates["zipCode"] = p.ZipCode
+			updates["location"] = p.Location
+			updates["images"] = p.Images
+			updates["defaultLocale"] = p.DefaultLocale
+		case "translations":
+			translations := p.Translations
+			if translations == nil {
+				translations = []PropertyTranslation{}
+			}
+			updates["translations"] = translations
+		default:
+			updates[section] = p.SectionValue(section)
+		}
+	}
+	return updates
+}
+
+// DiffSections compare deux états d'une propriété section par section.
+// Seules les sections modifiées sont retournées, dans l'ordre de RevisionSections.
+func DiffSections(before, after *Property) []SectionDiff {
+	diffs := []SectionDiff{}
+	for _, section := range RevisionSections {
+		changes := diffValues(flatten(before, section), flatten(after, section))
+		if len(changes) > 0 {
+			diffs = append(diffs, SectionDiff{Section: section, Changes: changes})
+		}
+	}
+	return diffs
+}
+
+// ChangedSections retourne le nom des sections modifiées entre deux états
+func ChangedSections(before, after *Property) []string {
+	sections := []string{}
+	for _, diff := range DiffSections(before, after) {
+		sections = append(sections, diff.Section)
+	}
+	return sections
+}
+
+// flatten ramène une section à une liste de chemins feuilles (section.champ.sous-champ → valeur).
+// La représentation JSON est utilisée pour que les chemins correspondent à ceux de l'API.
+func flatten(p *Property, section string) map[string]any {
+	values := make(map[string]any)
+	if p == nil {
+		return values
+	}
+
+	data, err := json.Marshal(p.SectionValue(section))
+	if err != nil {
+		return values
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return values
+	}
+
+	// Les métadonnées générales sont à la racine de la propriété
+	prefix := section
+	if section == "general" || section == "publication" {
+		prefix = ""
+	}
+	flattenValue(prefix, decoded, values)
+	return values
+}
+
+func flattenValue(path string, value any, values map[string]any) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return
+		}
+		for key, child := range v {
+			flattenValue(join(key), child, values)
+		}
+	case []any:
+		// Les éléments identifiés (équipements, contacts, photos...) sont repérés par leur id,
+		// pour qu'une insertion en tête de liste n'apparaisse pas comme une modification de tous les éléments
+		for i, child := range v {
+			key := fmt.Sprint(i)
+			if item, ok := child.(map[string]any); ok {
+				if id, ok := item["id"].(string); ok && id != "" {
+					key = id
+				} else if id, ok := item["_id"].(string); ok && id != "" {
+					key = id
+				} else if locale, ok := item["locale"].(string); ok && locale != "" {
+					key = locale
+				}
+			}
+			flattenValue(join(key), child, values)
+		}
+	case nil:
+		// Champ absent : ignoré, il apparaîtra comme ajouté ou supprimé
+	default:
+		if path != "" {
+			values[path] = v
+		}
+	}
+}
+
+func diffValues(before, after map[string]any) []FieldChange {
+	paths := make(map[string]bool, len(before)+len(after))
+	for path := range before {
+		paths[path] = true
+	}
+	for path := range after {
+		paths[path] = true
+	}
+
+	changes := []FieldChange{}
+	for path := range paths {
+		b, inBefore := before[path]
+		a, inAfter := after[path]
+		if inBefore && inAfter && reflect.DeepEqual(a, b) {
+			continue
+		}
+		changes = append(changes, FieldChange{Path: path, Before: b, After: a})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
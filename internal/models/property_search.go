@@ -0,0 +1,43 @@
+package models
+
+// Tris disponibles pour la recherche de propriétés
+const (
+	PropertySortNewest   = "newest"
+	PropertySortOldest   = "oldest"
+	PropertySortNameAsc  = "name_asc"
+	PropertySortNameDesc = "name_desc"
+	PropertySortDistance = "distance" // Nécessite le paramètre near
+)
+
+// Taille de page de la recherche de propriétés
+const (
+	PropertySearchDefaultLimit = 20
+	PropertySearchMaxLimit     = 100
+)
+
+// PropertySearchDefaultRadiusKm est le rayon par défaut d'une recherche par proximité
+const PropertySearchDefaultRadiusKm = 10
+
+// PropertySearchQuery représente les critères de la recherche publique de propriétés.
+// Les filtres booléens non renseignés ne sont pas appliqués.
+type PropertySearchQuery struct {
+	Text                string   `form:"q"`
+	City                string   `form:"city"`
+	Country             string   `form:"country"`
+	ZipCode             string   `form:"zipCode"` // Préfixe : "75" correspond à tout Paris
+	Guests              int      `form:"guests" binding:"omitempty,min=1"`
+	PetsAllowed         *bool    `form:"petsAllowed"`
+	ChildrenAllowed     *bool    `form:"childrenAllowed"`
+	Parking             *bool    `form:"parking"`
+	Pool                *bool    `form:"pool"`
+	Spa                 *bool    `form:"spa"`
+	Garden              *bool    `form:"garden"`
+	EquipmentCategories []string `form:"equipment"` // Toutes les catégories demandées doivent être présentes
+	Near                string   `form:"near"`      // Format "latitude,longitude"
+	RadiusKm            float64  `form:"radius" binding:"omitempty,gt=0,max=500"`
+	Sort                string   `form:"sort" binding:"omitempty,oneof=newest oldest name_asc name_desc distance"`
+	Cursor              string   `form:"cursor"`
+	Limit               int64    `form:"limit" binding:"omitempty,min=1,max=100"`
+
+	NearPoint *GeoPoint `form:"-"` // Point décodé depuis Near
+}
@@ -0,0 +1,40 @@
+package models
+
+// SecretFields retourne des pointeurs vers les champs sensibles d'une propriété
+// (codes d'accès, mots de passe). Les sous-documents absents sont ignorés.
+func (p *Property) SecretFields() []*string {
+	var fields []*string
+
+	if p.CheckInOut != nil {
+		fields = append(fields,
+			&p.CheckInOut.KeyLocation,
+			&p.CheckInOut.AccessCode,
+			&p.CheckInOut.LockboxCode,
+			&p.CheckInOut.BuildingCode,
+			&p.CheckInOut.IntercomCode,
+			&p.CheckInOut.ParkingCode,
+			&p.CheckInOut.GateCode,
+		)
+	}
+	if p.Wifi != nil {
+		fields = append(fields, &p.Wifi.Password)
+	}
+	if p.Parking != nil {
+		fields = append(fields, &p.Parking.AccessCode)
+	}
+	if p.Security != nil {
+		fields = append(fields,
+			&p.Security.AlarmCode,
+			&p.Security.SafeCode,
+		)
+	}
+
+	return fields
+}
+
+// RedactSecrets efface les champs sensibles, pour les lectures publiques
+func (p *Property) RedactSecrets() {
+	for _, field := range p.SecretFields() {
+		*field = ""
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reservation représente un séjour sur une propriété
+type Reservation struct {
+	ID             primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	PropertyID     primitive.ObjectID `json:"propertyId" bson:"propertyId"`
+	GuestName      string             `json:"guestName" bson:"guestName"`
+	GuestEmail     string             `json:"guestEmail" bson:"guestEmail"`
+	GuestPhone     string             `json:"guestPhone,omitempty" bson:"guestPhone,omitempty"`
+	NumberOfGuests int                `json:"numberOfGuests" bson:"numberOfGuests"`
+	ArrivalDate    time.Time          `json:"arrivalDate" bson:"arrivalDate"`     // Minuit UTC du jour d'arrivée
+	DepartureDate  time.Time          `json:"departureDate" bson:"departureDate"` // Minuit UTC du jour de départ
+	Status         string             `json:"status" bson:"status"`
+	Notes          string             `json:"notes,omitempty" bson:"notes,omitempty"`
+	CreatedBy      primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Statuts d'une réservation
+const (
+	ReservationStatusPending   = "pending"
+	ReservationStatusConfirmed = "confirmed"
+	ReservationStatusCancelled = "cancelled"
+	ReservationStatusCheckedIn = "checked-in"
+	ReservationStatusCompleted = "completed"
+)
+
+// ReservationDateLayout est le format des dates d'arrivée et de départ dans l'API
+const ReservationDateLayout = "2006-01-02"
+
+// reservationTransitions liste les changements de statut autorisés
+var reservationTransitions = map[string][]string{
+	ReservationStatusPending:   {ReservationStatusConfirmed, ReservationStatusCancelled},
+	ReservationStatusConfirmed: {ReservationStatusCheckedIn, ReservationStatusCancelled},
+	ReservationStatusCheckedIn: {ReservationStatusCompleted},
+}
+
+// CanTransitionTo indique si la réservation peut passer au statut demandé
+func (r *Reservation) CanTransitionTo(status string) bool {
+	if r.Status == status {
+		return true
+	}
+	for _, next := range reservationTransitions[r.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Nights retourne le nombre de nuits du séjour
+func (r *Reservation) Nights() int {
+	return int(r.DepartureDate.Sub(r.ArrivalDate).Hours() / 24)
+}
+
+// CreateReservationRequest représente la requête de création d'une réservation
+type CreateReservationRequest struct {
+	GuestName      string `json:"guestName" binding:"required"`
+	GuestEmail     string `json:"guestEmail" binding:"required,email"`
+	GuestPhone     string `json:"guestPhone,omitempty"`
+	NumberOfGuests int    `json:"numberOfGuests" binding:"required,min=1"`
+	ArrivalDate    string `json:"arrivalDate" binding:"required"`   // Format "YYYY-MM-DD"
+	DepartureDate  string `json:"departureDate" binding:"required"` // Format "YYYY-MM-DD"
+	Status         string `json:"status,omitempty" binding:"omitempty,oneof=pending confirmed"`
+	Notes          string `json:"notes,omitempty"`
+}
+
+// UpdateReservationRequest représente la requête de mise à jour d'une réservation (tous les champs optionnels)
+type UpdateReservationRequest struct {
+	GuestName      string `json:"guestName,omitempty"`
+	GuestEmail     string `json:"guestEmail,omitempty" binding:"omitempty,email"`
+	GuestPhone     string `json:"guestPhone,omitempty"`
+	NumberOfGuests int    `json:"numberOfGuests,omitempty" binding:"omitempty,min=1"`
+	ArrivalDate    string `json:"arrivalDate,omitempty"`
+	DepartureDate  string `json:"departureDate,omitempty"`
+	Status         string `json:"status,omitempty" binding:"omitempty,oneof=pending confirmed cancelled checked-in completed"`
+	Notes          string `json:"notes,omitempty"`
+}
@@ -5,11 +5,13 @@ import (
 )
 
 type Role struct {
-	ID        string    `json:"id" bson:"_id,omitempty"`
-	Name      string    `json:"name" bson:"name"`
-	Slug      string    `json:"slug" bson:"slug"`
-	CreatedAt time.Time `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+	ID          string    `json:"id" bson:"_id,omitempty"`
+	Name        string    `json:"name" bson:"name"`
+	Slug        string    `json:"slug" bson:"slug"`
+	Permissions []string  `json:"permissions" bson:"permissions"`
+	IsSystem    bool      `json:"is_system" bson:"is_system"` // Rôles créés par le seed : non supprimables
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
 }
 
 const (
@@ -20,6 +22,13 @@ const (
 )
 
 type CreateRoleRequest struct {
-	Name string `json:"name" binding:"required"`
-	Slug string `json:"slug" binding:"required"`
+	Name        string   `json:"name" binding:"required"`
+	Slug        string   `json:"slug" binding:"required"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest représente la modification d'un rôle (champs optionnels)
+type UpdateRoleRequest struct {
+	Name        string    `json:"name"`
+	Permissions *[]string `json:"permissions"`
 }
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TemplateSections sont les sections qu'un modèle peut contenir
+var TemplateSections = []string{
+	"checkInOut", "wifi", "equipment", "instructions", "rules", "contacts",
+	"localRecommendations", "parking", "transport", "security", "services",
+	"babyKids", "pets", "entertainment", "outdoor", "neighborhood", "emergency",
+}
+
+// SectionTemplate est une section enregistrée par un hôte pour être réutilisée sur ses propriétés.
+// Content ne contient que la section du modèle ; ses secrets sont chiffrés comme ceux d'une propriété.
+// Les propriétés de LinkedPropertyIDs reçoivent les modifications du modèle.
+type SectionTemplate struct {
+	ID                primitive.ObjectID   `json:"_id" bson:"_id,omitempty"`
+	HostID            primitive.ObjectID   `json:"hostId" bson:"hostId"`
+	Name              string               `json:"name" bson:"name"`
+	Section           string               `json:"section" bson:"section"`
+	Content           *Property            `json:"-" bson:"content"`
+	Value             any                  `json:"value" bson:"-"` // Contenu de la section, renseigné à la lecture
+	LinkedPropertyIDs []primitive.ObjectID `json:"linkedPropertyIds" bson:"linkedPropertyIds"`
+	CreatedAt         time.Time            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt         time.Time            `json:"updatedAt" bson:"updatedAt"`
+}
+
+// CreateSectionTemplateRequest enregistre un modèle à partir de la section d'une propriété
+// (propertyId) ou d'un contenu saisi (value)
+type CreateSectionTemplateRequest struct {
+	Name       string          `json:"name" binding:"required"`
+	Section    string          `json:"section" binding:"required"`
+	PropertyID string          `json:"propertyId,omitempty"`
+	Value      json.RawMessage `json:"value,omitempty"`
+}
+
+// UpdateSectionTemplateRequest renomme un modèle ou remplace son contenu, repris d'une
+// propriété (propertyId) ou saisi (value)
+type UpdateSectionTemplateRequest struct {
+	Name       string          `json:"name,omitempty"`
+	PropertyID string          `json:"propertyId,omitempty"`
+	Value      json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplySectionTemplateRequest applique un modèle à des propriétés. Avec linked, elles restent
+// liées au modèle jusqu'à ce que la section soit modifiée directement.
+type ApplySectionTemplateRequest struct {
+	PropertyIDs []string `json:"propertyIds" binding:"required,min=1"`
+	Linked      bool     `json:"linked"`
+}
+
+// DuplicatePropertyRequest nomme éventuellement la copie d'une propriété
+type DuplicatePropertyRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+var errEmptySection = errors.New("section vide")
+
+// IsTemplateSection indique si une section peut être enregistrée comme modèle
+func IsTemplateSection(section string) bool {
+	return slices.Contains(TemplateSections, section)
+}
+
+// SectionContent retourne une propriété ne contenant que la section demandée, copiée depuis p
+func (p *Property) SectionContent(section string) *Property {
+	content := &Property{}
+	content.setSection(section, p)
+	return content
+}
+
+// ParseSectionContent lit le contenu JSON d'une section et retourne une propriété ne contenant que cette section
+func ParseSectionContent(section string, value json.RawMessage) (*Property, error) {
+	data, err := json.Marshal(map[string]json.RawMessage{section: value})
+	if err != nil {
+		return nil, err
+	}
+
+	content := &Property{}
+	if err := json.Unmarshal(data, content); err != nil {
+		return nil, err
+	}
+	if !content.HasSection(section) {
+		return nil, errEmptySection
+	}
+	return content, nil
+}
+
+// ApplySection retourne une copie de la propriété dont la section est remplacée par celle de content
+func (p *Property) ApplySection(section string, content *Property) *Property {
+	merged := *p
+	merged.setSection(section, content)
+	return &merged
+}
+
+// setSection copie la section de src dans p
+func (p *Property) setSection(section string, src *Property) {
+	switch section {
+	case "checkInOut":
+		p.CheckInOut = src.CheckInOut
+	case "wifi":
+		p.Wifi = src.Wifi
+	case "equipment":
+		p.Equipment = src.Equipment
+	case "instructions":
+		p.Instructions = src.Instructions
+	case "rules":
+		p.Rules = src.Rules
+	case "contacts":
+		p.Contacts = src.Contacts
+	case "localRecommendations":
+		p.LocalRecommendations = src.LocalRecommendations
+	case "parking":
+		p.Parking = src.Parking
+	case "transport":
+		p.Transport = src.Transport
+	case "security":
+		p.Security = src.Security
+	case "services":
+		p.Services = src.Services
+	case "babyKids":
+		p.BabyKids = src.BabyKids
+	case "pets":
+		p.Pets = src.Pets
+	case "entertainment":
+		p.Entertainment = src.Entertainment
+	case "outdoor":
+		p.Outdoor = src.Outdoor
+	case "neighborhood":
+		p.Neighborhood = src.Neighborhood
+	case "emergency":
+		p.Emergency = src.Emergency
+	}
+}
+
+// HasSection indique si la section est renseignée
+func (p *Property) HasSection(section string) bool {
+	value := reflect.ValueOf(p.SectionValue(section))
+	return value.IsValid() && !(value.Kind() == reflect.Pointer && value.IsNil())
+}
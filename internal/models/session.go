@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session représente une famille de refresh tokens issue d'une connexion.
+// Le refresh token courant est remplacé à chaque rafraîchissement ; les
+// empreintes précédentes sont conservées pour détecter leur réutilisation.
+type Session struct {
+	ID                  primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID              primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	OrganizationID      *primitive.ObjectID `json:"organization_id,omitempty" bson:"organization_id,omitempty"` // Organisation pour laquelle agit l'utilisateur
+	RefreshTokenHash    string              `json:"-" bson:"refresh_token_hash"`
+	PreviousTokenHashes []string            `json:"-" bson:"previous_token_hashes,omitempty"`
+	UserAgent           string              `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	IP                  string              `json:"ip,omitempty" bson:"ip,omitempty"`
+	ExpiresAt           time.Time           `json:"expires_at" bson:"expires_at"`
+	LastUsedAt          time.Time           `json:"last_used_at" bson:"last_used_at"`
+	RevokedAt           *time.Time          `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	RevokedReason       string              `json:"revoked_reason,omitempty" bson:"revoked_reason,omitempty"`
+	CreatedAt           time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+// MaxPreviousTokenHashes borne l'historique des refresh tokens consommés d'une session.
+// Un token plus ancien n'est plus reconnu comme réutilisé, mais il reste refusé.
+const MaxPreviousTokenHashes = 50
+
+// Raisons de révocation d'une session
+const (
+	SessionRevokedLogout          = "logout"
+	SessionRevokedLogoutAll       = "logout_all"
+	SessionRevokedReuseDetected   = "refresh_token_reuse"
+	SessionRevokedUserDeleted     = "user_deleted"
+	SessionRevokedPasswordChanged = "password_changed"
+)
+
+// IsActive indique si la session peut encore être utilisée
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type RefreshTokenResponse struct {
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
@@ -7,14 +7,16 @@ import (
 )
 
 type User struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Nom       string             `json:"nom" bson:"nom" binding:"required"`
-	Prenom    string             `json:"prenom" bson:"prenom" binding:"required"`
-	Email     string             `json:"email" bson:"email" binding:"required,email"`
-	Password  string             `json:"-" bson:"password" binding:"required,min=6"`
-	RoleID    string             `json:"role_id" bson:"role_id"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID                       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Nom                      string             `json:"nom" bson:"nom" binding:"required"`
+	Prenom                   string             `json:"prenom" bson:"prenom" binding:"required"`
+	Email                    string             `json:"email" bson:"email" binding:"required,email"`
+	Password                 string             `json:"-" bson:"password" binding:"required,min=6"`
+	RoleID                   string             `json:"role_id" bson:"role_id"`
+	EmailVerificationPending bool               `json:"-" bson:"email_verification_pending,omitempty"` // Inscription publique : connexion bloquée tant que l'email n'est pas confirmé
+	EmailVerifiedAt          *time.Time         `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty"`
+	CreatedAt                time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt                time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
 type RegisterRequest struct {
@@ -25,22 +27,42 @@ type RegisterRequest struct {
 	RoleID   string `json:"role_id" binding:"required"`
 }
 
+// SignupRequest représente une inscription publique, limitée aux rôles client et loueur
+type SignupRequest struct {
+	Nom      string `json:"nom" binding:"required"`
+	Prenom   string `json:"prenom" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	Role     string `json:"role" binding:"required,oneof=client loueur"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
 
 type LoginResponse struct {
-	User UserProfile `json:"user"`
+	User         UserProfile `json:"user"`
+	RefreshToken string      `json:"refresh_token"`
+	ExpiresIn    int64       `json:"expires_in"`
 }
 
 type UserProfile struct {
-	ID        primitive.ObjectID `json:"id"`
-	Nom       string             `json:"nom"`
-	Prenom    string             `json:"prenom"`
-	Email     string             `json:"email"`
-	RoleID    string             `json:"role_id"`
-	CreatedAt time.Time          `json:"created_at"`
+	ID            primitive.ObjectID `json:"id"`
+	Nom           string             `json:"nom"`
+	Prenom        string             `json:"prenom"`
+	Email         string             `json:"email"`
+	RoleID        string             `json:"role_id"`
+	EmailVerified bool               `json:"email_verified"`
+	CreatedAt     time.Time          `json:"created_at"`
 }
 
 type UserWithRole struct {
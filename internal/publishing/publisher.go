@@ -0,0 +1,226 @@
+// Package publishing met en ligne les propriétés : publication immédiate ou programmée,
+// publication des modifications en attente et retour au brouillon.
+package publishing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"onestay-back/internal/models"
+	"onestay-back/internal/readiness"
+	"onestay-back/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type Publisher struct {
+	propertyRepo repository.PropertyStore
+	draftRepo    repository.PropertyDraftStore
+	revisionRepo repository.PropertyRevisionStore
+	validator    *readiness.Validator
+}
+
+func NewPublisher(properties repository.PropertyStore, drafts repository.PropertyDraftStore, revisions repository.PropertyRevisionStore, validator *readiness.Validator) *Publisher {
+	return &Publisher{
+		propertyRepo: properties,
+		draftRepo:    drafts,
+		revisionRepo: revisions,
+		validator:    validator,
+	}
+}
+
+// ErrDraftChanged est retourné lorsque le brouillon est modifié pendant sa publication :
+// rien n'est mis en ligne et les modifications restent en attente
+var ErrDraftChanged = errors.New("publishing: brouillon modifié pendant la publication")
+
+// NotReadyError est retourné lorsqu'une propriété ne respecte pas les règles de publication bloquantes
+type NotReadyError struct {
+	Checklist readiness.Checklist
+}
+
+func (e *NotReadyError) Error() string {
+	return fmt.Sprintf("règles de publication non respectées: %s", strings.Join(e.Checklist.FailedRules(), ", "))
+}
+
+// Readiness vérifie les règles de publication sur le contenu qui serait mis en ligne
+// (modifications en attente comprises) et indique s'il y a des modifications en attente
+func (p *Publisher) Readiness(ctx context.Context, property *models.Property) (readiness.Checklist, bool, error) {
+	draft, err := p.findDraft(ctx, property)
+	if err != nil {
+		return readiness.Checklist{}, false, err
+	}
+
+	preview := property
+	if draft != nil {
+		preview = property.ApplyDraft(draft.Content)
+	}
+	return p.validator.Check(preview), draft != nil, nil
+}
+
+// Publish met en ligne le contenu le plus récent de la propriété : un brouillon passe au
+// statut publié et les modifications en attente d'une propriété publiée remplacent son
+// contenu. Si une règle bloquante n'est pas respectée, rien n'est modifié et une
+// *NotReadyError est retournée ; si le brouillon change entre sa lecture et sa mise en
+// ligne, ErrDraftChanged. Retourne la propriété à jour.
+func (p *Publisher) Publish(ctx context.Context, property *models.Property, authorID *primitive.ObjectID) (*models.Property, error) {
+	draft, err := p.findDraft(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := property
+	content := map[string]interface{}{}
+	if draft != nil {
+		preview = property.ApplyDraft(draft.Content)
+		content = draft.Content.DraftUpdates()
+	}
+
+	if checklist := p.validator.Check(preview); !checklist.Ready {
+		return nil, &NotReadyError{Checklist: checklist}
+	}
+
+	var publishedAt *time.Time
+	if !property.IsPublished() {
+		now := time.Now()
+		publishedAt = &now
+	}
+
+	if err := p.consume(ctx, draft); err != nil {
+		return nil, err
+	}
+	if err := p.propertyRepo.Publish(ctx, property.ID, content, publishedAt); err != nil {
+		p.restore(ctx, property, draft)
+		return nil, err
+	}
+
+	return p.finish(ctx, property, authorID, models.RevisionActionPublish)
+}
+
+// Unpublish repasse la propriété en brouillon. Les modifications en attente sont
+// conservées : elles deviennent le contenu du brouillon. ErrDraftChanged est retourné
+// si elles changent pendant l'opération.
+func (p *Publisher) Unpublish(ctx context.Context, property *models.Property, authorID *primitive.ObjectID) (*models.Property, error) {
+	draft, err := p.findDraft(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+
+	content := map[string]interface{}{}
+	if draft != nil {
+		content = draft.Content.DraftUpdates()
+	}
+
+	if err := p.consume(ctx, draft); err != nil {
+		return nil, err
+	}
+	if err := p.propertyRepo.Unpublish(ctx, property.ID, content); err != nil {
+		p.restore(ctx, property, draft)
+		return nil, err
+	}
+
+	return p.finish(ctx, property, authorID, models.RevisionActionUnpublish)
+}
+
+// findDraft retourne le brouillon en attente de la propriété, nil s'il n'y en a pas
+func (p *Publisher) findDraft(ctx context.Context, property *models.Property) (*models.PropertyDraft, error) {
+	draft, err := p.draftRepo.FindByPropertyID(ctx, property.ID)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return draft, err
+}
+
+// consume retire le brouillon lu avant d'appliquer son contenu, à condition qu'il n'ait pas
+// été modifié depuis : une modification concurrente n'est ainsi jamais perdue
+func (p *Publisher) consume(ctx context.Context, draft *models.PropertyDraft) error {
+	if draft == nil {
+		return nil
+	}
+	deleted, err := p.draftRepo.DeleteIfUnchanged(ctx, draft.PropertyID, draft.Version)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrDraftChanged
+	}
+	return nil
+}
+
+// restore remet en attente le brouillon consommé lorsque son contenu n'a pas pu être appliqué
+func (p *Publisher) restore(ctx context.Context, live *models.Property, draft *models.PropertyDraft) {
+	if draft == nil {
+		return
+	}
+	if err := p.draftRepo.Save(ctx, live, draft.Content.DraftUpdates(), draft.AuthorID); err != nil {
+		log.Printf("Erreur lors de la restauration du brouillon de la propriété %s: %v", live.ID.Hex(), err)
+	}
+}
+
+// finish enregistre la révision. Le contenu est déjà en ligne : une erreur est seulement journalisée.
+func (p *Publisher) finish(ctx context.Context, before *models.Property, authorID *primitive.ObjectID, action string) (*models.Property, error) {
+	after, err := p.propertyRepo.FindByID(ctx, before.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := models.PropertyRevision{Action: action, AuthorID: authorID}
+	if err := p.revisionRepo.Record(ctx, before, after, &revision); err != nil {
+		log.Printf("Erreur lors de l'enregistrement de la révision de la propriété %s: %v", after.ID.Hex(), err)
+	}
+
+	return after, nil
+}
+
+// Run publie les propriétés programmées au démarrage puis à chaque intervalle,
+// jusqu'à l'annulation du contexte
+func (p *Publisher) Run(ctx context.Context, interval time.Duration) {
+	log.Printf("Vérification des publications programmées toutes les %s", interval)
+
+	p.PublishDue(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.PublishDue(ctx)
+		}
+	}
+}
+
+// PublishDue publie les propriétés dont la date de publication programmée est passée ;
+// un échec n'interrompt pas les suivantes. Une propriété qui ne respecte pas les règles
+// de publication voit sa programmation annulée : l'hôte doit la corriger puis la reprogrammer.
+func (p *Publisher) PublishDue(ctx context.Context) {
+	properties, err := p.propertyRepo.FindDueForPublication(ctx, time.Now())
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des publications programmées: %v", err)
+		return
+	}
+
+	for i := range properties {
+		if ctx.Err() != nil {
+			return
+		}
+		_, err := p.Publish(ctx, &properties[i], nil)
+		if err == nil {
+			continue
+		}
+		log.Printf("Publication programmée de la propriété %s échouée: %v", properties[i].ID.Hex(), err)
+
+		var notReady *NotReadyError
+		if errors.As(err, &notReady) {
+			if err := p.propertyRepo.SchedulePublication(ctx, properties[i].ID, nil); err != nil {
+				log.Printf("Erreur lors de l'annulation de la publication programmée de la propriété %s: %v", properties[i].ID.Hex(), err)
+			}
+		}
+	}
+}
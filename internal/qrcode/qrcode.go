@@ -0,0 +1,164 @@
+// Package qrcode génère les QR codes affichés aux voyageurs (connexion Wi-Fi, livret d'accueil).
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	goqrcode "github.com/skip2/go-qrcode"
+)
+
+// Format de sortie d'un QR code
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+)
+
+// Level est le niveau de correction d'erreur : plus il est élevé, plus le code résiste
+// aux salissures ou à un logo superposé, au prix d'un motif plus dense
+type Level string
+
+const (
+	LevelLow      Level = "L" // ~7 % de redondance
+	LevelMedium   Level = "M" // ~15 %
+	LevelQuartile Level = "Q" // ~25 %
+	LevelHigh     Level = "H" // ~30 %
+)
+
+const (
+	DefaultSize = 256
+	MinSize     = 64
+	MaxSize     = 2048
+)
+
+var (
+	ErrInvalidFormat = errors.New("format invalide (png ou svg)")
+	ErrInvalidLevel  = errors.New("niveau de correction invalide (L, M, Q ou H)")
+	ErrInvalidSize   = fmt.Errorf("taille invalide (entre %d et %d pixels)", MinSize, MaxSize)
+)
+
+var recoveryLevels = map[Level]goqrcode.RecoveryLevel{
+	LevelLow:      goqrcode.Low,
+	LevelMedium:   goqrcode.Medium,
+	LevelQuartile: goqrcode.High,
+	LevelHigh:     goqrcode.Highest,
+}
+
+// Options paramètre le rendu d'un QR code
+type Options struct {
+	Format Format
+	Size   int // Côté de l'image en pixels
+	Level  Level
+}
+
+// DefaultOptions retourne un PNG de 256 pixels avec une correction moyenne
+func DefaultOptions() Options {
+	return Options{Format: FormatPNG, Size: DefaultSize, Level: LevelMedium}
+}
+
+// ParseOptions lit les paramètres format, size et level ; une valeur vide garde la valeur par défaut
+func ParseOptions(format, size, level string) (Options, error) {
+	opts := DefaultOptions()
+
+	if format != "" {
+		opts.Format = Format(strings.ToLower(format))
+		if opts.Format != FormatPNG && opts.Format != FormatSVG {
+			return opts, ErrInvalidFormat
+		}
+	}
+
+	if size != "" {
+		value, err := strconv.Atoi(size)
+		if err != nil || value < MinSize || value > MaxSize {
+			return opts, ErrInvalidSize
+		}
+		opts.Size = value
+	}
+
+	if level != "" {
+		opts.Level = Level(strings.ToUpper(level))
+		if _, ok := recoveryLevels[opts.Level]; !ok {
+			return opts, ErrInvalidLevel
+		}
+	}
+
+	return opts, nil
+}
+
+// ContentType retourne le type MIME correspondant au format
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Encode génère le QR code du contenu dans le format demandé
+func Encode(content string, opts Options) ([]byte, error) {
+	level, ok := recoveryLevels[opts.Level]
+	if !ok {
+		return nil, ErrInvalidLevel
+	}
+
+	code, err := goqrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Format == FormatSVG {
+		return svg(code.Bitmap(), opts.Size), nil
+	}
+	return code.PNG(opts.Size)
+}
+
+// PNG encode le contenu en QR code PNG de size pixels de côté
+func PNG(content string, size int) ([]byte, error) {
+	opts := DefaultOptions()
+	opts.Size = size
+	return Encode(content, opts)
+}
+
+// svg dessine chaque module sombre dans un unique chemin ; la marge de silence
+// est comprise dans la matrice
+func svg(bitmap [][]bool, size int) []byte {
+	modules := len(bitmap)
+
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, modules, modules)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`, modules, modules)
+	fmt.Fprintf(&b, `<path d="%s" fill="#000000"/>`, path.String())
+	b.WriteString("</svg>\n")
+	return []byte(b.String())
+}
+
+// wifiEscaper échappe les caractères réservés du format WIFI: (\ ; , : ")
+var wifiEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	`:`, `\:`,
+	`"`, `\"`,
+)
+
+// WifiPayload construit le contenu standard d'un QR code de connexion Wi-Fi,
+// reconnu par les appareils photo iOS et Android. Sans mot de passe, le réseau est ouvert.
+func WifiPayload(networkName, password string) string {
+	if password == "" {
+		return "WIFI:T:nopass;S:" + wifiEscaper.Replace(networkName) + ";;"
+	}
+	return "WIFI:T:WPA;S:" + wifiEscaper.Replace(networkName) + ";P:" + wifiEscaper.Replace(password) + ";;"
+}
@@ -0,0 +1,21 @@
+package rbac
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// OrganizationRole retourne le rôle d'un utilisateur dans une organisation, ou une chaîne
+// vide s'il n'en est pas membre
+func (s *Service) OrganizationRole(ctx context.Context, organizationID, userID primitive.ObjectID) (string, error) {
+	member, err := s.orgs.FindMember(ctx, organizationID, userID)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
@@ -0,0 +1,64 @@
+package rbac
+
+import (
+	"context"
+	"time"
+)
+
+// cacheTTL borne la durée de vie d'une entrée, au cas où un rôle serait modifié
+// par un autre processus (seed, autre instance de l'API)
+const cacheTTL = 5 * time.Minute
+
+type cacheEntry struct {
+	permissions map[string]bool
+	loadedAt    time.Time
+}
+
+// Permissions retourne l'ensemble des permissions d'un rôle, depuis le cache si possible
+func (s *Service) Permissions(ctx context.Context, roleID string) (map[string]bool, error) {
+	s.mu.RLock()
+	entry, ok := s.cache[roleID]
+	s.mu.RUnlock()
+
+	if ok && time.Since(entry.loadedAt) < cacheTTL {
+		return entry.permissions, nil
+	}
+
+	role, err := s.roles.FindByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string]bool, len(role.Permissions))
+	for _, p := range role.Permissions {
+		permissions[p] = true
+	}
+
+	s.mu.Lock()
+	s.cache[roleID] = cacheEntry{permissions: permissions, loadedAt: time.Now()}
+	s.mu.Unlock()
+
+	return permissions, nil
+}
+
+// HasPermissions vérifie qu'un rôle possède toutes les permissions demandées
+func (s *Service) HasPermissions(ctx context.Context, roleID string, required ...string) (bool, error) {
+	permissions, err := s.Permissions(ctx, roleID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range required {
+		if !permissions[p] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Invalidate retire un rôle du cache ; à appeler après chaque modification ou suppression
+func (s *Service) Invalidate(roleID string) {
+	s.mu.Lock()
+	delete(s.cache, roleID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,52 @@
+package rbac
+
+import (
+	"context"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PropertyRole retourne le rôle d'un utilisateur sur une propriété : owner pour l'hôte d'une
+// propriété personnelle, le rôle découlant de son appartenance à l'organisation détentrice,
+// son rôle de collaborateur, ou une chaîne vide s'il n'en a aucun. Le rôle le plus étendu l'emporte.
+func (s *Service) PropertyRole(ctx context.Context, property *models.Property, userID primitive.ObjectID) (string, error) {
+	role := ""
+	if property.OrganizationID == nil {
+		if userID == property.HostID {
+			return models.PropertyRoleOwner, nil
+		}
+	} else {
+		organizationRole, err := s.OrganizationRole(ctx, *property.OrganizationID, userID)
+		if err != nil {
+			return "", err
+		}
+		role = models.OrganizationPropertyRoles[organizationRole]
+		if role == models.PropertyRoleOwner {
+			return role, nil
+		}
+	}
+
+	member, err := s.members.FindByPropertyAndUser(ctx, property.ID, userID)
+	if err == mongo.ErrNoDocuments {
+		return role, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(models.PropertyRoleActions[member.Role]) > len(models.PropertyRoleActions[role]) {
+		role = member.Role
+	}
+	return role, nil
+}
+
+// CanAccessProperty indique si un utilisateur peut effectuer une action sur une propriété
+func (s *Service) CanAccessProperty(ctx context.Context, property *models.Property, userID primitive.ObjectID, action models.PropertyAction) (bool, error) {
+	role, err := s.PropertyRole(ctx, property, userID)
+	if err != nil {
+		return false, err
+	}
+	return models.PropertyRoleAllows(role, action), nil
+}
@@ -0,0 +1,29 @@
+package rbac
+
+import (
+	"sync"
+
+	"onestay-back/internal/repository"
+)
+
+// Service résout les permissions globales et les rôles sur les propriétés et les
+// organisations. Chaque application possède le sien : ses stores et son cache ne sont
+// pas partagés.
+type Service struct {
+	roles   repository.RoleStore
+	members repository.PropertyMemberStore
+	orgs    repository.OrganizationStore
+
+	mu    sync.RWMutex
+	cache map[string]cacheEntry
+}
+
+// NewService crée un service qui consulte ces stores
+func NewService(roles repository.RoleStore, members repository.PropertyMemberStore, orgs repository.OrganizationStore) *Service {
+	return &Service{
+		roles:   roles,
+		members: members,
+		orgs:    orgs,
+		cache:   map[string]cacheEntry{},
+	}
+}
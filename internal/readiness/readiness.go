@@ -0,0 +1,153 @@
+// Package readiness vérifie qu'une propriété peut être publiée. Chaque règle est bloquante
+// (error) ou simplement signalée (warning) ; la liste complète des règles sert de checklist
+// à l'hôte pour suivre sa progression.
+package readiness
+
+import (
+	"log"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/models"
+)
+
+// Severity indique l'effet d'une règle non respectée
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // Bloque la publication
+	SeverityWarning Severity = "warning" // Signalé sans bloquer
+	SeverityOff     Severity = "off"     // Règle désactivée
+)
+
+// Rule est une condition de publication. Check retourne les chemins des champs en défaut,
+// aucun si la règle est respectée.
+type Rule struct {
+	ID       string
+	Section  string
+	Severity Severity
+	Messages map[string]string
+	Check    func(p *models.Property) []string
+}
+
+// Item est le résultat d'une règle dans la checklist. Message est renseigné par Localize.
+type Item struct {
+	Rule     string   `json:"rule"`
+	Section  string   `json:"section"`
+	Severity Severity `json:"severity"`
+	Passed   bool     `json:"passed"`
+	Message  string   `json:"message"`
+	Fields   []string `json:"fields,omitempty"`
+
+	messages map[string]string
+}
+
+// Checklist est le résultat de la vérification d'une propriété. Progress est le pourcentage
+// de règles respectées ; Errors et Warnings reprennent les règles non respectées.
+type Checklist struct {
+	Ready    bool   `json:"ready"`
+	Progress int    `json:"progress"`
+	Errors   []Item `json:"errors"`
+	Warnings []Item `json:"warnings"`
+	Items    []Item `json:"items"`
+}
+
+// Validator applique les règles de publication avec leur sévérité configurée
+type Validator struct {
+	rules []Rule
+}
+
+// New construit un validateur à partir des règles par défaut ; overrides change la sévérité
+// de certaines règles (identifiant → error, warning ou off). Les surcharges invalides sont
+// ignorées et journalisées.
+func New(overrides map[string]string) *Validator {
+	rules := defaultRules()
+
+	known := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		known[rule.ID] = i
+	}
+
+	for id, value := range overrides {
+		i, ok := known[id]
+		if !ok {
+			log.Printf("Warning: règle de publication inconnue dans PUBLICATION_RULES: %s", id)
+			continue
+		}
+		switch severity := Severity(value); severity {
+		case SeverityError, SeverityWarning, SeverityOff:
+			rules[i].Severity = severity
+		default:
+			log.Printf("Warning: sévérité invalide pour la règle %s dans PUBLICATION_RULES: %q", id, value)
+		}
+	}
+
+	active := rules[:0]
+	for _, rule := range rules {
+		if rule.Severity != SeverityOff {
+			active = append(active, rule)
+		}
+	}
+	return &Validator{rules: active}
+}
+
+// Check vérifie une propriété
+func (v *Validator) Check(p *models.Property) Checklist {
+	checklist := Checklist{
+		Ready:    true,
+		Errors:   []Item{},
+		Warnings: []Item{},
+		Items:    make([]Item, 0, len(v.rules)),
+	}
+
+	passed := 0
+	for _, rule := range v.rules {
+		fields := rule.Check(p)
+		item := Item{
+			Rule:     rule.ID,
+			Section:  rule.Section,
+			Severity: rule.Severity,
+			Passed:   len(fields) == 0,
+			Fields:   fields,
+			messages: rule.Messages,
+		}
+		checklist.Items = append(checklist.Items, item)
+
+		switch {
+		case item.Passed:
+			passed++
+		case rule.Severity == SeverityError:
+			checklist.Ready = false
+			checklist.Errors = append(checklist.Errors, item)
+		default:
+			checklist.Warnings = append(checklist.Warnings, item)
+		}
+	}
+
+	checklist.Progress = 100
+	if len(v.rules) > 0 {
+		checklist.Progress = passed * 100 / len(v.rules)
+	}
+	return checklist
+}
+
+// Localize rédige les messages de la checklist dans la langue demandée
+func (c *Checklist) Localize(locale string) {
+	for _, items := range [][]Item{c.Items, c.Errors, c.Warnings} {
+		for i := range items {
+			msg, ok := items[i].messages[locale]
+			if !ok {
+				msg = items[i].messages[apierror.DefaultLocale]
+			}
+			items[i].Message = msg
+		}
+	}
+}
+
+// FailedRules retourne les identifiants des règles bloquantes non respectées
+func (c *Checklist) FailedRules() []string {
+	rules := make([]string, 0, len(c.Errors))
+	for _, item := range c.Errors {
+		rules = append(rules, item.Rule)
+	}
+	return rules
+}
@@ -0,0 +1,286 @@
+package readiness
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"onestay-back/internal/models"
+)
+
+// recommendedPhotos est le nombre de photos en dessous duquel un avertissement est émis
+const recommendedPhotos = 3
+
+// Numéro de téléphone : chiffres, espaces et séparateurs usuels, indicatif international facultatif
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 .\-()]{4,22}[0-9]$`)
+
+// defaultRules retourne les règles de publication avec leur sévérité par défaut
+func defaultRules() []Rule {
+	return []Rule{
+		{
+			ID:       "photos.required",
+			Section:  "photos",
+			Severity: SeverityError,
+			Messages: map[string]string{
+				"fr": "Ajoutez au moins une photo",
+				"en": "Add at least one photo",
+			},
+			Check: func(p *models.Property) []string {
+				if len(p.Photos)+len(p.Images) == 0 {
+					return []string{"photos"}
+				}
+				return nil
+			},
+		},
+		{
+			ID:       "photos.minimum",
+			Section:  "photos",
+			Severity: SeverityWarning,
+			Messages: map[string]string{
+				"fr": fmt.Sprintf("Ajoutez au moins %d photos pour bien présenter le logement", recommendedPhotos),
+				"en": fmt.Sprintf("Add at least %d photos to showcase the property", recommendedPhotos),
+			},
+			Check: func(p *models.Property) []string {
+				if len(p.Photos)+len(p.Images) < recommendedPhotos {
+					return []string{"photos"}
+				}
+				return nil
+			},
+		},
+		{
+			ID:       "general.description",
+			Section:  "general",
+			Severity: SeverityWarning,
+			Messages: map[string]string{
+				"fr": "Ajoutez une description du logement",
+				"en": "Add a description of the property",
+			},
+			Check: func(p *models.Property) []string {
+				if strings.TrimSpace(p.Description) == "" {
+					return []string{"description"}
+				}
+				return nil
+			},
+		},
+		{
+			ID:       "general.location",
+			Section:  "general",
+			Severity: SeverityWarning,
+			Messages: map[string]string{
+				"fr": "Renseignez la position du logement pour la carte et les distances",
+				"en": "Set the property location for the map and distances",
+			},
+			Check: func(p *models.Property) []string {
+				if p.Location == nil {
+					return []string{"location"}
+				}
+				return nil
+			},
+		},
+		{
+			ID:       "checkInOut.timeFormat",
+			Section:  "checkInOut",
+			Severity: SeverityError,
+			Messages: map[string]string{
+				"fr": "Les heures d'arrivée et de départ doivent être au format HH:mm",
+				"en": "Check-in and check-out times must use the HH:mm format",
+			},
+			Check: func(p *models.Property) []string {
+				if p.CheckInOut == nil || !p.CheckInOut.Enabled {
+					return nil
+				}
+				var fields []string
+				if _, ok := parseClock(p.CheckInOut.CheckInTime); !ok {
+					fields = append(fields, "checkInOut.checkInTime")
+				}
+				if _, ok := parseClock(p.CheckInOut.CheckOutTime); !ok {
+					fields = append(fields, "checkInOut.checkOutTime")
+				}
+				return fields
+			},
+		},
+		{
+			ID:       "checkInOut.timeOrder",
+			Section:  "checkInOut",
+			Severity: SeverityError,
+			Messages: map[string]string{
+				"fr": "L'heure de départ doit précéder l'heure d'arrivée : le départ a lieu le lendemain, avant l'arrivée des voyageurs suivants",
+				"en": "Check-out time must be earlier than check-in time: guests leave the next day, before the next guests arrive",
+			},
+			Check: func(p *models.Property) []string {
+				if p.CheckInOut == nil || !p.CheckInOut.Enabled {
+					return nil
+				}
+				// Un format invalide est déjà signalé par checkInOut.timeFormat
+				checkIn, okIn := parseClock(p.CheckInOut.CheckInTime)
+				checkOut, okOut := parseClock(p.CheckInOut.CheckOutTime)
+				if okIn && okOut && checkOut >= checkIn {
+					return []string{"checkInOut.checkInTime", "checkInOut.checkOutTime"}
+				}
+				return nil
+			},
+		},
+		{
+			ID:       "wifi.network",
+			Section:  "wifi",
+			Severity: SeverityWarning,
+			Messages: map[string]string{
+				"fr": "Renseignez le nom du réseau Wi-Fi",
+				"en": "Enter the Wi-Fi network name",
+			},
+			Check: func(p *models.Property) []string {
+				if p.Wifi != nil && p.Wifi.Enabled && strings.TrimSpace(p.Wifi.NetworkName) == "" {
+					return []string{"wifi.networkName"}
+				}
+				return nil
+			},
+		},
+		{
+			ID:       "rules.maxGuests",
+			Section:  "rules",
+			Severity: SeverityWarning,
+			Messages: map[string]string{
+				"fr": "Indiquez le nombre maximum de voyageurs",
+				"en": "Enter the maximum number of guests",
+			},
+			Check: func(p *models.Property) []string {
+				if p.Rules != nil && p.Rules.Enabled && (p.Rules.MaxGuests == nil || *p.Rules.MaxGuests < 1) {
+					return []string{"rules.maxGuests"}
+				}
+				return nil
+			},
+		},
+		{
+			ID:       "contacts.required",
+			Section:  "contacts",
+			Severity: SeverityError,
+			Messages: map[string]string{
+				"fr": "Ajoutez au moins un contact ou désactivez la section",
+				"en": "Add at least one contact or disable the section",
+			},
+			Check: func(p *models.Property) []string {
+				if p.Contacts != nil && p.Contacts.Enabled && len(p.Contacts.Contacts) == 0 {
+					return []string{"contacts.contacts"}
+				}
+				return nil
+			},
+		},
+		{
+			ID:       "contacts.phone",
+			Section:  "contacts",
+			Severity: SeverityError,
+			Messages: map[string]string{
+				"fr": "Numéro de téléphone de contact invalide",
+				"en": "Invalid contact phone number",
+			},
+			Check: func(p *models.Property) []string {
+				if p.Contacts == nil || !p.Contacts.Enabled {
+					return nil
+				}
+				var fields []string
+				for i, contact := range p.Contacts.Contacts {
+					if !validPhone(contact.Phone) {
+						fields = append(fields, fmt.Sprintf("contacts.contacts[%d].phone", i))
+					}
+				}
+				return fields
+			},
+		},
+		{
+			ID:       "contacts.email",
+			Section:  "contacts",
+			Severity: SeverityError,
+			Messages: map[string]string{
+				"fr": "Adresse email de contact invalide",
+				"en": "Invalid contact email address",
+			},
+			Check: func(p *models.Property) []string {
+				if p.Contacts == nil || !p.Contacts.Enabled {
+					return nil
+				}
+				var fields []string
+				for i, contact := range p.Contacts.Contacts {
+					if contact.Email != "" && !validEmail(contact.Email) {
+						fields = append(fields, fmt.Sprintf("contacts.contacts[%d].email", i))
+					}
+				}
+				return fields
+			},
+		},
+		{
+			ID:       "recommendations.rating",
+			Section:  "localRecommendations",
+			Severity: SeverityError,
+			Messages: map[string]string{
+				"fr": "La note d'une recommandation doit être comprise entre 1 et 5",
+				"en": "A recommendation rating must be between 1 and 5",
+			},
+			Check: func(p *models.Property) []string {
+				if p.LocalRecommendations == nil || !p.LocalRecommendations.Enabled {
+					return nil
+				}
+				var fields []string
+				for i, rec := range p.LocalRecommendations.Recommendations {
+					if rec.Rating != nil && (*rec.Rating < 1 || *rec.Rating > 5) {
+						fields = append(fields, fmt.Sprintf("localRecommendations.recommendations[%d].rating", i))
+					}
+				}
+				return fields
+			},
+		},
+		{
+			ID:       "recommendations.phone",
+			Section:  "localRecommendations",
+			Severity: SeverityError,
+			Messages: map[string]string{
+				"fr": "Numéro de téléphone de recommandation invalide",
+				"en": "Invalid recommendation phone number",
+			},
+			Check: func(p *models.Property) []string {
+				if p.LocalRecommendations == nil || !p.LocalRecommendations.Enabled {
+					return nil
+				}
+				var fields []string
+				for i, rec := range p.LocalRecommendations.Recommendations {
+					if rec.Phone != "" && !validPhone(rec.Phone) {
+						fields = append(fields, fmt.Sprintf("localRecommendations.recommendations[%d].phone", i))
+					}
+				}
+				return fields
+			},
+		},
+	}
+}
+
+// parseClock lit une heure "HH:mm" et retourne le nombre de minutes depuis minuit
+func parseClock(value string) (int, bool) {
+	if len(value) != 5 {
+		return 0, false
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func validPhone(value string) bool {
+	if !phonePattern.MatchString(value) {
+		return false
+	}
+	digits := 0
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 6 && digits <= 15
+}
+
+// validEmail accepte une adresse seule, sans nom affiché ("Nom <adresse>")
+func validEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	return err == nil && addr.Address == value && addr.Name == ""
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type CalendarBlockRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCalendarBlockRepository(db *mongo.Database) *CalendarBlockRepository {
+	return &CalendarBlockRepository{
+		collection: db.Collection("calendar_blocks"),
+	}
+}
+
+// Create crée une période bloquée
+func (r *CalendarBlockRepository) Create(ctx context.Context, block *models.CalendarBlock) error {
+	block.ID = primitive.NewObjectID()
+	block.CreatedAt = time.Now()
+	block.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, block)
+	return err
+}
+
+// FindByID trouve une période bloquée par son ID
+func (r *CalendarBlockRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.CalendarBlock, error) {
+	var block models.CalendarBlock
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&block)
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// FindByPropertyID liste les périodes bloquées d'une propriété, triées par date de début.
+// Les bornes from/to (optionnelles) filtrent les périodes qui chevauchent l'intervalle.
+func (r *CalendarBlockRepository) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, from, to *time.Time) ([]models.CalendarBlock, error) {
+	filter := bson.M{"propertyId": propertyID}
+	if from != nil {
+		filter["endDate"] = bson.M{"$gt": *from}
+	}
+	if to != nil {
+		filter["startDate"] = bson.M{"$lt": *to}
+	}
+
+	opts := options.Find().SetSort(bson.M{"startDate": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blocks := []models.CalendarBlock{}
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// HasOverlap vérifie si une période bloquée chevauche l'intervalle [start, end[
+func (r *CalendarBlockRepository) HasOverlap(ctx context.Context, propertyID primitive.ObjectID, start, end time.Time) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"propertyId": propertyID,
+		"startDate":  bson.M{"$lt": end},
+		"endDate":    bson.M{"$gt": start},
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ReplaceFeedBlocks remplace l'ensemble des périodes importées d'un calendrier externe
+func (r *CalendarBlockRepository) ReplaceFeedBlocks(ctx context.Context, feedID primitive.ObjectID, blocks []models.CalendarBlock) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"feedId": feedID}); err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	documents := make([]interface{}, len(blocks))
+	for i := range blocks {
+		blocks[i].ID = primitive.NewObjectID()
+		blocks[i].FeedID = &feedID
+		blocks[i].Source = models.CalendarBlockSourceICal
+		blocks[i].CreatedAt = now
+		blocks[i].UpdatedAt = now
+		documents[i] = blocks[i]
+	}
+
+	_, err := r.collection.InsertMany(ctx, documents)
+	return err
+}
+
+// Delete supprime une période bloquée
+func (r *CalendarBlockRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// DeleteByFeedID supprime les périodes importées d'un calendrier externe
+func (r *CalendarBlockRepository) DeleteByFeedID(ctx context.Context, feedID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"feedId": feedID})
+	return err
+}
+
+// DeleteByPropertyID supprime toutes les périodes bloquées d'une propriété
+func (r *CalendarBlockRepository) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"propertyId": propertyID})
+	return err
+}
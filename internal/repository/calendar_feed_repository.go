@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type CalendarFeedRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCalendarFeedRepository(db *mongo.Database) *CalendarFeedRepository {
+	return &CalendarFeedRepository{
+		collection: db.Collection("calendar_feeds"),
+	}
+}
+
+// Create enregistre un calendrier externe
+func (r *CalendarFeedRepository) Create(ctx context.Context, feed *models.CalendarFeed) error {
+	feed.ID = primitive.NewObjectID()
+	feed.CreatedAt = time.Now()
+	feed.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, feed)
+	return err
+}
+
+// FindByID trouve un calendrier externe par son ID
+func (r *CalendarFeedRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&feed)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// FindByPropertyID liste les calendriers externes d'une propriété
+func (r *CalendarFeedRepository) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.CalendarFeed, error) {
+	return r.find(ctx, bson.M{"propertyId": propertyID})
+}
+
+// FindAll liste tous les calendriers externes, les moins récemment synchronisés en premier
+func (r *CalendarFeedRepository) FindAll(ctx context.Context) ([]models.CalendarFeed, error) {
+	return r.find(ctx, bson.M{}, options.Find().SetSort(bson.M{"lastSyncedAt": 1}))
+}
+
+func (r *CalendarFeedRepository) find(ctx context.Context, filter bson.M, opts ...options.Lister[options.FindOptions]) ([]models.CalendarFeed, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	feeds := []models.CalendarFeed{}
+	if err := cursor.All(ctx, &feeds); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// UpdateSyncStatus enregistre le résultat d'une synchronisation
+func (r *CalendarFeedRepository) UpdateSyncStatus(ctx context.Context, id primitive.ObjectID, status, syncError string, eventCount int) error {
+	now := time.Now()
+	set := bson.M{
+		"lastSyncedAt":   now,
+		"lastSyncStatus": status,
+		"updatedAt":      now,
+	}
+	update := bson.M{"$set": set}
+
+	if syncError != "" {
+		// En cas d'échec, les périodes importées précédemment sont conservées
+		set["lastSyncError"] = syncError
+	} else {
+		set["eventCount"] = eventCount
+		update["$unset"] = bson.M{"lastSyncError": ""}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Delete supprime un calendrier externe
+func (r *CalendarFeedRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// DeleteByPropertyID supprime les calendriers externes d'une propriété
+func (r *CalendarFeedRepository) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"propertyId": propertyID})
+	return err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// calendarLockLease borne la durée d'un verrou dont le détenteur aurait disparu
+	calendarLockLease = 30 * time.Second
+	// calendarLockWait borne l'attente d'un verrou détenu par une autre requête
+	calendarLockWait = 5 * time.Second
+	calendarLockPoll = 25 * time.Millisecond
+)
+
+// ErrCalendarBusy indique que le calendrier est resté verrouillé pendant toute l'attente
+var ErrCalendarBusy = errors.New("calendrier en cours de modification")
+
+// CalendarLockRepository verrouille le calendrier d'une propriété à l'aide d'un document
+// par propriété : son insertion échoue sur la clé _id tant qu'un autre détenteur l'occupe.
+// Le verrou est partagé entre toutes les instances de l'API.
+type CalendarLockRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCalendarLockRepository(db *mongo.Database) *CalendarLockRepository {
+	return &CalendarLockRepository{
+		collection: db.Collection("calendar_locks"),
+	}
+}
+
+// Lock attend le verrou du calendrier de la propriété. Un verrou dont le bail a expiré est
+// repris ; au-delà de calendarLockWait, ErrCalendarBusy est retourné.
+func (r *CalendarLockRepository) Lock(ctx context.Context, propertyID primitive.ObjectID) (func(), error) {
+	owner := primitive.NewObjectID()
+	deadline := time.Now().Add(calendarLockWait)
+
+	for {
+		acquired, err := r.tryLock(ctx, propertyID, owner)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return func() {
+				// Le verrou est libéré même si la requête a été annulée entre-temps
+				r.collection.DeleteOne(context.Background(), bson.M{"_id": propertyID, "owner": owner})
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, ErrCalendarBusy
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(calendarLockPoll):
+		}
+	}
+}
+
+func (r *CalendarLockRepository) tryLock(ctx context.Context, propertyID, owner primitive.ObjectID) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(calendarLockLease)
+
+	_, err := r.collection.InsertOne(ctx, bson.M{"_id": propertyID, "owner": owner, "expiresAt": expiresAt})
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+
+	// Reprise d'un verrou abandonné (processus arrêté avant de le libérer)
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": propertyID, "expiresAt": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"owner": owner, "expiresAt": expiresAt}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
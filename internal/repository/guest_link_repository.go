@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type GuestLinkRepository struct {
+	collection *mongo.Collection
+	accesses   *mongo.Collection
+}
+
+func NewGuestLinkRepository(db *mongo.Database) *GuestLinkRepository {
+	return &GuestLinkRepository{
+		collection: db.Collection("guest_links"),
+		accesses:   db.Collection("guest_link_accesses"),
+	}
+}
+
+// Create crée un nouveau lien voyageur
+func (r *GuestLinkRepository) Create(ctx context.Context, link *models.GuestLink) error {
+	link.ID = primitive.NewObjectID()
+	link.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, link)
+	return err
+}
+
+// FindByID trouve un lien par son ID
+func (r *GuestLinkRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.GuestLink, error) {
+	var link models.GuestLink
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&link)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindByTokenHash trouve un lien par l'empreinte de son token
+func (r *GuestLinkRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*models.GuestLink, error) {
+	var link models.GuestLink
+	err := r.collection.FindOne(ctx, bson.M{"tokenHash": tokenHash}).Decode(&link)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindByPropertyID liste les liens d'une propriété, du plus récent au plus ancien
+func (r *GuestLinkRepository) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.GuestLink, error) {
+	opts := options.Find().SetSort(bson.M{"createdAt": -1})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"propertyId": propertyID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	links := []models.GuestLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// Revoke révoque un lien
+func (r *GuestLinkRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now()}},
+	)
+	return err
+}
+
+// DeleteByPropertyID supprime les liens et l'historique d'une propriété
+func (r *GuestLinkRepository) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"propertyId": propertyID}); err != nil {
+		return err
+	}
+	_, err := r.accesses.DeleteMany(ctx, bson.M{"propertyId": propertyID})
+	return err
+}
+
+// RecordUse incrémente le compteur d'utilisation d'un lien
+func (r *GuestLinkRepository) RecordUse(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$inc": bson.M{"useCount": 1},
+			"$set": bson.M{"lastUsedAt": time.Now()},
+		},
+	)
+	return err
+}
+
+// RecordFailedAttempt incrémente le compteur de PIN erronés
+func (r *GuestLinkRepository) RecordFailedAttempt(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{"failedAttempts": 1}},
+	)
+	return err
+}
+
+// LogAccess enregistre une utilisation du lien dans l'historique
+func (r *GuestLinkRepository) LogAccess(ctx context.Context, access *models.GuestLinkAccess) error {
+	access.ID = primitive.NewObjectID()
+	access.AccessedAt = time.Now()
+
+	_, err := r.accesses.InsertOne(ctx, access)
+	return err
+}
+
+// FindAccessesByLinkID retourne l'historique d'utilisation d'un lien
+func (r *GuestLinkRepository) FindAccessesByLinkID(ctx context.Context, linkID primitive.ObjectID, limit int64) ([]models.GuestLinkAccess, error) {
+	opts := options.Find().
+		SetSort(bson.M{"accessedAt": -1}).
+		SetLimit(limit)
+
+	cursor, err := r.accesses.Find(ctx, bson.M{"linkId": linkID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	accesses := []models.GuestLinkAccess{}
+	if err := cursor.All(ctx, &accesses); err != nil {
+		return nil, err
+	}
+	return accesses, nil
+}
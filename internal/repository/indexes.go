@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// EnsureIndexes crée les index MongoDB nécessaires à l'application (opération idempotente)
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	// La création des index ne lit ni n'écrit de secret : aucun trousseau n'est nécessaire
+	if err := NewPropertyRepository(db, nil).EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("index des propriétés: %w", err)
+	}
+	if err := NewPropertyRevisionRepository(db, nil).EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("index de l'historique des propriétés: %w", err)
+	}
+	if err := NewPropertyDraftRepository(db, nil).EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("index des brouillons des propriétés: %w", err)
+	}
+	if err := NewSectionTemplateRepository(db, nil).EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("index des modèles de section: %w", err)
+	}
+	if err := NewPropertyMemberRepository(db).EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("index des collaborateurs des propriétés: %w", err)
+	}
+	if err := NewOrganizationRepository(db).EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("index des organisations: %w", err)
+	}
+	return nil
+}
+
+// requiredIndexes liste, par collection, les index sans lesquels l'API ne fonctionne pas
+// correctement : contraintes d'unicité, recherche plein texte et géographique
+var requiredIndexes = map[string][]string{
+	"properties":           {"property_text_search", "location_2dsphere"},
+	"property_revisions":   {"propertyId_1_number_-1"},
+	"property_drafts":      {"propertyId_1"},
+	"property_members":     {"propertyId_1_userId_1"},
+	"property_invitations": {"tokenHash_1"},
+	"organizations":        {"slug_1"},
+	"organization_members": {"organizationId_1_userId_1"},
+}
+
+// MissingIndexes retourne les index requis absents, sous la forme collection.index
+func MissingIndexes(ctx context.Context, db *mongo.Database) ([]string, error) {
+	var missing []string
+	for collection, names := range requiredIndexes {
+		specs, err := db.Collection(collection).Indexes().ListSpecifications(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("index de %s: %w", collection, err)
+		}
+
+		existing := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			existing = append(existing, spec.Name)
+		}
+		for _, name := range names {
+			if !slices.Contains(existing, name) {
+				missing = append(missing, collection+"."+name)
+			}
+		}
+	}
+	slices.Sort(missing)
+	return missing, nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"onestay-back/internal/database"
 	"onestay-back/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,9 +15,9 @@ type LogementRepository struct {
 	collection *mongo.Collection
 }
 
-func NewLogementRepository() *LogementRepository {
+func NewLogementRepository(db *mongo.Database) *LogementRepository {
 	return &LogementRepository{
-		collection: database.DB.Collection("logements"),
+		collection: db.Collection("logements"),
 	}
 }
 
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// CalendarBlockStore conserve les périodes bloquées en mémoire
+type CalendarBlockStore struct {
+	mu     sync.RWMutex
+	blocks []models.CalendarBlock
+}
+
+func NewCalendarBlockStore() *CalendarBlockStore {
+	return &CalendarBlockStore{}
+}
+
+func (s *CalendarBlockStore) Create(ctx context.Context, block *models.CalendarBlock) error {
+	block.ID = primitive.NewObjectID()
+	block.CreatedAt = time.Now()
+	block.UpdatedAt = block.CreatedAt
+
+	stored, err := clone(block)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.blocks = append(s.blocks, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// FindByID trouve une période bloquée par son ID
+func (s *CalendarBlockStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.CalendarBlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.blocks {
+		if s.blocks[i].ID == id {
+			return clone(&s.blocks[i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// FindByPropertyID liste les périodes bloquées d'une propriété, triées par date de début.
+// Les bornes from/to (optionnelles) filtrent les périodes qui chevauchent l'intervalle.
+func (s *CalendarBlockStore) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, from, to *time.Time) ([]models.CalendarBlock, error) {
+	s.mu.RLock()
+	var found []models.CalendarBlock
+	for _, block := range s.blocks {
+		if block.PropertyID != propertyID {
+			continue
+		}
+		if (from != nil && !block.EndDate.After(*from)) || (to != nil && !block.StartDate.Before(*to)) {
+			continue
+		}
+		found = append(found, block)
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].StartDate.Before(found[j].StartDate) })
+	return cloneAll(found)
+}
+
+// HasOverlap vérifie si une période bloquée chevauche l'intervalle [start, end[
+func (s *CalendarBlockStore) HasOverlap(ctx context.Context, propertyID primitive.ObjectID, start, end time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, block := range s.blocks {
+		if block.PropertyID == propertyID && block.StartDate.Before(end) && block.EndDate.After(start) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ReplaceFeedBlocks remplace l'ensemble des périodes importées d'un calendrier externe
+func (s *CalendarBlockStore) ReplaceFeedBlocks(ctx context.Context, feedID primitive.ObjectID, blocks []models.CalendarBlock) error {
+	now := time.Now()
+	stored := make([]models.CalendarBlock, 0, len(blocks))
+	for i := range blocks {
+		blocks[i].ID = primitive.NewObjectID()
+		blocks[i].FeedID = &feedID
+		blocks[i].Source = models.CalendarBlockSourceICal
+		blocks[i].CreatedAt = now
+		blocks[i].UpdatedAt = now
+
+		copied, err := clone(&blocks[i])
+		if err != nil {
+			return err
+		}
+		stored = append(stored, *copied)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteLocked(func(block *models.CalendarBlock) bool { return block.FeedID != nil && *block.FeedID == feedID })
+	s.blocks = append(s.blocks, stored...)
+	return nil
+}
+
+// Delete supprime une période bloquée
+func (s *CalendarBlockStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	s.delete(func(block *models.CalendarBlock) bool { return block.ID == id })
+	return nil
+}
+
+// DeleteByFeedID supprime les périodes importées d'un calendrier externe
+func (s *CalendarBlockStore) DeleteByFeedID(ctx context.Context, feedID primitive.ObjectID) error {
+	s.delete(func(block *models.CalendarBlock) bool { return block.FeedID != nil && *block.FeedID == feedID })
+	return nil
+}
+
+// DeleteByPropertyID supprime toutes les périodes bloquées d'une propriété
+func (s *CalendarBlockStore) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	s.delete(func(block *models.CalendarBlock) bool { return block.PropertyID == propertyID })
+	return nil
+}
+
+func (s *CalendarBlockStore) delete(match func(*models.CalendarBlock) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteLocked(match)
+}
+
+func (s *CalendarBlockStore) deleteLocked(match func(*models.CalendarBlock) bool) {
+	kept := s.blocks[:0]
+	for i := range s.blocks {
+		if !match(&s.blocks[i]) {
+			kept = append(kept, s.blocks[i])
+		}
+	}
+	s.blocks = kept
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// CalendarFeedStore conserve les calendriers externes en mémoire
+type CalendarFeedStore struct {
+	mu    sync.RWMutex
+	feeds []models.CalendarFeed
+}
+
+func NewCalendarFeedStore() *CalendarFeedStore {
+	return &CalendarFeedStore{}
+}
+
+func (s *CalendarFeedStore) Create(ctx context.Context, feed *models.CalendarFeed) error {
+	feed.ID = primitive.NewObjectID()
+	feed.CreatedAt = time.Now()
+	feed.UpdatedAt = feed.CreatedAt
+
+	stored, err := clone(feed)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.feeds = append(s.feeds, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// FindByID trouve un calendrier externe par son ID
+func (s *CalendarFeedStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.CalendarFeed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.feeds {
+		if s.feeds[i].ID == id {
+			return clone(&s.feeds[i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// FindByPropertyID liste les calendriers externes d'une propriété
+func (s *CalendarFeedStore) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.CalendarFeed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var found []models.CalendarFeed
+	for _, feed := range s.feeds {
+		if feed.PropertyID == propertyID {
+			found = append(found, feed)
+		}
+	}
+	return cloneAll(found)
+}
+
+// FindAll liste tous les calendriers externes, les moins récemment synchronisés en premier
+func (s *CalendarFeedStore) FindAll(ctx context.Context) ([]models.CalendarFeed, error) {
+	s.mu.RLock()
+	found, err := cloneAll(s.feeds)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].LastSyncedAt == nil || found[j].LastSyncedAt == nil {
+			return found[i].LastSyncedAt == nil && found[j].LastSyncedAt != nil
+		}
+		return found[i].LastSyncedAt.Before(*found[j].LastSyncedAt)
+	})
+	return found, nil
+}
+
+// UpdateSyncStatus enregistre le résultat d'une synchronisation
+func (s *CalendarFeedStore) UpdateSyncStatus(ctx context.Context, id primitive.ObjectID, status, syncError string, eventCount int) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.feeds {
+		feed := &s.feeds[i]
+		if feed.ID != id {
+			continue
+		}
+		feed.LastSyncedAt = &now
+		feed.LastSyncStatus = status
+		feed.UpdatedAt = now
+		if syncError != "" {
+			// En cas d'échec, les périodes importées précédemment sont conservées
+			feed.LastSyncError = syncError
+		} else {
+			feed.EventCount = eventCount
+			feed.LastSyncError = ""
+		}
+	}
+	return nil
+}
+
+// Delete supprime un calendrier externe
+func (s *CalendarFeedStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	s.delete(func(feed *models.CalendarFeed) bool { return feed.ID == id })
+	return nil
+}
+
+// DeleteByPropertyID supprime les calendriers externes d'une propriété
+func (s *CalendarFeedStore) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	s.delete(func(feed *models.CalendarFeed) bool { return feed.PropertyID == propertyID })
+	return nil
+}
+
+func (s *CalendarFeedStore) delete(match func(*models.CalendarFeed) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.feeds[:0]
+	for i := range s.feeds {
+		if !match(&s.feeds[i]) {
+			kept = append(kept, s.feeds[i])
+		}
+	}
+	s.feeds = kept
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalendarLockStore verrouille les calendriers des propriétés au sein du processus
+type CalendarLockStore struct {
+	mu    sync.Mutex
+	locks map[primitive.ObjectID]chan struct{}
+}
+
+func NewCalendarLockStore() *CalendarLockStore {
+	return &CalendarLockStore{locks: map[primitive.ObjectID]chan struct{}{}}
+}
+
+// Lock attend le verrou du calendrier de la propriété ou l'annulation du contexte
+func (s *CalendarLockStore) Lock(ctx context.Context, propertyID primitive.ObjectID) (func(), error) {
+	s.mu.Lock()
+	lock, ok := s.locks[propertyID]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		s.locks[propertyID] = lock
+	}
+	s.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
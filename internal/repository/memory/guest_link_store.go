@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// GuestLinkStore conserve en mémoire les liens voyageurs et leur historique d'utilisation
+type GuestLinkStore struct {
+	mu       sync.RWMutex
+	links    []models.GuestLink
+	accesses []models.GuestLinkAccess
+}
+
+func NewGuestLinkStore() *GuestLinkStore {
+	return &GuestLinkStore{}
+}
+
+func (s *GuestLinkStore) Create(ctx context.Context, link *models.GuestLink) error {
+	link.ID = primitive.NewObjectID()
+	link.CreatedAt = time.Now()
+
+	stored, err := clone(link)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.links = append(s.links, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// FindByID trouve un lien par son ID
+func (s *GuestLinkStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.GuestLink, error) {
+	return s.find(func(link *models.GuestLink) bool { return link.ID == id })
+}
+
+// FindByTokenHash trouve un lien par l'empreinte de son token
+func (s *GuestLinkStore) FindByTokenHash(ctx context.Context, tokenHash string) (*models.GuestLink, error) {
+	return s.find(func(link *models.GuestLink) bool { return link.TokenHash == tokenHash })
+}
+
+// FindByPropertyID liste les liens d'une propriété, du plus récent au plus ancien
+func (s *GuestLinkStore) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.GuestLink, error) {
+	s.mu.RLock()
+	var found []models.GuestLink
+	for _, link := range s.links {
+		if link.PropertyID == propertyID {
+			found = append(found, link)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].CreatedAt.After(found[j].CreatedAt) })
+	return cloneAll(found)
+}
+
+// Revoke révoque un lien
+func (s *GuestLinkStore) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	s.update(id, func(link *models.GuestLink) {
+		if link.RevokedAt == nil {
+			link.RevokedAt = &now
+		}
+	})
+	return nil
+}
+
+// DeleteByPropertyID supprime les liens et l'historique d'une propriété
+func (s *GuestLinkStore) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	links := s.links[:0]
+	for _, link := range s.links {
+		if link.PropertyID != propertyID {
+			links = append(links, link)
+		}
+	}
+	s.links = links
+
+	accesses := s.accesses[:0]
+	for _, access := range s.accesses {
+		if access.PropertyID != propertyID {
+			accesses = append(accesses, access)
+		}
+	}
+	s.accesses = accesses
+	return nil
+}
+
+// RecordUse incrémente le compteur d'utilisation d'un lien
+func (s *GuestLinkStore) RecordUse(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	s.update(id, func(link *models.GuestLink) {
+		link.UseCount++
+		link.LastUsedAt = &now
+	})
+	return nil
+}
+
+// RecordFailedAttempt incrémente le compteur de PIN erronés
+func (s *GuestLinkStore) RecordFailedAttempt(ctx context.Context, id primitive.ObjectID) error {
+	s.update(id, func(link *models.GuestLink) { link.FailedAttempts++ })
+	return nil
+}
+
+// LogAccess enregistre une utilisation du lien dans l'historique
+func (s *GuestLinkStore) LogAccess(ctx context.Context, access *models.GuestLinkAccess) error {
+	access.ID = primitive.NewObjectID()
+	access.AccessedAt = time.Now()
+
+	stored, err := clone(access)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.accesses = append(s.accesses, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// FindAccessesByLinkID retourne l'historique d'utilisation d'un lien, du plus récent au plus ancien
+func (s *GuestLinkStore) FindAccessesByLinkID(ctx context.Context, linkID primitive.ObjectID, limit int64) ([]models.GuestLinkAccess, error) {
+	s.mu.RLock()
+	var found []models.GuestLinkAccess
+	for i := len(s.accesses) - 1; i >= 0; i-- {
+		if s.accesses[i].LinkID == linkID {
+			found = append(found, s.accesses[i])
+		}
+	}
+	s.mu.RUnlock()
+
+	if limit > 0 && int64(len(found)) > limit {
+		found = found[:limit]
+	}
+	return cloneAll(found)
+}
+
+func (s *GuestLinkStore) find(match func(*models.GuestLink) bool) (*models.GuestLink, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.links {
+		if match(&s.links[i]) {
+			return clone(&s.links[i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *GuestLinkStore) update(id primitive.ObjectID, modify func(*models.GuestLink)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.links {
+		if s.links[i].ID == id {
+			modify(&s.links[i])
+		}
+	}
+}
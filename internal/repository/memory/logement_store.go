@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LogementStore conserve les logements en mémoire
+type LogementStore struct {
+	mu        sync.RWMutex
+	logements []models.Logement
+}
+
+func NewLogementStore() *LogementStore {
+	return &LogementStore{}
+}
+
+func (s *LogementStore) Create(ctx context.Context, logement *models.Logement) error {
+	logement.ID = primitive.NewObjectID()
+	logement.CreatedAt = time.Now()
+	logement.UpdatedAt = time.Now()
+
+	stored, err := clone(logement)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.logements = append(s.logements, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *LogementStore) ExistsByNomBienAndUserID(ctx context.Context, nomBien string, userID primitive.ObjectID) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, logement := range s.logements {
+		if logement.NomBien == nomBien && logement.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *LogementStore) FindByUserID(ctx context.Context, userID primitive.ObjectID, includeBrouillon bool) ([]models.Logement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var logements []models.Logement
+	for _, logement := range s.logements {
+		// Sans les brouillons, seuls les logements publiés (status = 2) sont retournés
+		if logement.UserID == userID && (includeBrouillon || logement.Status == 2) {
+			logements = append(logements, logement)
+		}
+	}
+	return cloneAll(logements)
+}
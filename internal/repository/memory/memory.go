@@ -0,0 +1,202 @@
+// Package memory implémente les stores du package repository en mémoire. Sans dépendance
+// à MongoDB, il permet de tester les handlers ; les documents sont copiés en profondeur
+// à chaque lecture et écriture, comme s'ils faisaient l'aller-retour avec la base.
+// Tous les stores peuvent être utilisés depuis plusieurs goroutines.
+package memory
+
+import (
+	"strings"
+
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// NewStores construit un jeu de stores vides. Les secrets des propriétés sont chiffrés avec
+// keyring ; nil les laisse en clair. Les modèles de section n'ont pas d'implémentation en
+// mémoire : ce store reste nil.
+func NewStores(keyring *fieldcrypt.Keyring) *repository.Stores {
+	guestLinks := NewGuestLinkStore()
+	reservations := NewReservationStore()
+	blocks := NewCalendarBlockStore()
+	feeds := NewCalendarFeedStore()
+
+	return &repository.Stores{
+		Users:                NewUserStore(),
+		Roles:                NewRoleStore(),
+		Properties:           NewPropertyStore(keyring),
+		Logements:            NewLogementStore(),
+		Sessions:             NewSessionStore(),
+		UserTokens:           NewUserTokenStore(),
+		PropertyMembers:      NewPropertyMemberStore(),
+		Organizations:        NewOrganizationStore(),
+		PropertyDrafts:       NewPropertyDraftStore(),
+		PropertyRevisions:    NewPropertyRevisionStore(),
+		SectionTemplateLinks: NewSectionTemplateLinkStore(),
+		GuestLinks:           guestLinks,
+		Reservations:         reservations,
+		CalendarBlocks:       blocks,
+		CalendarFeeds:        feeds,
+		CalendarLocks:        NewCalendarLockStore(),
+		PropertyData:         []repository.PropertyDataCleaner{guestLinks, reservations, blocks, feeds},
+	}
+}
+
+// clone copie un document en profondeur en passant par sa représentation BSON
+func clone[T any](doc *T) (*T, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied T
+	if err := bson.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+// cloneAll copie une liste de documents
+func cloneAll[T any](docs []T) ([]T, error) {
+	copies := make([]T, 0, len(docs))
+	for i := range docs {
+		copied, err := clone(&docs[i])
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, *copied)
+	}
+	return copies, nil
+}
+
+// apply retourne une copie du document à laquelle sont appliqués un $set et un $unset.
+// Les clés pointées ("content.wifi") désignent des champs de sous-documents.
+func apply[T any](doc *T, set bson.M, unset ...string) (*T, error) {
+	fields, err := toFields(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range set {
+		setPath(fields, key, value)
+	}
+	for _, key := range unset {
+		unsetPath(fields, key)
+	}
+
+	data, err := bson.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated T
+	if err := bson.Unmarshal(data, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// toFields retourne la représentation BSON d'un document sous forme de map
+func toFields(doc interface{}) (bson.M, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := bson.M{}
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func setPath(fields bson.M, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child := subDocument(fields[key])
+		if child == nil {
+			child = bson.M{}
+		}
+		fields[key] = child
+		fields = child
+	}
+	fields[keys[len(keys)-1]] = value
+}
+
+func unsetPath(fields bson.M, path string) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child := subDocument(fields[key])
+		if child == nil {
+			return
+		}
+		fields[key] = child
+		fields = child
+	}
+	delete(fields, keys[len(keys)-1])
+}
+
+// lookup retourne les valeurs désignées par un chemin pointé, en parcourant les tableaux
+// comme le fait MongoDB ("equipment.items.category")
+func lookup(value interface{}, path string) []interface{} {
+	if path == "" {
+		return []interface{}{value}
+	}
+
+	if values, ok := value.(primitive.A); ok {
+		var found []interface{}
+		for _, item := range values {
+			found = append(found, lookup(item, path)...)
+		}
+		return found
+	}
+
+	fields := subDocument(value)
+	if fields == nil {
+		return nil
+	}
+
+	key, rest, _ := strings.Cut(path, ".")
+	child, ok := fields[key]
+	if !ok {
+		return nil
+	}
+	return lookup(child, rest)
+}
+
+func subDocument(value interface{}) bson.M {
+	switch doc := value.(type) {
+	case bson.M:
+		return doc
+	case bson.D:
+		return doc.Map()
+	}
+	return nil
+}
+
+// duplicateKeyError reproduit l'erreur renvoyée par MongoDB lorsqu'un index unique est violé
+func duplicateKeyError() error {
+	return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key error"}}}
+}
+
+var (
+	_ repository.UserStore                = (*UserStore)(nil)
+	_ repository.RoleStore                = (*RoleStore)(nil)
+	_ repository.PropertyStore            = (*PropertyStore)(nil)
+	_ repository.LogementStore            = (*LogementStore)(nil)
+	_ repository.SessionStore             = (*SessionStore)(nil)
+	_ repository.UserTokenStore           = (*UserTokenStore)(nil)
+	_ repository.PropertyMemberStore      = (*PropertyMemberStore)(nil)
+	_ repository.OrganizationStore        = (*OrganizationStore)(nil)
+	_ repository.PropertyDraftStore       = (*PropertyDraftStore)(nil)
+	_ repository.PropertyRevisionStore    = (*PropertyRevisionStore)(nil)
+	_ repository.SectionTemplateLinkStore = (*SectionTemplateLinkStore)(nil)
+	_ repository.GuestLinkStore           = (*GuestLinkStore)(nil)
+	_ repository.ReservationStore         = (*ReservationStore)(nil)
+	_ repository.CalendarBlockStore       = (*CalendarBlockStore)(nil)
+	_ repository.CalendarFeedStore        = (*CalendarFeedStore)(nil)
+	_ repository.CalendarLockStore        = (*CalendarLockStore)(nil)
+)
@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// OrganizationStore conserve en mémoire les organisations et leurs membres
+type OrganizationStore struct {
+	mu            sync.RWMutex
+	organizations []models.Organization
+	members       []models.OrganizationMember
+}
+
+func NewOrganizationStore() *OrganizationStore {
+	return &OrganizationStore{}
+}
+
+// Create enregistre une organisation ; un slug déjà utilisé fait échouer l'insertion (clé dupliquée)
+func (s *OrganizationStore) Create(ctx context.Context, organization *models.Organization) error {
+	organization.ID = primitive.NewObjectID()
+	organization.CreatedAt = time.Now()
+	organization.UpdatedAt = organization.CreatedAt
+
+	stored, err := clone(organization)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.organizations {
+		if existing.Slug == organization.Slug {
+			return duplicateKeyError()
+		}
+	}
+	s.organizations = append(s.organizations, *stored)
+	return nil
+}
+
+// ExistsBySlug vérifie si un slug d'organisation est déjà utilisé
+func (s *OrganizationStore) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
+	organizations, err := s.find(func(organization *models.Organization) bool { return organization.Slug == slug })
+	return len(organizations) > 0, err
+}
+
+// FindByID trouve une organisation par son ID
+func (s *OrganizationStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Organization, error) {
+	organizations, err := s.find(func(organization *models.Organization) bool { return organization.ID == id })
+	if err != nil {
+		return nil, err
+	}
+	if len(organizations) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &organizations[0], nil
+}
+
+// FindByIDs trouve plusieurs organisations ; les IDs inconnus sont ignorés
+func (s *OrganizationStore) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Organization, error) {
+	wanted := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return s.find(func(organization *models.Organization) bool { return wanted[organization.ID] })
+}
+
+// FindAll liste toutes les organisations par ordre alphabétique
+func (s *OrganizationStore) FindAll(ctx context.Context) ([]models.Organization, error) {
+	return s.find(func(*models.Organization) bool { return true })
+}
+
+func (s *OrganizationStore) find(match func(*models.Organization) bool) ([]models.Organization, error) {
+	s.mu.RLock()
+	var found []models.Organization
+	for i := range s.organizations {
+		if match(&s.organizations[i]) {
+			found = append(found, s.organizations[i])
+		}
+	}
+	s.mu.RUnlock()
+
+	organizations, err := cloneAll(found)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(organizations, func(i, j int) bool { return organizations[i].Name < organizations[j].Name })
+	return organizations, nil
+}
+
+// Update met à jour une organisation
+func (s *OrganizationStore) Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error {
+	updates["updatedAt"] = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.organizations {
+		if s.organizations[i].ID == id {
+			updated, err := apply(&s.organizations[i], updates)
+			if err != nil {
+				return err
+			}
+			s.organizations[i] = *updated
+			break
+		}
+	}
+	return nil
+}
+
+// Delete supprime une organisation et ses membres
+func (s *OrganizationStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members = removeOrganizationMembers(s.members, func(member *models.OrganizationMember) bool {
+		return member.OrganizationID == id
+	})
+	for i := range s.organizations {
+		if s.organizations[i].ID == id {
+			s.organizations = append(s.organizations[:i], s.organizations[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// AddMember ajoute un membre ; un utilisateur déjà membre fait échouer l'insertion (clé dupliquée)
+func (s *OrganizationStore) AddMember(ctx context.Context, member *models.OrganizationMember) error {
+	member.ID = primitive.NewObjectID()
+	member.CreatedAt = time.Now()
+	member.UpdatedAt = member.CreatedAt
+
+	stored, err := clone(member)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.indexOfMember(member.OrganizationID, member.UserID) >= 0 {
+		return duplicateKeyError()
+	}
+	s.members = append(s.members, *stored)
+	return nil
+}
+
+// FindMember trouve l'appartenance d'un utilisateur à une organisation
+func (s *OrganizationStore) FindMember(ctx context.Context, organizationID, userID primitive.ObjectID) (*models.OrganizationMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.indexOfMember(organizationID, userID)
+	if i < 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return clone(&s.members[i])
+}
+
+// FindMembers liste les membres d'une organisation, du plus ancien au plus récent
+func (s *OrganizationStore) FindMembers(ctx context.Context, organizationID primitive.ObjectID) ([]models.OrganizationMember, error) {
+	return s.findMembers(func(member *models.OrganizationMember) bool { return member.OrganizationID == organizationID })
+}
+
+// FindMembershipsByUserID liste les organisations dont un utilisateur est membre
+func (s *OrganizationStore) FindMembershipsByUserID(ctx context.Context, userID primitive.ObjectID) ([]models.OrganizationMember, error) {
+	return s.findMembers(func(member *models.OrganizationMember) bool { return member.UserID == userID })
+}
+
+func (s *OrganizationStore) findMembers(match func(*models.OrganizationMember) bool) ([]models.OrganizationMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := []models.OrganizationMember{}
+	for i := range s.members {
+		if match(&s.members[i]) {
+			members = append(members, s.members[i])
+		}
+	}
+	return cloneAll(members)
+}
+
+// CountOwners compte les propriétaires d'une organisation
+func (s *OrganizationStore) CountOwners(ctx context.Context, organizationID primitive.ObjectID) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, member := range s.members {
+		if member.OrganizationID == organizationID && member.Role == models.OrganizationRoleOwner {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountMembers compte les membres de chaque organisation
+func (s *OrganizationStore) CountMembers(ctx context.Context) (map[primitive.ObjectID]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[primitive.ObjectID]int64{}
+	for _, member := range s.members {
+		counts[member.OrganizationID]++
+	}
+	return counts, nil
+}
+
+// UpdateMemberRole change le rôle d'un membre
+func (s *OrganizationStore) UpdateMemberRole(ctx context.Context, organizationID, userID primitive.ObjectID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexOfMember(organizationID, userID)
+	if i < 0 {
+		return mongo.ErrNoDocuments
+	}
+	s.members[i].Role = role
+	s.members[i].UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveMember retire un membre d'une organisation
+func (s *OrganizationStore) RemoveMember(ctx context.Context, organizationID, userID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexOfMember(organizationID, userID)
+	if i < 0 {
+		return mongo.ErrNoDocuments
+	}
+	s.members = append(s.members[:i], s.members[i+1:]...)
+	return nil
+}
+
+// DeleteMembershipsByUserID retire un utilisateur de toutes ses organisations
+func (s *OrganizationStore) DeleteMembershipsByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members = removeOrganizationMembers(s.members, func(member *models.OrganizationMember) bool {
+		return member.UserID == userID
+	})
+	return nil
+}
+
+func (s *OrganizationStore) indexOfMember(organizationID, userID primitive.ObjectID) int {
+	for i := range s.members {
+		if s.members[i].OrganizationID == organizationID && s.members[i].UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeOrganizationMembers(members []models.OrganizationMember, match func(*models.OrganizationMember) bool) []models.OrganizationMember {
+	kept := members[:0]
+	for i := range members {
+		if !match(&members[i]) {
+			kept = append(kept, members[i])
+		}
+	}
+	return kept
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PropertyDraftStore conserve en mémoire les modifications en attente des propriétés publiées
+type PropertyDraftStore struct {
+	mu     sync.RWMutex
+	drafts map[primitive.ObjectID]models.PropertyDraft
+}
+
+func NewPropertyDraftStore() *PropertyDraftStore {
+	return &PropertyDraftStore{drafts: map[primitive.ObjectID]models.PropertyDraft{}}
+}
+
+func (s *PropertyDraftStore) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) (*models.PropertyDraft, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	draft, ok := s.drafts[propertyID]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return clone(&draft)
+}
+
+// Save applique des modifications au brouillon d'une propriété. S'il n'existe pas encore,
+// il est créé à partir du contenu en ligne.
+func (s *PropertyDraftStore) Save(ctx context.Context, live *models.Property, updates bson.M, authorID *primitive.ObjectID) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[live.ID]
+	if !ok {
+		content, err := clone(live)
+		if err != nil {
+			return err
+		}
+		draft = models.PropertyDraft{
+			ID:         primitive.NewObjectID(),
+			PropertyID: live.ID,
+			Content:    content,
+			CreatedAt:  now,
+		}
+	}
+
+	set := bson.M{"updatedAt": now}
+	for field, value := range updates {
+		set["content."+field] = value
+	}
+	if authorID != nil {
+		set["authorId"] = authorID
+	}
+
+	updated, err := apply(&draft, set)
+	if err != nil {
+		return err
+	}
+	updated.Version = draft.Version + 1
+	s.drafts[live.ID] = *updated
+	return nil
+}
+
+// DeleteIfUnchanged supprime le brouillon d'une propriété s'il n'a pas été modifié depuis
+// sa lecture (même version)
+func (s *PropertyDraftStore) DeleteIfUnchanged(ctx context.Context, propertyID primitive.ObjectID, version int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[propertyID]
+	if !ok || draft.Version != version {
+		return false, nil
+	}
+	delete(s.drafts, propertyID)
+	return true, nil
+}
+
+// DeleteByPropertyID supprime le brouillon d'une propriété
+func (s *PropertyDraftStore) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	s.mu.Lock()
+	delete(s.drafts, propertyID)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PropertyMemberStore conserve en mémoire les collaborateurs des propriétés et leurs invitations.
+// Les listes sont dans l'ordre d'ajout, qui est celui de création.
+type PropertyMemberStore struct {
+	mu          sync.RWMutex
+	members     []models.PropertyMember
+	invitations []models.PropertyInvitation
+}
+
+func NewPropertyMemberStore() *PropertyMemberStore {
+	return &PropertyMemberStore{}
+}
+
+// Create ajoute un collaborateur ; un utilisateur déjà membre de l'équipe fait échouer l'ajout (clé dupliquée)
+func (s *PropertyMemberStore) Create(ctx context.Context, member *models.PropertyMember) error {
+	member.ID = primitive.NewObjectID()
+	member.CreatedAt = time.Now()
+	member.UpdatedAt = member.CreatedAt
+
+	stored, err := clone(member)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.indexOf(member.PropertyID, member.UserID) >= 0 {
+		return duplicateKeyError()
+	}
+	s.members = append(s.members, *stored)
+	return nil
+}
+
+// FindByPropertyAndUser trouve le collaborateur d'une propriété correspondant à un utilisateur
+func (s *PropertyMemberStore) FindByPropertyAndUser(ctx context.Context, propertyID, userID primitive.ObjectID) (*models.PropertyMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.indexOf(propertyID, userID)
+	if i < 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return clone(&s.members[i])
+}
+
+// FindByPropertyID liste les collaborateurs d'une propriété, du plus ancien au plus récent
+func (s *PropertyMemberStore) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.PropertyMember, error) {
+	return s.find(func(member *models.PropertyMember) bool { return member.PropertyID == propertyID })
+}
+
+// FindByUserID liste les propriétés sur lesquelles un utilisateur collabore
+func (s *PropertyMemberStore) FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]models.PropertyMember, error) {
+	return s.find(func(member *models.PropertyMember) bool { return member.UserID == userID })
+}
+
+// UpdateRole change le rôle d'un collaborateur
+func (s *PropertyMemberStore) UpdateRole(ctx context.Context, propertyID, userID primitive.ObjectID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexOf(propertyID, userID)
+	if i < 0 {
+		return mongo.ErrNoDocuments
+	}
+	s.members[i].Role = role
+	s.members[i].UpdatedAt = time.Now()
+	return nil
+}
+
+// Delete retire un collaborateur d'une propriété
+func (s *PropertyMemberStore) Delete(ctx context.Context, propertyID, userID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexOf(propertyID, userID)
+	if i < 0 {
+		return mongo.ErrNoDocuments
+	}
+	s.members = append(s.members[:i], s.members[i+1:]...)
+	return nil
+}
+
+// DeleteByPropertyID supprime les collaborateurs et les invitations d'une propriété
+func (s *PropertyMemberStore) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members = removeMembers(s.members, func(member *models.PropertyMember) bool { return member.PropertyID == propertyID })
+	s.invitations = removeInvitations(s.invitations, func(invitation *models.PropertyInvitation) bool {
+		return invitation.PropertyID == propertyID
+	})
+	return nil
+}
+
+// DeleteByUserID retire un utilisateur de toutes les équipes dont il fait partie
+func (s *PropertyMemberStore) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members = removeMembers(s.members, func(member *models.PropertyMember) bool { return member.UserID == userID })
+	return nil
+}
+
+// CreateInvitation enregistre une invitation et remplace celles encore en attente pour
+// la même adresse : seul le dernier lien envoyé reste valable
+func (s *PropertyMemberStore) CreateInvitation(ctx context.Context, invitation *models.PropertyInvitation) error {
+	invitation.ID = primitive.NewObjectID()
+	invitation.Status = models.InvitationStatusPending
+	invitation.CreatedAt = time.Now()
+
+	stored, err := clone(invitation)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invitations = removeInvitations(s.invitations, func(pending *models.PropertyInvitation) bool {
+		return pending.PropertyID == invitation.PropertyID &&
+			pending.Email == invitation.Email &&
+			pending.Status == models.InvitationStatusPending
+	})
+	for _, existing := range s.invitations {
+		if existing.TokenHash == invitation.TokenHash {
+			return duplicateKeyError()
+		}
+	}
+	s.invitations = append(s.invitations, *stored)
+	return nil
+}
+
+// FindInvitationByTokenHash trouve une invitation par l'empreinte de son token
+func (s *PropertyMemberStore) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.PropertyInvitation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.invitations {
+		if s.invitations[i].TokenHash == tokenHash {
+			return clone(&s.invitations[i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// FindPendingInvitations liste les invitations en attente d'une propriété, de la plus récente à la plus ancienne
+func (s *PropertyMemberStore) FindPendingInvitations(ctx context.Context, propertyID primitive.ObjectID) ([]models.PropertyInvitation, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	var pending []models.PropertyInvitation
+	for _, invitation := range s.invitations {
+		if invitation.PropertyID == propertyID && invitation.IsPendingAt(now) {
+			pending = append(pending, invitation)
+		}
+	}
+	s.mu.RUnlock()
+
+	invitations, err := cloneAll(pending)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+	return invitations, nil
+}
+
+// RespondInvitation enregistre la réponse à une invitation encore en attente
+func (s *PropertyMemberStore) RespondInvitation(ctx context.Context, id primitive.ObjectID, status string) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.invitations {
+		invitation := &s.invitations[i]
+		if invitation.ID == id && invitation.IsPendingAt(now) {
+			invitation.Status = status
+			invitation.RespondedAt = &now
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+// DeleteInvitation annule une invitation en attente d'une propriété
+func (s *PropertyMemberStore) DeleteInvitation(ctx context.Context, propertyID, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, invitation := range s.invitations {
+		if invitation.ID == id && invitation.PropertyID == propertyID && invitation.Status == models.InvitationStatusPending {
+			s.invitations = append(s.invitations[:i], s.invitations[i+1:]...)
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (s *PropertyMemberStore) indexOf(propertyID, userID primitive.ObjectID) int {
+	for i := range s.members {
+		if s.members[i].PropertyID == propertyID && s.members[i].UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *PropertyMemberStore) find(match func(*models.PropertyMember) bool) ([]models.PropertyMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := []models.PropertyMember{}
+	for i := range s.members {
+		if match(&s.members[i]) {
+			members = append(members, s.members[i])
+		}
+	}
+	return cloneAll(members)
+}
+
+func removeMembers(members []models.PropertyMember, match func(*models.PropertyMember) bool) []models.PropertyMember {
+	kept := members[:0]
+	for i := range members {
+		if !match(&members[i]) {
+			kept = append(kept, members[i])
+		}
+	}
+	return kept
+}
+
+func removeInvitations(invitations []models.PropertyInvitation, match func(*models.PropertyInvitation) bool) []models.PropertyInvitation {
+	kept := invitations[:0]
+	for i := range invitations {
+		if !match(&invitations[i]) {
+			kept = append(kept, invitations[i])
+		}
+	}
+	return kept
+}
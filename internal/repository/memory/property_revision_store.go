@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PropertyRevisionStore conserve en mémoire l'historique des propriétés
+type PropertyRevisionStore struct {
+	mu        sync.RWMutex
+	revisions map[primitive.ObjectID][]models.PropertyRevision // Par propriété, du plus ancien au plus récent
+}
+
+func NewPropertyRevisionStore() *PropertyRevisionStore {
+	return &PropertyRevisionStore{revisions: map[primitive.ObjectID][]models.PropertyRevision{}}
+}
+
+// Record enregistre l'état after comme nouvelle révision. before est l'état qui précédait
+// la modification : il sert de révision initiale pour les propriétés sans historique.
+// Une modification sans effet n'est pas enregistrée (revision.Number reste à 0).
+func (s *PropertyRevisionStore) Record(ctx context.Context, before, after *models.Property, revision *models.PropertyRevision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.revisions[after.ID]
+	previous := before
+	number := 0
+	if len(history) > 0 {
+		latest := history[len(history)-1]
+		previous = latest.Snapshot
+		number = latest.Number
+	} else if before != nil {
+		initial := &models.PropertyRevision{
+			PropertyID: after.ID,
+			Number:     1,
+			Action:     models.RevisionActionInitial,
+			Sections:   []string{},
+		}
+		if err := s.insert(initial, before, before.UpdatedAt); err != nil {
+			return err
+		}
+		number = 1
+	}
+
+	sections := models.ChangedSections(previous, after)
+	if len(sections) == 0 && previous != nil {
+		return nil
+	}
+
+	revision.PropertyID = after.ID
+	revision.Number = number + 1
+	revision.Sections = sections
+	if err := s.insert(revision, after, time.Now()); err != nil {
+		revision.Number = 0
+		return err
+	}
+
+	// Purge des révisions les plus anciennes
+	history = s.revisions[after.ID]
+	if len(history) > models.MaxPropertyRevisions {
+		s.revisions[after.ID] = history[len(history)-models.MaxPropertyRevisions:]
+	}
+	return nil
+}
+
+func (s *PropertyRevisionStore) insert(revision *models.PropertyRevision, snapshot *models.Property, createdAt time.Time) error {
+	revision.ID = primitive.NewObjectID()
+	revision.CreatedAt = createdAt
+	revision.Snapshot = snapshot
+
+	stored, err := clone(revision)
+	if err != nil {
+		return err
+	}
+	s.revisions[revision.PropertyID] = append(s.revisions[revision.PropertyID], *stored)
+	return nil
+}
+
+// FindByPropertyID liste les révisions d'une propriété, de la plus récente à la plus ancienne,
+// sans les instantanés
+func (s *PropertyRevisionStore) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, limit int64) ([]models.PropertyRevision, error) {
+	s.mu.RLock()
+	history := s.revisions[propertyID]
+	revisions, err := cloneAll(history)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool { return revisions[i].Number > revisions[j].Number })
+	if limit > 0 && int64(len(revisions)) > limit {
+		revisions = revisions[:limit]
+	}
+	for i := range revisions {
+		revisions[i].Snapshot = nil
+	}
+	return revisions, nil
+}
+
+// FindByNumber trouve une révision par son numéro
+func (s *PropertyRevisionStore) FindByNumber(ctx context.Context, propertyID primitive.ObjectID, number int) (*models.PropertyRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i, revision := range s.revisions[propertyID] {
+		if revision.Number == number {
+			return clone(&s.revisions[propertyID][i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// DeleteByPropertyID supprime l'historique d'une propriété
+func (s *PropertyRevisionStore) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	s.mu.Lock()
+	delete(s.revisions, propertyID)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository"
+)
+
+// FindAll recherche parmi les propriétés publiées avec les mêmes filtres et tris que la
+// recherche MongoDB. Deux simplifications : la recherche textuelle est une recherche de
+// sous-chaîne insensible à la casse dans le nom et la description, et le curseur est la
+// position de la page suivante dans la liste triée.
+func (s *PropertyStore) FindAll(ctx context.Context, query models.PropertySearchQuery) (*repository.PropertyPage, error) {
+	if query.Sort == "" {
+		query.Sort = models.PropertySortNewest
+	}
+	if query.Limit <= 0 {
+		query.Limit = models.PropertySearchDefaultLimit
+	}
+	if query.NearPoint != nil && query.RadiusKm <= 0 {
+		query.RadiusKm = models.PropertySearchDefaultRadiusKm
+	}
+
+	offset := 0
+	if query.Cursor != "" {
+		var err error
+		offset, err = strconv.Atoi(query.Cursor)
+		if err != nil || offset < 0 {
+			return nil, repository.ErrInvalidCursor
+		}
+	}
+
+	s.mu.RLock()
+	var matches []models.Property
+	for i := range s.properties {
+		matched, err := matchesSearch(&s.properties[i], query)
+		if err != nil {
+			s.mu.RUnlock()
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, s.properties[i])
+		}
+	}
+	s.mu.RUnlock()
+
+	properties, err := s.decryptAll(matches)
+	if err != nil {
+		return nil, err
+	}
+
+	// Comme avec $geoNear, la distance n'est renseignée que pour un tri par proximité
+	if query.Sort == models.PropertySortDistance && query.NearPoint != nil {
+		for i := range properties {
+			distance := query.NearPoint.DistanceTo(properties[i].Location)
+			properties[i].DistanceMeters = &distance
+		}
+	}
+	sortProperties(properties, query.Sort)
+
+	page := &repository.PropertyPage{Total: int64(len(properties)), Properties: []models.Property{}}
+	if offset < len(properties) {
+		end := offset + int(query.Limit)
+		if end < len(properties) {
+			page.NextCursor = strconv.Itoa(end)
+		} else {
+			end = len(properties)
+		}
+		page.Properties = properties[offset:end]
+	}
+	return page, nil
+}
+
+func matchesSearch(property *models.Property, query models.PropertySearchQuery) (bool, error) {
+	if !property.IsPublished() {
+		return false, nil
+	}
+	if query.Text != "" {
+		text := strings.ToLower(query.Text)
+		if !strings.Contains(strings.ToLower(property.Name), text) && !strings.Contains(strings.ToLower(property.Description), text) {
+			return false, nil
+		}
+	}
+	if query.City != "" && !strings.EqualFold(property.City, query.City) {
+		return false, nil
+	}
+	if query.Country != "" && !strings.EqualFold(property.Country, query.Country) {
+		return false, nil
+	}
+	if query.ZipCode != "" && !strings.HasPrefix(property.ZipCode, query.ZipCode) {
+		return false, nil
+	}
+	if query.NearPoint != nil {
+		if property.Location == nil || query.NearPoint.DistanceTo(property.Location) > query.RadiusKm*1000 {
+			return false, nil
+		}
+	}
+
+	fields, err := toFields(property)
+	if err != nil {
+		return false, err
+	}
+
+	if query.Guests > 0 && !anyValue(lookup(fields, "rules.maxGuests"), func(value interface{}) bool {
+		guests, ok := number(value)
+		return ok && guests >= float64(query.Guests)
+	}) {
+		return false, nil
+	}
+
+	categories := lookup(fields, "equipment.items.category")
+	for _, category := range query.EquipmentCategories {
+		if !anyValue(categories, func(value interface{}) bool { return value == category }) {
+			return false, nil
+		}
+	}
+
+	flags := map[string]*bool{
+		"rules.petsAllowed":     query.PetsAllowed,
+		"rules.childrenAllowed": query.ChildrenAllowed,
+		"parking.available":     query.Parking,
+		"outdoor.hasPool":       query.Pool,
+		"outdoor.hasSpa":        query.Spa,
+		"outdoor.hasGarden":     query.Garden,
+	}
+	for field, value := range flags {
+		if value == nil {
+			continue
+		}
+		enabled := anyValue(lookup(fields, field), func(v interface{}) bool { return v == true })
+		if enabled != *value {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func sortProperties(properties []models.Property, order string) {
+	sort.SliceStable(properties, func(i, j int) bool {
+		a, b := &properties[i], &properties[j]
+		switch order {
+		case models.PropertySortOldest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID.Hex() < b.ID.Hex()
+		case models.PropertySortNameAsc:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID.Hex() < b.ID.Hex()
+		case models.PropertySortNameDesc:
+			if a.Name != b.Name {
+				return a.Name > b.Name
+			}
+			return a.ID.Hex() > b.ID.Hex()
+		case models.PropertySortDistance:
+			if a.DistanceMeters != nil && b.DistanceMeters != nil && *a.DistanceMeters != *b.DistanceMeters {
+				return *a.DistanceMeters < *b.DistanceMeters
+			}
+			return a.ID.Hex() < b.ID.Hex()
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			return a.ID.Hex() > b.ID.Hex()
+		}
+	})
+}
+
+func anyValue(values []interface{}, match func(interface{}) bool) bool {
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// number convertit une valeur numérique décodée depuis BSON
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,417 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/models"
+	"onestay-back/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PropertyStore conserve les propriétés en mémoire. Comme en base, les champs sensibles
+// sont chiffrés avec le trousseau (nil : en clair).
+type PropertyStore struct {
+	mu         sync.RWMutex
+	properties []models.Property
+	keyring    *fieldcrypt.Keyring
+}
+
+func NewPropertyStore(keyring *fieldcrypt.Keyring) *PropertyStore {
+	return &PropertyStore{keyring: keyring}
+}
+
+// Create crée une nouvelle propriété
+func (s *PropertyStore) Create(ctx context.Context, property *models.Property) error {
+	property.ID = primitive.NewObjectID()
+	property.CreatedAt = time.Now()
+	property.UpdatedAt = time.Now()
+
+	encrypted, err := repository.EncryptPropertySecrets(s.keyring, property)
+	if err != nil {
+		return err
+	}
+	stored, err := clone(encrypted)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.properties = append(s.properties, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// ExistsBySlug vérifie si un slug existe déjà
+func (s *PropertyStore) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
+	return s.exists(func(property *models.Property) bool { return property.Slug == slug }), nil
+}
+
+// ExistsByNameAndHostID vérifie si un logement avec le même nom existe déjà pour cet hôte
+func (s *PropertyStore) ExistsByNameAndHostID(ctx context.Context, name string, hostID primitive.ObjectID) (bool, error) {
+	return s.exists(func(property *models.Property) bool {
+		return property.Name == name && property.HostID == hostID
+	}), nil
+}
+
+// FindBySlug trouve une propriété par son slug
+func (s *PropertyStore) FindBySlug(ctx context.Context, slug string) (*models.Property, error) {
+	return s.findOne(func(property *models.Property) bool { return property.Slug == slug })
+}
+
+// FindByID trouve une propriété par son ID
+func (s *PropertyStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Property, error) {
+	return s.findOne(func(property *models.Property) bool { return property.ID == id })
+}
+
+func (s *PropertyStore) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Property, error) {
+	wanted := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return s.find(func(property *models.Property) bool { return wanted[property.ID] })
+}
+
+// FindByHostID trouve les propriétés personnelles d'un hôte
+func (s *PropertyStore) FindByHostID(ctx context.Context, hostID primitive.ObjectID, includeDraft bool) ([]models.Property, error) {
+	return s.find(func(property *models.Property) bool {
+		return property.HostID == hostID && property.OrganizationID == nil && (includeDraft || property.IsPublished())
+	})
+}
+
+// Update met à jour une propriété
+func (s *PropertyStore) Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error {
+	updates["updatedAt"] = time.Now()
+	_, err := s.update(byID(id), updates)
+	return err
+}
+
+// Delete supprime une propriété
+func (s *PropertyStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	s.delete(byID(id))
+	return nil
+}
+
+// DeleteByHostID supprime les propriétés personnelles d'un hôte
+func (s *PropertyStore) DeleteByHostID(ctx context.Context, hostID primitive.ObjectID) (int64, error) {
+	return s.delete(func(property *models.Property) bool {
+		return property.HostID == hostID && property.OrganizationID == nil
+	}), nil
+}
+
+// TransferOwnership change l'hôte propriétaire d'une propriété
+func (s *PropertyStore) TransferOwnership(ctx context.Context, id, hostID primitive.ObjectID) error {
+	_, err := s.update(byID(id), bson.M{"hostId": hostID, "updatedAt": time.Now()})
+	return err
+}
+
+// AddPhoto ajoute une photo, dans la limite de models.MaxPropertyImages. Si la propriété
+// n'a pas encore de couverture, la première photo le devient.
+func (s *PropertyStore) AddPhoto(ctx context.Context, propertyID primitive.ObjectID, photo models.PropertyImage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.properties {
+		property := &s.properties[i]
+		if property.ID != propertyID || len(property.Photos) >= models.MaxPropertyImages {
+			continue
+		}
+		return s.setPhotos(property, append(slices.Clone(property.Photos), photo))
+	}
+	return repository.ErrTooManyPhotos
+}
+
+// SetPhotos remplace la liste des photos (ordre, légendes, couverture) si elle n'a pas été
+// modifiée depuis sa lecture (même PhotosVersion)
+func (s *PropertyStore) SetPhotos(ctx context.Context, propertyID primitive.ObjectID, version int64, photos []models.PropertyImage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.properties {
+		property := &s.properties[i]
+		if property.ID != propertyID || property.PhotosVersion != version {
+			continue
+		}
+		return s.setPhotos(property, slices.Clone(photos))
+	}
+	return repository.ErrPhotosChanged
+}
+
+// DeletePhoto retire une photo et la retourne ; si c'était la couverture, la première photo
+// restante la remplace
+func (s *PropertyStore) DeletePhoto(ctx context.Context, propertyID, photoID primitive.ObjectID) (*models.PropertyImage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.properties {
+		property := &s.properties[i]
+		if property.ID != propertyID {
+			continue
+		}
+		index := slices.IndexFunc(property.Photos, func(photo models.PropertyImage) bool { return photo.ID == photoID })
+		if index < 0 {
+			break
+		}
+		removed := property.Photos[index]
+		if err := s.setPhotos(property, slices.Delete(slices.Clone(property.Photos), index, index+1)); err != nil {
+			return nil, err
+		}
+		return &removed, nil
+	}
+	return nil, repository.ErrPhotoNotFound
+}
+
+// setPhotos enregistre les photos d'une propriété en garantissant une couverture ; le verrou doit être tenu
+func (s *PropertyStore) setPhotos(property *models.Property, photos []models.PropertyImage) error {
+	if photos == nil {
+		photos = []models.PropertyImage{}
+	}
+	if len(photos) > 0 && !slices.ContainsFunc(photos, func(photo models.PropertyImage) bool { return photo.IsCover }) {
+		photos[0].IsCover = true
+	}
+	updated, err := apply(property, bson.M{
+		"photos":        photos,
+		"photosVersion": property.PhotosVersion + 1,
+		"updatedAt":     time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	*property = *updated
+	return nil
+}
+
+func (s *PropertyStore) SetTranslations(ctx context.Context, propertyID primitive.ObjectID, translations []models.PropertyTranslation) error {
+	if translations == nil {
+		translations = []models.PropertyTranslation{}
+	}
+	return s.Update(ctx, propertyID, bson.M{"translations": translations})
+}
+
+// Publish met la propriété en ligne en y appliquant le contenu en attente
+func (s *PropertyStore) Publish(ctx context.Context, id primitive.ObjectID, content bson.M, publishedAt *time.Time) error {
+	set := bson.M{"status": models.PropertyStatusPublished, "updatedAt": time.Now()}
+	for field, value := range content {
+		set[field] = value
+	}
+	if publishedAt != nil {
+		set["publishedAt"] = publishedAt
+	}
+
+	_, err := s.update(byID(id), set, "scheduledPublishAt")
+	return err
+}
+
+// Unpublish repasse la propriété en brouillon ; le contenu en attente, s'il y en a un,
+// devient le contenu de la propriété
+func (s *PropertyStore) Unpublish(ctx context.Context, id primitive.ObjectID, content bson.M) error {
+	set := bson.M{"status": models.PropertyStatusDraft, "updatedAt": time.Now()}
+	for field, value := range content {
+		set[field] = value
+	}
+
+	_, err := s.update(byID(id), set, "scheduledPublishAt", "publishedAt")
+	return err
+}
+
+// SchedulePublication programme la publication (ou l'annule si at est nil)
+func (s *PropertyStore) SchedulePublication(ctx context.Context, id primitive.ObjectID, at *time.Time) error {
+	var err error
+	if at == nil {
+		_, err = s.update(byID(id), bson.M{}, "scheduledPublishAt")
+	} else {
+		_, err = s.update(byID(id), bson.M{"scheduledPublishAt": at})
+	}
+	return err
+}
+
+// FindDueForPublication liste les propriétés dont la publication programmée est échue
+func (s *PropertyStore) FindDueForPublication(ctx context.Context, now time.Time) ([]models.Property, error) {
+	return s.find(func(property *models.Property) bool {
+		return property.ScheduledPublishAt != nil && !property.ScheduledPublishAt.After(now)
+	})
+}
+
+// FindByOrganizationID liste les propriétés d'une organisation, par ordre alphabétique
+func (s *PropertyStore) FindByOrganizationID(ctx context.Context, organizationID primitive.ObjectID, includeDraft bool) ([]models.Property, error) {
+	properties, err := s.find(func(property *models.Property) bool {
+		return inOrganization(property, organizationID) && (includeDraft || property.IsPublished())
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(properties, func(i, j int) bool { return properties[i].Name < properties[j].Name })
+	return properties, nil
+}
+
+// SetOrganization confie une propriété à une organisation, ou la rend à son hôte si organizationID est nil
+func (s *PropertyStore) SetOrganization(ctx context.Context, id primitive.ObjectID, organizationID *primitive.ObjectID) error {
+	var err error
+	if organizationID == nil {
+		_, err = s.update(byID(id), bson.M{"updatedAt": time.Now()}, "organizationId")
+	} else {
+		_, err = s.update(byID(id), bson.M{"organizationId": *organizationID, "updatedAt": time.Now()})
+	}
+	return err
+}
+
+// DetachOrganization rend à leurs hôtes toutes les propriétés d'une organisation
+func (s *PropertyStore) DetachOrganization(ctx context.Context, organizationID primitive.ObjectID) (int64, error) {
+	return s.update(func(property *models.Property) bool {
+		return inOrganization(property, organizationID)
+	}, bson.M{"updatedAt": time.Now()}, "organizationId")
+}
+
+// FindOrganizationIDsByHostID liste les organisations dont un hôte gère des propriétés
+func (s *PropertyStore) FindOrganizationIDsByHostID(ctx context.Context, hostID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := map[primitive.ObjectID]bool{}
+	var ids []primitive.ObjectID
+	for _, property := range s.properties {
+		if property.HostID != hostID || property.OrganizationID == nil || seen[*property.OrganizationID] {
+			continue
+		}
+		seen[*property.OrganizationID] = true
+		ids = append(ids, *property.OrganizationID)
+	}
+	return ids, nil
+}
+
+// ReassignOrganizationHost confie à un autre hôte les propriétés d'une organisation gérées par hostID
+func (s *PropertyStore) ReassignOrganizationHost(ctx context.Context, organizationID, hostID, newHostID primitive.ObjectID) error {
+	_, err := s.update(func(property *models.Property) bool {
+		return property.HostID == hostID && inOrganization(property, organizationID)
+	}, bson.M{"hostId": newHostID, "updatedAt": time.Now()})
+	return err
+}
+
+// CountByOrganization compte les propriétés, publiées ou non, de chaque organisation
+func (s *PropertyStore) CountByOrganization(ctx context.Context) (map[primitive.ObjectID]models.OrganizationPropertyCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[primitive.ObjectID]models.OrganizationPropertyCount{}
+	for _, property := range s.properties {
+		if property.OrganizationID == nil {
+			continue
+		}
+		count := counts[*property.OrganizationID]
+		count.Total++
+		if property.IsPublished() {
+			count.Published++
+		}
+		counts[*property.OrganizationID] = count
+	}
+	return counts, nil
+}
+
+func byID(id primitive.ObjectID) func(*models.Property) bool {
+	return func(property *models.Property) bool { return property.ID == id }
+}
+
+func inOrganization(property *models.Property, organizationID primitive.ObjectID) bool {
+	return property.OrganizationID != nil && *property.OrganizationID == organizationID
+}
+
+func (s *PropertyStore) exists(match func(*models.Property) bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.properties {
+		if match(&s.properties[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PropertyStore) findOne(match func(*models.Property) bool) (*models.Property, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.properties {
+		if match(&s.properties[i]) {
+			property, err := clone(&s.properties[i])
+			if err != nil {
+				return nil, err
+			}
+			if err := repository.DecryptPropertySecrets(s.keyring, property); err != nil {
+				return nil, err
+			}
+			return property, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *PropertyStore) find(match func(*models.Property) bool) ([]models.Property, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []models.Property
+	for i := range s.properties {
+		if match(&s.properties[i]) {
+			found = append(found, s.properties[i])
+		}
+	}
+	return s.decryptAll(found)
+}
+
+// decryptAll copie les propriétés lues et en déchiffre les champs sensibles
+func (s *PropertyStore) decryptAll(stored []models.Property) ([]models.Property, error) {
+	properties, err := cloneAll(stored)
+	if err != nil {
+		return nil, err
+	}
+	for i := range properties {
+		if err := repository.DecryptPropertySecrets(s.keyring, &properties[i]); err != nil {
+			return nil, err
+		}
+	}
+	return properties, nil
+}
+
+// update applique un $set et un $unset aux propriétés correspondantes et retourne leur nombre
+func (s *PropertyStore) update(match func(*models.Property) bool, set bson.M, unset ...string) (int64, error) {
+	if err := repository.EncryptSecretUpdates(s.keyring, set); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var modified int64
+	for i := range s.properties {
+		if !match(&s.properties[i]) {
+			continue
+		}
+		updated, err := apply(&s.properties[i], set, unset...)
+		if err != nil {
+			return modified, err
+		}
+		s.properties[i] = *updated
+		modified++
+	}
+	return modified, nil
+}
+
+func (s *PropertyStore) delete(match func(*models.Property) bool) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.properties[:0]
+	var deleted int64
+	for i := range s.properties {
+		if match(&s.properties[i]) {
+			deleted++
+			continue
+		}
+		kept = append(kept, s.properties[i])
+	}
+	s.properties = kept
+	return deleted
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ReservationStore conserve les réservations en mémoire
+type ReservationStore struct {
+	mu           sync.RWMutex
+	reservations []models.Reservation
+}
+
+func NewReservationStore() *ReservationStore {
+	return &ReservationStore{}
+}
+
+func (s *ReservationStore) Create(ctx context.Context, reservation *models.Reservation) error {
+	reservation.ID = primitive.NewObjectID()
+	reservation.CreatedAt = time.Now()
+	reservation.UpdatedAt = reservation.CreatedAt
+
+	stored, err := clone(reservation)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.reservations = append(s.reservations, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// FindByID trouve une réservation par son ID
+func (s *ReservationStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.reservations {
+		if s.reservations[i].ID == id {
+			return clone(&s.reservations[i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// FindByPropertyID liste les réservations d'une propriété, triées par date d'arrivée.
+// Les bornes from/to (optionnelles) filtrent les séjours qui chevauchent la période.
+func (s *ReservationStore) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, status string, from, to *time.Time) ([]models.Reservation, error) {
+	s.mu.RLock()
+	var found []models.Reservation
+	for _, reservation := range s.reservations {
+		if reservation.PropertyID != propertyID || (status != "" && reservation.Status != status) {
+			continue
+		}
+		if (from != nil && !reservation.DepartureDate.After(*from)) || (to != nil && !reservation.ArrivalDate.Before(*to)) {
+			continue
+		}
+		found = append(found, reservation)
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].ArrivalDate.Before(found[j].ArrivalDate) })
+	return cloneAll(found)
+}
+
+// HasOverlap vérifie si une réservation non annulée chevauche la période [arrival, departure[
+func (s *ReservationStore) HasOverlap(ctx context.Context, propertyID primitive.ObjectID, arrival, departure time.Time, excludeID *primitive.ObjectID) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, reservation := range s.reservations {
+		if reservation.PropertyID != propertyID || reservation.Status == models.ReservationStatusCancelled {
+			continue
+		}
+		if excludeID != nil && reservation.ID == *excludeID {
+			continue
+		}
+		if reservation.ArrivalDate.Before(departure) && reservation.DepartureDate.After(arrival) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Update met à jour une réservation
+func (s *ReservationStore) Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error {
+	updates["updatedAt"] = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.reservations {
+		if s.reservations[i].ID != id {
+			continue
+		}
+		updated, err := apply(&s.reservations[i], updates)
+		if err != nil {
+			return err
+		}
+		s.reservations[i] = *updated
+	}
+	return nil
+}
+
+// Delete supprime une réservation
+func (s *ReservationStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	s.delete(func(reservation *models.Reservation) bool { return reservation.ID == id })
+	return nil
+}
+
+// DeleteByPropertyID supprime toutes les réservations d'une propriété
+func (s *ReservationStore) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	s.delete(func(reservation *models.Reservation) bool { return reservation.PropertyID == propertyID })
+	return nil
+}
+
+func (s *ReservationStore) delete(match func(*models.Reservation) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.reservations[:0]
+	for i := range s.reservations {
+		if !match(&s.reservations[i]) {
+			kept = append(kept, s.reservations[i])
+		}
+	}
+	s.reservations = kept
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// RoleStore conserve les rôles en mémoire ; l'ID d'un rôle est choisi par l'appelant
+type RoleStore struct {
+	mu    sync.RWMutex
+	roles []models.Role
+}
+
+func NewRoleStore() *RoleStore {
+	return &RoleStore{}
+}
+
+func (s *RoleStore) Create(ctx context.Context, role *models.Role) error {
+	role.CreatedAt = time.Now()
+	role.UpdatedAt = time.Now()
+
+	stored, err := clone(role)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.roles {
+		if s.roles[i].ID == role.ID {
+			return duplicateKeyError()
+		}
+	}
+	s.roles = append(s.roles, *stored)
+	return nil
+}
+
+// Update met à jour un rôle
+func (s *RoleStore) Update(ctx context.Context, id string, updates bson.M) error {
+	updates["updated_at"] = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.roles {
+		if s.roles[i].ID == id {
+			updated, err := apply(&s.roles[i], updates)
+			if err != nil {
+				return err
+			}
+			s.roles[i] = *updated
+			break
+		}
+	}
+	return nil
+}
+
+func (s *RoleStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.roles {
+		if s.roles[i].ID == id {
+			s.roles = append(s.roles[:i], s.roles[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *RoleStore) FindBySlug(ctx context.Context, slug string) (*models.Role, error) {
+	return s.find(func(role *models.Role) bool { return role.Slug == slug })
+}
+
+func (s *RoleStore) FindByID(ctx context.Context, id string) (*models.Role, error) {
+	return s.find(func(role *models.Role) bool { return role.ID == id })
+}
+
+func (s *RoleStore) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
+	_, err := s.FindBySlug(ctx, slug)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *RoleStore) GetAll(ctx context.Context) ([]models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneAll(s.roles)
+}
+
+func (s *RoleStore) find(match func(*models.Role) bool) (*models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.roles {
+		if match(&s.roles[i]) {
+			return clone(&s.roles[i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SectionTemplateLinkStore tient lieu de liens entre les modèles de section et les propriétés.
+// Les modèles de section n'ayant pas d'implémentation en mémoire, aucun lien n'existe :
+// il n'y a rien à entretenir.
+type SectionTemplateLinkStore struct{}
+
+func NewSectionTemplateLinkStore() *SectionTemplateLinkStore {
+	return &SectionTemplateLinkStore{}
+}
+
+func (s *SectionTemplateLinkStore) UnlinkSections(ctx context.Context, propertyID primitive.ObjectID, sections []string) error {
+	return nil
+}
+
+func (s *SectionTemplateLinkStore) UnlinkProperty(ctx context.Context, propertyID primitive.ObjectID) error {
+	return nil
+}
+
+func (s *SectionTemplateLinkStore) CopyLinks(ctx context.Context, src, dst primitive.ObjectID) error {
+	return nil
+}
+
+func (s *SectionTemplateLinkStore) DeleteByHostID(ctx context.Context, hostID primitive.ObjectID) error {
+	return nil
+}
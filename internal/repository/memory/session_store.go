@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// SessionStore conserve les sessions en mémoire
+type SessionStore struct {
+	mu       sync.RWMutex
+	sessions []models.Session
+}
+
+func NewSessionStore() *SessionStore {
+	return &SessionStore{}
+}
+
+func (s *SessionStore) Create(ctx context.Context, session *models.Session) error {
+	session.ID = primitive.NewObjectID()
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+	session.LastUsedAt = time.Now()
+
+	stored, err := clone(session)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.sessions = append(s.sessions, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// FindByID trouve une session par son ID
+func (s *SessionStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	return s.find(func(session *models.Session) bool { return session.ID == id })
+}
+
+// FindByRefreshTokenHash trouve la session dont le refresh token courant correspond à l'empreinte
+func (s *SessionStore) FindByRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	return s.find(func(session *models.Session) bool { return session.RefreshTokenHash == hash })
+}
+
+// FindByPreviousTokenHash trouve la session ayant déjà consommé ce refresh token
+func (s *SessionStore) FindByPreviousTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	return s.find(func(session *models.Session) bool {
+		for _, previous := range session.PreviousTokenHashes {
+			if previous == hash {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FindActive trouve une session non révoquée et non expirée ; mongo.ErrNoDocuments sinon
+func (s *SessionStore) FindActive(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	return s.find(func(session *models.Session) bool { return session.ID == id && session.IsActive() })
+}
+
+// Rotate remplace le refresh token d'une session active. Retourne false si le
+// token courant a changé entre-temps (rafraîchissement concurrent ou session révoquée).
+func (s *SessionStore) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+	rotated := false
+	s.update(func(session *models.Session) bool {
+		if session.ID != id || session.RefreshTokenHash != oldHash || session.RevokedAt != nil {
+			return false
+		}
+		session.PreviousTokenHashes = append(session.PreviousTokenHashes, oldHash)
+		if excess := len(session.PreviousTokenHashes) - models.MaxPreviousTokenHashes; excess > 0 {
+			session.PreviousTokenHashes = session.PreviousTokenHashes[excess:]
+		}
+		session.RefreshTokenHash = newHash
+		session.ExpiresAt = expiresAt
+		session.LastUsedAt = now
+		session.UpdatedAt = now
+		rotated = true
+		return true
+	})
+	return rotated, nil
+}
+
+// SetOrganization choisit l'organisation pour laquelle agit la session, ou aucune si organizationID est nil
+func (s *SessionStore) SetOrganization(ctx context.Context, id primitive.ObjectID, organizationID *primitive.ObjectID) error {
+	var copied *primitive.ObjectID
+	if organizationID != nil {
+		value := *organizationID
+		copied = &value
+	}
+
+	s.update(func(session *models.Session) bool {
+		if session.ID != id {
+			return false
+		}
+		session.OrganizationID = copied
+		session.UpdatedAt = time.Now()
+		return true
+	})
+	return nil
+}
+
+// ClearOrganization fait agir en leur nom propre les sessions qui agissaient pour une
+// organisation ; limité aux sessions d'un utilisateur si userID est renseigné
+func (s *SessionStore) ClearOrganization(ctx context.Context, organizationID primitive.ObjectID, userID *primitive.ObjectID) error {
+	s.update(func(session *models.Session) bool {
+		if session.OrganizationID == nil || *session.OrganizationID != organizationID {
+			return false
+		}
+		if userID != nil && session.UserID != *userID {
+			return false
+		}
+		session.OrganizationID = nil
+		session.UpdatedAt = time.Now()
+		return true
+	})
+	return nil
+}
+
+// Revoke révoque une session (et donc toute sa famille de tokens)
+func (s *SessionStore) Revoke(ctx context.Context, id primitive.ObjectID, reason string) error {
+	s.revoke(reason, func(session *models.Session) bool { return session.ID == id })
+	return nil
+}
+
+// RevokeAllByUserID révoque toutes les sessions actives d'un utilisateur
+func (s *SessionStore) RevokeAllByUserID(ctx context.Context, userID primitive.ObjectID, reason string) (int64, error) {
+	return s.revoke(reason, func(session *models.Session) bool { return session.UserID == userID }), nil
+}
+
+// RevokeOthersByUserID révoque toutes les sessions actives d'un utilisateur sauf celle indiquée
+func (s *SessionStore) RevokeOthersByUserID(ctx context.Context, userID, keepID primitive.ObjectID, reason string) (int64, error) {
+	return s.revoke(reason, func(session *models.Session) bool {
+		return session.UserID == userID && session.ID != keepID
+	}), nil
+}
+
+func (s *SessionStore) revoke(reason string, match func(*models.Session) bool) int64 {
+	now := time.Now()
+	return s.update(func(session *models.Session) bool {
+		if session.RevokedAt != nil || !match(session) {
+			return false
+		}
+		session.RevokedAt = &now
+		session.RevokedReason = reason
+		session.UpdatedAt = now
+		return true
+	})
+}
+
+func (s *SessionStore) find(match func(*models.Session) bool) (*models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.sessions {
+		if match(&s.sessions[i]) {
+			return clone(&s.sessions[i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// update applique modify à chaque session et retourne le nombre de sessions modifiées
+func (s *SessionStore) update(modify func(*models.Session) bool) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var modified int64
+	for i := range s.sessions {
+		if modify(&s.sessions[i]) {
+			modified++
+		}
+	}
+	return modified
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// UserStore conserve les comptes utilisateurs en mémoire
+type UserStore struct {
+	mu    sync.RWMutex
+	users []models.User
+}
+
+func NewUserStore() *UserStore {
+	return &UserStore{}
+}
+
+func (s *UserStore) Create(ctx context.Context, user *models.User) error {
+	user.ID = primitive.NewObjectID()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	stored, err := clone(user)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.users = append(s.users, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *UserStore) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return s.find(func(user *models.User) bool { return user.Email == email })
+}
+
+func (s *UserStore) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	_, err := s.FindByEmail(ctx, email)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *UserStore) GetAll(ctx context.Context) ([]models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneAll(s.users)
+}
+
+func (s *UserStore) FindByID(ctx context.Context, id string) (*models.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.find(func(user *models.User) bool { return user.ID == objectID })
+}
+
+func (s *UserStore) Update(ctx context.Context, id string, updates bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	updates["updated_at"] = time.Now()
+	return s.update(objectID, updates)
+}
+
+// MarkEmailVerified confirme l'adresse email d'un utilisateur
+func (s *UserStore) MarkEmailVerified(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	return s.update(id, bson.M{"email_verified_at": now, "updated_at": now}, "email_verification_pending")
+}
+
+func (s *UserStore) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.users {
+		if s.users[i].ID == objectID {
+			s.users = append(s.users[:i], s.users[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *UserStore) find(match func(*models.User) bool) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.users {
+		if match(&s.users[i]) {
+			return clone(&s.users[i])
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *UserStore) update(id primitive.ObjectID, set bson.M, unset ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.users {
+		if s.users[i].ID == id {
+			updated, err := apply(&s.users[i], set, unset...)
+			if err != nil {
+				return err
+			}
+			s.users[i] = *updated
+			break
+		}
+	}
+	return nil
+}
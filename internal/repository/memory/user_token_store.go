@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// UserTokenStore conserve en mémoire les tokens à usage unique envoyés par email
+type UserTokenStore struct {
+	mu     sync.Mutex
+	tokens []models.UserToken
+}
+
+func NewUserTokenStore() *UserTokenStore {
+	return &UserTokenStore{}
+}
+
+func (s *UserTokenStore) Create(ctx context.Context, token *models.UserToken) error {
+	token.ID = primitive.NewObjectID()
+	token.CreatedAt = time.Now()
+
+	stored, err := clone(token)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens = append(s.tokens, *stored)
+	s.mu.Unlock()
+	return nil
+}
+
+// Consume marque comme utilisé un token valide et le retourne
+func (s *UserTokenStore) Consume(ctx context.Context, tokenHash, purpose string) (*models.UserToken, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tokens {
+		token := &s.tokens[i]
+		if token.TokenHash == tokenHash && token.Purpose == purpose && token.UsedAt == nil && token.ExpiresAt.After(now) {
+			token.UsedAt = &now
+			return clone(token)
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// InvalidateByUserID invalide tous les tokens encore utilisables d'un utilisateur pour un usage donné
+func (s *UserTokenStore) InvalidateByUserID(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tokens {
+		token := &s.tokens[i]
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
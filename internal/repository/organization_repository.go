@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	bsonv2 "go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// OrganizationRepository conserve les organisations et leurs membres
+type OrganizationRepository struct {
+	collection *mongo.Collection
+	members    *mongo.Collection
+}
+
+func NewOrganizationRepository(db *mongo.Database) *OrganizationRepository {
+	return &OrganizationRepository{
+		collection: db.Collection("organizations"),
+		members:    db.Collection("organization_members"),
+	}
+}
+
+// Create enregistre une organisation ; un slug déjà utilisé fait échouer l'insertion (clé dupliquée)
+func (r *OrganizationRepository) Create(ctx context.Context, organization *models.Organization) error {
+	organization.ID = primitive.NewObjectID()
+	organization.CreatedAt = time.Now()
+	organization.UpdatedAt = organization.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, organization)
+	return err
+}
+
+// ExistsBySlug vérifie si un slug d'organisation est déjà utilisé
+func (r *OrganizationRepository) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"slug": slug})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindByID trouve une organisation par son ID
+func (r *OrganizationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Organization, error) {
+	var organization models.Organization
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&organization)
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// FindByIDs trouve plusieurs organisations ; les IDs inconnus sont ignorés
+func (r *OrganizationRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Organization, error) {
+	organizations := []models.Organization{}
+	if len(ids) == 0 {
+		return organizations, nil
+	}
+	return r.find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+// FindAll liste toutes les organisations par ordre alphabétique
+func (r *OrganizationRepository) FindAll(ctx context.Context) ([]models.Organization, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *OrganizationRepository) find(ctx context.Context, filter bson.M) ([]models.Organization, error) {
+	opts := options.Find().SetSort(bsonv2.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	organizations := []models.Organization{}
+	if err := cursor.All(ctx, &organizations); err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+// Update met à jour une organisation
+func (r *OrganizationRepository) Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error {
+	updates["updatedAt"] = time.Now()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": updates})
+	return err
+}
+
+// Delete supprime une organisation et ses membres
+func (r *OrganizationRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.members.DeleteMany(ctx, bson.M{"organizationId": id}); err != nil {
+		return err
+	}
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// AddMember ajoute un membre ; un utilisateur déjà membre fait échouer l'insertion (clé dupliquée)
+func (r *OrganizationRepository) AddMember(ctx context.Context, member *models.OrganizationMember) error {
+	member.ID = primitive.NewObjectID()
+	member.CreatedAt = time.Now()
+	member.UpdatedAt = member.CreatedAt
+
+	_, err := r.members.InsertOne(ctx, member)
+	return err
+}
+
+// FindMember trouve l'appartenance d'un utilisateur à une organisation
+func (r *OrganizationRepository) FindMember(ctx context.Context, organizationID, userID primitive.ObjectID) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := r.members.FindOne(ctx, bson.M{"organizationId": organizationID, "userId": userID}).Decode(&member)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindMembers liste les membres d'une organisation, du plus ancien au plus récent
+func (r *OrganizationRepository) FindMembers(ctx context.Context, organizationID primitive.ObjectID) ([]models.OrganizationMember, error) {
+	return r.findMembers(ctx, bson.M{"organizationId": organizationID})
+}
+
+// FindMembershipsByUserID liste les organisations dont un utilisateur est membre
+func (r *OrganizationRepository) FindMembershipsByUserID(ctx context.Context, userID primitive.ObjectID) ([]models.OrganizationMember, error) {
+	return r.findMembers(ctx, bson.M{"userId": userID})
+}
+
+func (r *OrganizationRepository) findMembers(ctx context.Context, filter bson.M) ([]models.OrganizationMember, error) {
+	opts := options.Find().SetSort(bsonv2.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := r.members.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	members := []models.OrganizationMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// CountOwners compte les propriétaires d'une organisation
+func (r *OrganizationRepository) CountOwners(ctx context.Context, organizationID primitive.ObjectID) (int64, error) {
+	return r.members.CountDocuments(ctx, bson.M{
+		"organizationId": organizationID,
+		"role":           models.OrganizationRoleOwner,
+	})
+}
+
+// CountMembers compte les membres de chaque organisation
+func (r *OrganizationRepository) CountMembers(ctx context.Context) (map[primitive.ObjectID]int64, error) {
+	pipeline := []interface{}{
+		bsonv2.M{"$group": bsonv2.M{"_id": "$organizationId", "count": bsonv2.M{"$sum": 1}}},
+	}
+
+	cursor, err := r.members.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]int64, len(results))
+	for _, result := range results {
+		counts[result.ID] = result.Count
+	}
+	return counts, nil
+}
+
+// UpdateMemberRole change le rôle d'un membre
+func (r *OrganizationRepository) UpdateMemberRole(ctx context.Context, organizationID, userID primitive.ObjectID, role string) error {
+	result, err := r.members.UpdateOne(
+		ctx,
+		bson.M{"organizationId": organizationID, "userId": userID},
+		bson.M{"$set": bson.M{"role": role, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// RemoveMember retire un membre d'une organisation
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID primitive.ObjectID) error {
+	result, err := r.members.DeleteOne(ctx, bson.M{"organizationId": organizationID, "userId": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteMembershipsByUserID retire un utilisateur de toutes ses organisations
+func (r *OrganizationRepository) DeleteMembershipsByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.members.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
+
+// EnsureIndexes crée les index des organisations (slug unique) et de leurs membres
+// (une seule appartenance par utilisateur et par organisation)
+func (r *OrganizationRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bsonv2.D{{Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.members.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bsonv2.D{{Key: "organizationId", Value: 1}, {Key: "userId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bsonv2.D{{Key: "userId", Value: 1}}},
+	})
+	return err
+}
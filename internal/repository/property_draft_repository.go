@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	bsonv2 "go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PropertyDraftRepository conserve les modifications en attente des propriétés publiées
+// (au plus un brouillon par propriété). Les secrets du contenu sont chiffrés.
+type PropertyDraftRepository struct {
+	collection *mongo.Collection
+	keyring    *fieldcrypt.Keyring
+}
+
+func NewPropertyDraftRepository(db *mongo.Database, keyring *fieldcrypt.Keyring) *PropertyDraftRepository {
+	return &PropertyDraftRepository{
+		collection: db.Collection("property_drafts"),
+		keyring:    keyring,
+	}
+}
+
+// FindByPropertyID trouve le brouillon d'une propriété, contenu déchiffré
+func (r *PropertyDraftRepository) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) (*models.PropertyDraft, error) {
+	var draft models.PropertyDraft
+	if err := r.collection.FindOne(ctx, bson.M{"propertyId": propertyID}).Decode(&draft); err != nil {
+		return nil, err
+	}
+	if err := DecryptPropertySecrets(r.keyring, draft.Content); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// Save applique des modifications au brouillon d'une propriété. S'il n'existe pas encore,
+// il est créé à partir du contenu en ligne.
+func (r *PropertyDraftRepository) Save(ctx context.Context, live *models.Property, updates bson.M, authorID *primitive.ObjectID) error {
+	encrypted, err := EncryptPropertySecrets(r.keyring, live)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = r.collection.UpdateOne(
+		ctx,
+		bson.M{"propertyId": live.ID},
+		bson.M{"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"propertyId": live.ID,
+			"content":    encrypted,
+			"createdAt":  now,
+		}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	if err := EncryptSecretUpdates(r.keyring, updates); err != nil {
+		return err
+	}
+
+	set := bson.M{"updatedAt": now}
+	for field, value := range updates {
+		set["content."+field] = value
+	}
+	if authorID != nil {
+		set["authorId"] = authorID
+	}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"propertyId": live.ID}, bson.M{"$set": set, "$inc": bson.M{"version": 1}})
+	return err
+}
+
+// DeleteIfUnchanged supprime le brouillon d'une propriété s'il n'a pas été modifié depuis
+// sa lecture (même version). Retourne false si le brouillon a changé ou n'existe plus.
+func (r *PropertyDraftRepository) DeleteIfUnchanged(ctx context.Context, propertyID primitive.ObjectID, version int64) (bool, error) {
+	filter := bson.M{"propertyId": propertyID, "version": version}
+	if version == 0 {
+		// Brouillons enregistrés avant l'ajout du champ version
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+// DeleteByPropertyID supprime le brouillon d'une propriété
+func (r *PropertyDraftRepository) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"propertyId": propertyID})
+	return err
+}
+
+// ReencryptAll rechiffre avec la clé active les secrets des brouillons.
+// Retourne le nombre de brouillons modifiés.
+func (r *PropertyDraftRepository) ReencryptAll(ctx context.Context) (int, error) {
+	if r.keyring == nil {
+		return 0, fieldcrypt.ErrNoActiveKey
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var draft models.PropertyDraft
+		if err := cursor.Decode(&draft); err != nil {
+			return updated, err
+		}
+
+		needsRotation := false
+		for _, field := range draft.Content.SecretFields() {
+			if r.keyring.NeedsRotation(*field) {
+				needsRotation = true
+				break
+			}
+		}
+		if !needsRotation {
+			continue
+		}
+
+		if err := DecryptPropertySecrets(r.keyring, draft.Content); err != nil {
+			return updated, fmt.Errorf("brouillon de la propriété %s: %w", draft.PropertyID.Hex(), err)
+		}
+		encrypted, err := EncryptPropertySecrets(r.keyring, draft.Content)
+		if err != nil {
+			return updated, err
+		}
+
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": draft.ID}, bson.M{"$set": bson.M{"content": encrypted}}); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, cursor.Err()
+}
+
+// EnsureIndexes crée l'index garantissant un seul brouillon par propriété
+func (r *PropertyDraftRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bsonv2.D{{Key: "propertyId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
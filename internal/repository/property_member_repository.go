@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	bsonv2 "go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PropertyMemberRepository conserve les collaborateurs des propriétés et les invitations
+type PropertyMemberRepository struct {
+	collection  *mongo.Collection
+	invitations *mongo.Collection
+}
+
+func NewPropertyMemberRepository(db *mongo.Database) *PropertyMemberRepository {
+	return &PropertyMemberRepository{
+		collection:  db.Collection("property_members"),
+		invitations: db.Collection("property_invitations"),
+	}
+}
+
+// Create ajoute un collaborateur ; un utilisateur déjà collaborateur fait échouer l'insertion (clé dupliquée)
+func (r *PropertyMemberRepository) Create(ctx context.Context, member *models.PropertyMember) error {
+	member.ID = primitive.NewObjectID()
+	member.CreatedAt = time.Now()
+	member.UpdatedAt = member.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, member)
+	return err
+}
+
+// FindByPropertyAndUser trouve le collaborateur d'une propriété correspondant à un utilisateur
+func (r *PropertyMemberRepository) FindByPropertyAndUser(ctx context.Context, propertyID, userID primitive.ObjectID) (*models.PropertyMember, error) {
+	var member models.PropertyMember
+	err := r.collection.FindOne(ctx, bson.M{"propertyId": propertyID, "userId": userID}).Decode(&member)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindByPropertyID liste les collaborateurs d'une propriété, du plus ancien au plus récent
+func (r *PropertyMemberRepository) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.PropertyMember, error) {
+	return r.find(ctx, bson.M{"propertyId": propertyID})
+}
+
+// FindByUserID liste les propriétés sur lesquelles un utilisateur collabore
+func (r *PropertyMemberRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]models.PropertyMember, error) {
+	return r.find(ctx, bson.M{"userId": userID})
+}
+
+func (r *PropertyMemberRepository) find(ctx context.Context, filter bson.M) ([]models.PropertyMember, error) {
+	opts := options.Find().SetSort(bsonv2.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	members := []models.PropertyMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// UpdateRole change le rôle d'un collaborateur
+func (r *PropertyMemberRepository) UpdateRole(ctx context.Context, propertyID, userID primitive.ObjectID, role string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"propertyId": propertyID, "userId": userID},
+		bson.M{"$set": bson.M{"role": role, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Delete retire un collaborateur d'une propriété
+func (r *PropertyMemberRepository) Delete(ctx context.Context, propertyID, userID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"propertyId": propertyID, "userId": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteByPropertyID supprime les collaborateurs et les invitations d'une propriété
+func (r *PropertyMemberRepository) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"propertyId": propertyID}); err != nil {
+		return err
+	}
+	_, err := r.invitations.DeleteMany(ctx, bson.M{"propertyId": propertyID})
+	return err
+}
+
+// DeleteByUserID retire un utilisateur de toutes les équipes dont il fait partie
+func (r *PropertyMemberRepository) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
+
+// CreateInvitation enregistre une invitation et remplace celles encore en attente pour
+// la même adresse : seul le dernier lien envoyé reste valable
+func (r *PropertyMemberRepository) CreateInvitation(ctx context.Context, invitation *models.PropertyInvitation) error {
+	_, err := r.invitations.DeleteMany(ctx, bson.M{
+		"propertyId": invitation.PropertyID,
+		"email":      invitation.Email,
+		"status":     models.InvitationStatusPending,
+	})
+	if err != nil {
+		return err
+	}
+
+	invitation.ID = primitive.NewObjectID()
+	invitation.Status = models.InvitationStatusPending
+	invitation.CreatedAt = time.Now()
+
+	_, err = r.invitations.InsertOne(ctx, invitation)
+	return err
+}
+
+// FindInvitationByTokenHash trouve une invitation par l'empreinte de son token
+func (r *PropertyMemberRepository) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.PropertyInvitation, error) {
+	var invitation models.PropertyInvitation
+	err := r.invitations.FindOne(ctx, bson.M{"tokenHash": tokenHash}).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindPendingInvitations liste les invitations en attente d'une propriété, de la plus récente à la plus ancienne
+func (r *PropertyMemberRepository) FindPendingInvitations(ctx context.Context, propertyID primitive.ObjectID) ([]models.PropertyInvitation, error) {
+	opts := options.Find().SetSort(bsonv2.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := r.invitations.Find(ctx, bson.M{
+		"propertyId": propertyID,
+		"status":     models.InvitationStatusPending,
+		"expiresAt":  bson.M{"$gt": time.Now()},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invitations := []models.PropertyInvitation{}
+	if err := cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// RespondInvitation enregistre la réponse à une invitation encore en attente.
+// La vérification et la mise à jour sont atomiques : une invitation ne reçoit qu'une réponse.
+func (r *PropertyMemberRepository) RespondInvitation(ctx context.Context, id primitive.ObjectID, status string) error {
+	now := time.Now()
+	result, err := r.invitations.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": models.InvitationStatusPending, "expiresAt": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"status": status, "respondedAt": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteInvitation annule une invitation en attente d'une propriété
+func (r *PropertyMemberRepository) DeleteInvitation(ctx context.Context, propertyID, id primitive.ObjectID) error {
+	result, err := r.invitations.DeleteOne(ctx, bson.M{
+		"_id":        id,
+		"propertyId": propertyID,
+		"status":     models.InvitationStatusPending,
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// EnsureIndexes crée les index des collaborateurs (un seul rôle par utilisateur et par
+// propriété) et des invitations
+func (r *PropertyMemberRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bsonv2.D{{Key: "propertyId", Value: 1}, {Key: "userId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bsonv2.D{{Key: "userId", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.invitations.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bsonv2.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bsonv2.D{{Key: "propertyId", Value: 1}, {Key: "status", Value: 1}}},
+	})
+	return err
+}
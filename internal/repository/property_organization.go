@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	bsonv2 "go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FindByOrganizationID liste les propriétés d'une organisation, par ordre alphabétique
+func (r *PropertyRepository) FindByOrganizationID(ctx context.Context, organizationID primitive.ObjectID, includeDraft bool) ([]models.Property, error) {
+	filter := bson.M{"organizationId": organizationID}
+	if !includeDraft {
+		filter["status"] = models.PropertyStatusPublished
+	}
+
+	opts := options.Find().SetSort(bsonv2.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	properties := []models.Property{}
+	if err := cursor.All(ctx, &properties); err != nil {
+		return nil, err
+	}
+	if err := r.decryptAll(properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+// SetOrganization confie une propriété à une organisation, ou la rend à son hôte si organizationID est nil
+func (r *PropertyRepository) SetOrganization(ctx context.Context, id primitive.ObjectID, organizationID *primitive.ObjectID) error {
+	update := bson.M{"$set": bson.M{"organizationId": organizationID, "updatedAt": time.Now()}}
+	if organizationID == nil {
+		update = bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{"organizationId": ""},
+		}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// DetachOrganization rend à leurs hôtes toutes les propriétés d'une organisation
+func (r *PropertyRepository) DetachOrganization(ctx context.Context, organizationID primitive.ObjectID) (int64, error) {
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"organizationId": organizationID},
+		bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{"organizationId": ""},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// FindOrganizationIDsByHostID liste les organisations dont un hôte gère des propriétés
+func (r *PropertyRepository) FindOrganizationIDsByHostID(ctx context.Context, hostID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	var ids []primitive.ObjectID
+	err := r.collection.Distinct(ctx, "organizationId", bson.M{
+		"hostId":         hostID,
+		"organizationId": bson.M{"$exists": true},
+	}).Decode(&ids)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ReassignOrganizationHost confie à un autre hôte les propriétés d'une organisation gérées par hostID
+func (r *PropertyRepository) ReassignOrganizationHost(ctx context.Context, organizationID, hostID, newHostID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"organizationId": organizationID, "hostId": hostID},
+		bson.M{"$set": bson.M{"hostId": newHostID, "updatedAt": time.Now()}},
+	)
+	return err
+}
+
+// CountByOrganization compte les propriétés, publiées ou non, de chaque organisation
+func (r *PropertyRepository) CountByOrganization(ctx context.Context) (map[primitive.ObjectID]models.OrganizationPropertyCount, error) {
+	pipeline := []interface{}{
+		bsonv2.M{"$match": bsonv2.M{"organizationId": bsonv2.M{"$exists": true}}},
+		bsonv2.M{"$group": bsonv2.M{
+			"_id":   "$organizationId",
+			"total": bsonv2.M{"$sum": 1},
+			"published": bsonv2.M{"$sum": bsonv2.M{
+				"$cond": bsonv2.A{bsonv2.M{"$eq": bsonv2.A{"$status", models.PropertyStatusPublished}}, 1, 0},
+			}},
+		}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID                               primitive.ObjectID `bson:"_id"`
+		models.OrganizationPropertyCount `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]models.OrganizationPropertyCount, len(results))
+	for _, result := range results {
+		counts[result.ID] = result.OrganizationPropertyCount
+	}
+	return counts, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FindByIDs trouve plusieurs propriétés ; les IDs inconnus sont ignorés
+func (r *PropertyRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Property, error) {
+	properties := []models.Property{}
+	if len(ids) == 0 {
+		return properties, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &properties); err != nil {
+		return nil, err
+	}
+	if err := r.decryptAll(properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+// TransferOwnership change l'hôte propriétaire d'une propriété
+func (r *PropertyRepository) TransferOwnership(ctx context.Context, id, hostID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"hostId": hostID, "updatedAt": time.Now()}},
+	)
+	return err
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var ErrTooManyPhotos = fmt.Errorf("une propriété ne peut pas avoir plus de %d photos", models.MaxPropertyImages)
+
+// ErrPhotosChanged est retourné par SetPhotos lorsque les photos ont été modifiées depuis leur lecture
+var ErrPhotosChanged = errors.New("les photos de la propriété ont été modifiées entre-temps")
+
+// ErrPhotoNotFound est retourné par DeletePhoto lorsque la photo n'existe pas (ou plus)
+var ErrPhotoNotFound = errors.New("photo introuvable")
+
+// AddPhoto ajoute une photo en fin de liste. La limite de photos est vérifiée dans le filtre
+// pour que deux envois simultanés ne puissent pas la dépasser. Si la propriété n'a pas
+// encore de couverture, la première photo le devient.
+func (r *PropertyRepository) AddPhoto(ctx context.Context, propertyID primitive.ObjectID, photo models.PropertyImage) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id": propertyID,
+			fmt.Sprintf("photos.%d", models.MaxPropertyImages-1): bson.M{"$exists": false},
+		},
+		bson.M{
+			"$push": bson.M{"photos": photo},
+			"$set":  bson.M{"updatedAt": time.Now()},
+			"$inc":  bson.M{"photosVersion": 1},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrTooManyPhotos
+	}
+	return r.ensureCover(ctx, propertyID)
+}
+
+// SetPhotos remplace la liste des photos (ordre, légendes, couverture) si elle n'a pas été
+// modifiée depuis sa lecture (même PhotosVersion), sinon retourne ErrPhotosChanged
+func (r *PropertyRepository) SetPhotos(ctx context.Context, propertyID primitive.ObjectID, version int64, photos []models.PropertyImage) error {
+	if photos == nil {
+		photos = []models.PropertyImage{}
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": propertyID, "photosVersion": photosVersionFilter(version)},
+		bson.M{
+			"$set": bson.M{"photos": photos, "updatedAt": time.Now()},
+			"$inc": bson.M{"photosVersion": 1},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrPhotosChanged
+	}
+	return nil
+}
+
+// DeletePhoto retire une photo et la retourne ; si c'était la couverture, la première photo
+// restante la remplace
+func (r *PropertyRepository) DeletePhoto(ctx context.Context, propertyID, photoID primitive.ObjectID) (*models.PropertyImage, error) {
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"photos": 1})
+
+	var before models.Property
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": propertyID, "photos.id": photoID},
+		bson.M{
+			"$pull": bson.M{"photos": bson.M{"id": photoID}},
+			"$set":  bson.M{"updatedAt": time.Now()},
+			"$inc":  bson.M{"photosVersion": 1},
+		},
+		opts,
+	).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrPhotoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var removed *models.PropertyImage
+	for i := range before.Photos {
+		if before.Photos[i].ID == photoID {
+			removed = &before.Photos[i]
+			break
+		}
+	}
+	if removed == nil {
+		return nil, ErrPhotoNotFound
+	}
+	if removed.IsCover {
+		if err := r.ensureCover(ctx, propertyID); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// ensureCover désigne la première photo comme couverture si aucune ne l'est. La condition
+// est dans le filtre : deux requêtes simultanées ne peuvent pas créer deux couvertures.
+func (r *PropertyRepository) ensureCover(ctx context.Context, propertyID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":            propertyID,
+			"photos.0":       bson.M{"$exists": true},
+			"photos.isCover": bson.M{"$ne": true},
+		},
+		bson.M{
+			"$set": bson.M{"photos.0.isCover": true},
+			"$inc": bson.M{"photosVersion": 1},
+		},
+	)
+	return err
+}
+
+// photosVersionFilter retourne le filtre d'une version de photos ; le champ est absent
+// des propriétés dont les photos n'ont jamais été modifiées
+func photosVersionFilter(version int64) any {
+	if version == 0 {
+		return bson.M{"$exists": false}
+	}
+	return version
+}
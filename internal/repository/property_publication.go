@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Publish met la propriété en ligne en une seule opération : le contenu du brouillon
+// (éventuellement vide) remplace le contenu en ligne et une publication programmée est annulée.
+// publishedAt n'est renseigné que lors du passage de brouillon à publié.
+func (r *PropertyRepository) Publish(ctx context.Context, id primitive.ObjectID, content bson.M, publishedAt *time.Time) error {
+	if err := EncryptSecretUpdates(r.keyring, content); err != nil {
+		return err
+	}
+
+	set := bson.M{"status": models.PropertyStatusPublished, "updatedAt": time.Now()}
+	for field, value := range content {
+		set[field] = value
+	}
+	if publishedAt != nil {
+		set["publishedAt"] = publishedAt
+	}
+
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": set, "$unset": bson.M{"scheduledPublishAt": ""}},
+	)
+	return err
+}
+
+// Unpublish repasse la propriété en brouillon ; le contenu en attente, s'il y en a un,
+// devient le contenu de la propriété
+func (r *PropertyRepository) Unpublish(ctx context.Context, id primitive.ObjectID, content bson.M) error {
+	if err := EncryptSecretUpdates(r.keyring, content); err != nil {
+		return err
+	}
+
+	set := bson.M{"status": models.PropertyStatusDraft, "updatedAt": time.Now()}
+	for field, value := range content {
+		set[field] = value
+	}
+
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": set, "$unset": bson.M{"scheduledPublishAt": "", "publishedAt": ""}},
+	)
+	return err
+}
+
+// SchedulePublication programme la publication (ou l'annule si at est nil)
+func (r *PropertyRepository) SchedulePublication(ctx context.Context, id primitive.ObjectID, at *time.Time) error {
+	update := bson.M{"$unset": bson.M{"scheduledPublishAt": ""}}
+	if at != nil {
+		update = bson.M{"$set": bson.M{"scheduledPublishAt": at}}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// FindDueForPublication liste les propriétés dont la publication programmée est échue
+func (r *PropertyRepository) FindDueForPublication(ctx context.Context, now time.Time) ([]models.Property, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"scheduledPublishAt": bson.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	properties := []models.Property{}
+	if err := cursor.All(ctx, &properties); err != nil {
+		return nil, err
+	}
+	if err := r.decryptAll(properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
@@ -2,34 +2,41 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
-	"onestay-back/internal/database"
+	"onestay-back/internal/fieldcrypt"
 	"onestay-back/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/v2/mongo"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type PropertyRepository struct {
 	collection *mongo.Collection
+	keyring    *fieldcrypt.Keyring
 }
 
-func NewPropertyRepository() *PropertyRepository {
+func NewPropertyRepository(db *mongo.Database, keyring *fieldcrypt.Keyring) *PropertyRepository {
 	return &PropertyRepository{
-		collection: database.DB.Collection("properties"),
+		collection: db.Collection("properties"),
+		keyring:    keyring,
 	}
 }
 
-// Create crée une nouvelle propriété
+// Create crée une nouvelle propriété (les champs sensibles sont chiffrés en base)
 func (r *PropertyRepository) Create(ctx context.Context, property *models.Property) error {
 	property.ID = primitive.NewObjectID()
 	property.CreatedAt = time.Now()
 	property.UpdatedAt = time.Now()
 
-	_, err := r.collection.InsertOne(ctx, property)
+	encrypted, err := EncryptPropertySecrets(r.keyring, property)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.InsertOne(ctx, encrypted)
 	return err
 }
 
@@ -63,6 +70,9 @@ func (r *PropertyRepository) FindBySlug(ctx context.Context, slug string) (*mode
 	if err != nil {
 		return nil, err
 	}
+	if err := DecryptPropertySecrets(r.keyring, &property); err != nil {
+		return nil, err
+	}
 	return &property, nil
 }
 
@@ -73,12 +83,16 @@ func (r *PropertyRepository) FindByID(ctx context.Context, id primitive.ObjectID
 	if err != nil {
 		return nil, err
 	}
+	if err := DecryptPropertySecrets(r.keyring, &property); err != nil {
+		return nil, err
+	}
 	return &property, nil
 }
 
-// FindByHostID trouve toutes les propriétés d'un hôte
+// FindByHostID trouve les propriétés personnelles d'un hôte ; celles qu'il gère pour une
+// organisation sont listées avec l'organisation
 func (r *PropertyRepository) FindByHostID(ctx context.Context, hostID primitive.ObjectID, includeDraft bool) ([]models.Property, error) {
-	filter := bson.M{"hostId": hostID}
+	filter := bson.M{"hostId": hostID, "organizationId": bson.M{"$exists": false}}
 	
 	// Si on ne doit pas inclure les brouillons, filtrer uniquement les publiés (status = 2)
 	if !includeDraft {
@@ -95,13 +109,20 @@ func (r *PropertyRepository) FindByHostID(ctx context.Context, hostID primitive.
 	if err := cursor.All(ctx, &properties); err != nil {
 		return nil, err
 	}
+	if err := r.decryptAll(properties); err != nil {
+		return nil, err
+	}
 	return properties, nil
 }
 
 // Update met à jour une propriété
 func (r *PropertyRepository) Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error {
 	updates["updatedAt"] = time.Now()
-	
+
+	if err := EncryptSecretUpdates(r.keyring, updates); err != nil {
+		return err
+	}
+
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": id},
@@ -116,33 +137,85 @@ func (r *PropertyRepository) Delete(ctx context.Context, id primitive.ObjectID)
 	return err
 }
 
-// DeleteByHostID supprime toutes les propriétés d'un hôte
+// DeleteByHostID supprime les propriétés personnelles d'un hôte ; celles des organisations
+// leur restent acquises
 func (r *PropertyRepository) DeleteByHostID(ctx context.Context, hostID primitive.ObjectID) (int64, error) {
-	result, err := r.collection.DeleteMany(ctx, bson.M{"hostId": hostID})
+	result, err := r.collection.DeleteMany(ctx, bson.M{"hostId": hostID, "organizationId": bson.M{"$exists": false}})
 	if err != nil {
 		return 0, err
 	}
 	return result.DeletedCount, nil
 }
 
-// FindAll trouve toutes les propriétés publiées (pour recherche publique)
-func (r *PropertyRepository) FindAll(ctx context.Context, limit, skip int64) ([]models.Property, error) {
-	filter := bson.M{"status": 2} // 2 = publié
-	
-	opts := options.Find().
-		SetLimit(limit).
-		SetSkip(skip).
-		SetSort(bson.M{"createdAt": -1})
+func (r *PropertyRepository) decryptAll(properties []models.Property) error {
+	for i := range properties {
+		if err := DecryptPropertySecrets(r.keyring, &properties[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	cursor, err := r.collection.Find(ctx, filter, opts)
+// ReencryptAll rechiffre avec la clé active tous les champs sensibles encore en clair
+// ou chiffrés avec une ancienne clé. Retourne le nombre de propriétés modifiées.
+func (r *PropertyRepository) ReencryptAll(ctx context.Context) (int, error) {
+	if r.keyring == nil {
+		return 0, fieldcrypt.ErrNoActiveKey
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{})
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 	defer cursor.Close(ctx)
 
-	var properties []models.Property
-	if err := cursor.All(ctx, &properties); err != nil {
-		return nil, err
+	updated := 0
+	for cursor.Next(ctx) {
+		var property models.Property
+		if err := cursor.Decode(&property); err != nil {
+			return updated, err
+		}
+
+		needsRotation := false
+		for _, field := range property.SecretFields() {
+			if r.keyring.NeedsRotation(*field) {
+				needsRotation = true
+				break
+			}
+		}
+		if !needsRotation {
+			continue
+		}
+
+		if err := DecryptPropertySecrets(r.keyring, &property); err != nil {
+			return updated, fmt.Errorf("propriété %s: %w", property.ID.Hex(), err)
+		}
+
+		encrypted, err := EncryptPropertySecrets(r.keyring, &property)
+		if err != nil {
+			return updated, err
+		}
+
+		set := bson.M{}
+		if encrypted.CheckInOut != nil {
+			set["checkInOut"] = encrypted.CheckInOut
+		}
+		if encrypted.Wifi != nil {
+			set["wifi"] = encrypted.Wifi
+		}
+		if encrypted.Parking != nil {
+			set["parking"] = encrypted.Parking
+		}
+		if encrypted.Security != nil {
+			set["security"] = encrypted.Security
+		}
+
+		// updatedAt n'est pas modifié : le contenu visible par l'hôte reste identique
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": property.ID}, bson.M{"$set": set}); err != nil {
+			return updated, err
+		}
+		updated++
 	}
-	return properties, nil
+
+	return updated, cursor.Err()
 }
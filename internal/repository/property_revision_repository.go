@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	bsonv2 "go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PropertyRevisionRepository conserve l'historique des propriétés. Les instantanés
+// contiennent les mêmes secrets que la propriété : ils sont chiffrés de la même façon.
+type PropertyRevisionRepository struct {
+	collection *mongo.Collection
+	keyring    *fieldcrypt.Keyring
+}
+
+func NewPropertyRevisionRepository(db *mongo.Database, keyring *fieldcrypt.Keyring) *PropertyRevisionRepository {
+	return &PropertyRevisionRepository{
+		collection: db.Collection("property_revisions"),
+		keyring:    keyring,
+	}
+}
+
+// Record enregistre l'état after comme nouvelle révision. before est l'état qui précédait
+// la modification : il sert de révision initiale pour les propriétés créées avant
+// l'historique. Une modification sans effet n'est pas enregistrée (revision.Number reste à 0).
+func (r *PropertyRevisionRepository) Record(ctx context.Context, before, after *models.Property, revision *models.PropertyRevision) error {
+	// Deux enregistrements simultanés peuvent viser le même numéro : l'index unique
+	// fait échouer le second, qui repart du nouveau dernier numéro
+	for attempt := 0; ; attempt++ {
+		err := r.record(ctx, before, after, revision)
+		if err == nil || !mongo.IsDuplicateKeyError(err) || attempt == 2 {
+			return err
+		}
+	}
+}
+
+func (r *PropertyRevisionRepository) record(ctx context.Context, before, after *models.Property, revision *models.PropertyRevision) error {
+	latest, err := r.findLatest(ctx, after.ID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	previous := before
+	number := 0
+	if latest != nil {
+		previous = latest.Snapshot
+		number = latest.Number
+	} else if before != nil {
+		initial := &models.PropertyRevision{
+			PropertyID: after.ID,
+			Number:     1,
+			Action:     models.RevisionActionInitial,
+			Sections:   []string{},
+		}
+		if err := r.insert(ctx, initial, before, before.UpdatedAt); err != nil {
+			return err
+		}
+		number = 1
+	}
+
+	sections := models.ChangedSections(previous, after)
+	if len(sections) == 0 && previous != nil {
+		return nil
+	}
+
+	revision.PropertyID = after.ID
+	revision.Number = number + 1
+	revision.Sections = sections
+	if err := r.insert(ctx, revision, after, time.Now()); err != nil {
+		revision.Number = 0
+		return err
+	}
+
+	// Purge des révisions les plus anciennes
+	if revision.Number > models.MaxPropertyRevisions {
+		_, err = r.collection.DeleteMany(ctx, bson.M{
+			"propertyId": after.ID,
+			"number":     bson.M{"$lte": revision.Number - models.MaxPropertyRevisions},
+		})
+	}
+	return err
+}
+
+func (r *PropertyRevisionRepository) insert(ctx context.Context, revision *models.PropertyRevision, snapshot *models.Property, createdAt time.Time) error {
+	encrypted, err := EncryptPropertySecrets(r.keyring, snapshot)
+	if err != nil {
+		return err
+	}
+
+	revision.ID = primitive.NewObjectID()
+	revision.CreatedAt = createdAt
+	revision.Snapshot = encrypted
+
+	_, err = r.collection.InsertOne(ctx, revision)
+	revision.Snapshot = snapshot
+	return err
+}
+
+// findLatest retourne la dernière révision d'une propriété, instantané déchiffré
+func (r *PropertyRevisionRepository) findLatest(ctx context.Context, propertyID primitive.ObjectID) (*models.PropertyRevision, error) {
+	opts := options.FindOne().SetSort(bsonv2.D{{Key: "number", Value: -1}})
+
+	var revision models.PropertyRevision
+	if err := r.collection.FindOne(ctx, bson.M{"propertyId": propertyID}, opts).Decode(&revision); err != nil {
+		return nil, err
+	}
+	if err := r.decryptSnapshot(&revision); err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// FindByPropertyID liste les révisions d'une propriété, de la plus récente à la plus ancienne,
+// sans les instantanés
+func (r *PropertyRevisionRepository) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, limit int64) ([]models.PropertyRevision, error) {
+	opts := options.Find().
+		SetSort(bsonv2.D{{Key: "number", Value: -1}}).
+		SetProjection(bson.M{"snapshot": 0}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, bson.M{"propertyId": propertyID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []models.PropertyRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// FindByNumber trouve une révision par son numéro, instantané déchiffré
+func (r *PropertyRevisionRepository) FindByNumber(ctx context.Context, propertyID primitive.ObjectID, number int) (*models.PropertyRevision, error) {
+	var revision models.PropertyRevision
+	err := r.collection.FindOne(ctx, bson.M{"propertyId": propertyID, "number": number}).Decode(&revision)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.decryptSnapshot(&revision); err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// DeleteByPropertyID supprime l'historique d'une propriété
+func (r *PropertyRevisionRepository) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"propertyId": propertyID})
+	return err
+}
+
+func (r *PropertyRevisionRepository) decryptSnapshot(revision *models.PropertyRevision) error {
+	if revision.Snapshot == nil {
+		return nil
+	}
+	if err := DecryptPropertySecrets(r.keyring, revision.Snapshot); err != nil {
+		return fmt.Errorf("révision %d: %w", revision.Number, err)
+	}
+	return nil
+}
+
+// ReencryptAll rechiffre avec la clé active les secrets des instantanés.
+// Retourne le nombre de révisions modifiées.
+func (r *PropertyRevisionRepository) ReencryptAll(ctx context.Context) (int, error) {
+	if r.keyring == nil {
+		return 0, fieldcrypt.ErrNoActiveKey
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"snapshot": bson.M{"$exists": true}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var revision models.PropertyRevision
+		if err := cursor.Decode(&revision); err != nil {
+			return updated, err
+		}
+
+		needsRotation := false
+		for _, field := range revision.Snapshot.SecretFields() {
+			if r.keyring.NeedsRotation(*field) {
+				needsRotation = true
+				break
+			}
+		}
+		if !needsRotation {
+			continue
+		}
+
+		if err := r.decryptSnapshot(&revision); err != nil {
+			return updated, fmt.Errorf("propriété %s: %w", revision.PropertyID.Hex(), err)
+		}
+		encrypted, err := EncryptPropertySecrets(r.keyring, revision.Snapshot)
+		if err != nil {
+			return updated, err
+		}
+
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": revision.ID}, bson.M{"$set": bson.M{"snapshot": encrypted}}); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, cursor.Err()
+}
+
+// EnsureIndexes crée l'index de l'historique ; il garantit aussi l'unicité des numéros
+func (r *PropertyRevisionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bsonv2.D{{Key: "propertyId", Value: 1}, {Key: "number", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	// Les clés de tri et d'index doivent être des documents ordonnés : le driver v2
+	// ne sait pas encoder le bson.D de la v1 comme un document
+	bsonv2 "go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrInvalidCursor est retourné lorsque le curseur de pagination est illisible
+// ou a été obtenu avec un autre tri
+var ErrInvalidCursor = errors.New("curseur de pagination invalide")
+
+// PropertyPage représente une page de résultats de recherche
+type PropertyPage struct {
+	Properties []models.Property
+	Total      int64  // Nombre total de propriétés correspondant aux filtres
+	NextCursor string // Vide s'il n'y a pas de page suivante
+}
+
+// propertyCursor repère la dernière propriété d'une page pour le tri courant
+type propertyCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// propertySortField associe chaque tri à son champ et à son sens
+var propertySortField = map[string]struct {
+	field string
+	order int
+}{
+	models.PropertySortNewest:   {"createdAt", -1},
+	models.PropertySortOldest:   {"createdAt", 1},
+	models.PropertySortNameAsc:  {"name", 1},
+	models.PropertySortNameDesc: {"name", -1},
+	models.PropertySortDistance: {"distanceMeters", 1},
+}
+
+// sphereRadiusKm est le rayon terrestre utilisé par MongoDB pour $centerSphere
+const sphereRadiusKm = 6378.1
+
+// FindAll recherche les propriétés publiées selon les critères donnés, avec une
+// pagination par curseur (stable même si des propriétés sont publiées entre deux pages)
+func (r *PropertyRepository) FindAll(ctx context.Context, query models.PropertySearchQuery) (*PropertyPage, error) {
+	if query.Sort == "" {
+		query.Sort = models.PropertySortNewest
+	}
+	if query.Limit <= 0 {
+		query.Limit = models.PropertySearchDefaultLimit
+	}
+	if query.NearPoint != nil && query.RadiusKm <= 0 {
+		query.RadiusKm = models.PropertySearchDefaultRadiusKm
+	}
+
+	filter := buildPropertySearchFilter(query)
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursorFilter bson.M
+	if query.Cursor != "" {
+		cursorFilter, err = decodePropertyCursor(query.Cursor, query.Sort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var properties []models.Property
+	if query.Sort == models.PropertySortDistance {
+		properties, err = r.findNearest(ctx, query, filter, cursorFilter)
+	} else {
+		pageFilter := filter
+		if cursorFilter != nil {
+			pageFilter = bson.M{"$and": []bson.M{filter, cursorFilter}}
+		}
+		properties, err = r.findSorted(ctx, query, pageFilter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	page := &PropertyPage{Total: total}
+	if int64(len(properties)) > query.Limit {
+		properties = properties[:query.Limit]
+		page.NextCursor = encodePropertyCursor(query.Sort, &properties[len(properties)-1])
+	}
+
+	if err := r.decryptAll(properties); err != nil {
+		return nil, err
+	}
+	page.Properties = properties
+	return page, nil
+}
+
+// findSorted exécute la recherche triée sur un champ du document
+func (r *PropertyRepository) findSorted(ctx context.Context, query models.PropertySearchQuery, filter bson.M) ([]models.Property, error) {
+	sort := propertySortField[query.Sort]
+	opts := options.Find().
+		SetLimit(query.Limit + 1).
+		SetSort(bsonv2.D{{Key: sort.field, Value: sort.order}, {Key: "_id", Value: sort.order}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	properties := []models.Property{}
+	if err := cursor.All(ctx, &properties); err != nil {
+		return nil, err
+	}
+
+	if query.NearPoint != nil {
+		for i := range properties {
+			if properties[i].Location != nil {
+				distance := query.NearPoint.DistanceTo(properties[i].Location)
+				properties[i].DistanceMeters = &distance
+			}
+		}
+	}
+	return properties, nil
+}
+
+// findNearest exécute la recherche triée par distance au point demandé ($geoNear).
+// La distance calculée par MongoDB sert aussi de clé au curseur de pagination.
+func (r *PropertyRepository) findNearest(ctx context.Context, query models.PropertySearchQuery, filter, cursorFilter bson.M) ([]models.Property, error) {
+	// $geoNear applique lui-même le rayon : le filtre $geoWithin est retiré de sa requête,
+	// et le filtre du curseur porte sur la distance calculée, donc après $geoNear
+	geoQuery := bson.M{}
+	for key, value := range filter {
+		if key != "location" {
+			geoQuery[key] = value
+		}
+	}
+
+	pipeline := []interface{}{
+		bsonv2.M{"$geoNear": bsonv2.M{
+			"near":          bson.M{"type": "Point", "coordinates": query.NearPoint.Coordinates},
+			"key":           "location",
+			"distanceField": "distanceMeters",
+			"maxDistance":   query.RadiusKm * 1000,
+			"spherical":     true,
+			"query":         geoQuery,
+		}},
+	}
+	if cursorFilter != nil {
+		pipeline = append(pipeline, bsonv2.M{"$match": cursorFilter})
+	}
+	pipeline = append(pipeline,
+		bsonv2.M{"$sort": bsonv2.D{{Key: "distanceMeters", Value: 1}, {Key: "_id", Value: 1}}},
+		bsonv2.M{"$limit": query.Limit + 1},
+	)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		models.Property `bson:",inline"`
+		DistanceMeters  float64 `bson:"distanceMeters"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	properties := make([]models.Property, len(results))
+	for i := range results {
+		properties[i] = results[i].Property
+		distance := results[i].DistanceMeters
+		properties[i].DistanceMeters = &distance
+	}
+	return properties, nil
+}
+
+// buildPropertySearchFilter traduit les critères de recherche en filtre MongoDB
+func buildPropertySearchFilter(query models.PropertySearchQuery) bson.M {
+	filter := bson.M{"status": 2} // 2 = publié
+
+	if query.Text != "" {
+		filter["$text"] = bson.M{"$search": query.Text}
+	}
+	if query.City != "" {
+		filter["city"] = equalFoldPattern(query.City)
+	}
+	if query.Country != "" {
+		filter["country"] = equalFoldPattern(query.Country)
+	}
+	if query.ZipCode != "" {
+		filter["zipCode"] = bson.M{"$regex": "^" + regexp.QuoteMeta(query.ZipCode)}
+	}
+	if query.Guests > 0 {
+		filter["rules.maxGuests"] = bson.M{"$gte": query.Guests}
+	}
+	if query.NearPoint != nil {
+		filter["location"] = bson.M{"$geoWithin": bson.M{
+			"$centerSphere": []interface{}{query.NearPoint.Coordinates, query.RadiusKm / sphereRadiusKm},
+		}}
+	}
+	if len(query.EquipmentCategories) > 0 {
+		filter["equipment.items.category"] = bson.M{"$all": query.EquipmentCategories}
+	}
+
+	flags := map[string]*bool{
+		"rules.petsAllowed":     query.PetsAllowed,
+		"rules.childrenAllowed": query.ChildrenAllowed,
+		"parking.available":     query.Parking,
+		"outdoor.hasPool":       query.Pool,
+		"outdoor.hasSpa":        query.Spa,
+		"outdoor.hasGarden":     query.Garden,
+	}
+	for field, value := range flags {
+		switch {
+		case value == nil:
+		case *value:
+			filter[field] = true
+		default:
+			// Les booléens à false ne sont pas stockés (omitempty)
+			filter[field] = bson.M{"$ne": true}
+		}
+	}
+
+	return filter
+}
+
+// equalFoldPattern construit une égalité insensible à la casse
+func equalFoldPattern(value string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(value) + "$", "$options": "i"}
+}
+
+func encodePropertyCursor(sort string, last *models.Property) string {
+	c := propertyCursor{Sort: sort, ID: last.ID.Hex()}
+	switch propertySortField[sort].field {
+	case "createdAt":
+		c.Value = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "distanceMeters":
+		c.Value = strconv.FormatFloat(*last.DistanceMeters, 'g', -1, 64)
+	default:
+		c.Value = last.Name
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePropertyCursor retourne le filtre sélectionnant les propriétés situées après le curseur
+func decodePropertyCursor(raw, sort string) (bson.M, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c propertyCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	field := propertySortField[sort]
+	var value interface{} = c.Value
+	switch field.field {
+	case "createdAt":
+		createdAt, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		value = createdAt
+	case "distanceMeters":
+		distance, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		value = distance
+	}
+
+	op := "$gt"
+	if field.order < 0 {
+		op = "$lt"
+	}
+
+	return bson.M{"$or": []bson.M{
+		{field.field: bson.M{op: value}},
+		{field.field: value, "_id": bson.M{op: id}},
+	}}, nil
+}
+
+// EnsureIndexes crée les index utilisés par la recherche publique (dont l'index géographique)
+func (r *PropertyRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bsonv2.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bsonv2.D{{Key: "status", Value: 1}, {Key: "name", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bsonv2.D{{Key: "status", Value: 1}, {Key: "city", Value: 1}}},
+		{Keys: bsonv2.D{{Key: "status", Value: 1}, {Key: "country", Value: 1}}},
+		{Keys: bsonv2.D{{Key: "status", Value: 1}, {Key: "zipCode", Value: 1}}},
+		{Keys: bsonv2.D{{Key: "status", Value: 1}, {Key: "rules.maxGuests", Value: 1}}},
+		{Keys: bsonv2.D{{Key: "status", Value: 1}, {Key: "equipment.items.category", Value: 1}}},
+		{Keys: bsonv2.D{{Key: "hostId", Value: 1}}},
+		{Keys: bsonv2.D{{Key: "organizationId", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bsonv2.D{{Key: "scheduledPublishAt", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bsonv2.D{{Key: "location", Value: "2dsphere"}}},
+		{
+			Keys: bsonv2.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().
+				SetName("property_text_search").
+				SetWeights(bsonv2.D{{Key: "name", Value: 10}, {Key: "description", Value: 1}}).
+				SetDefaultLanguage("french").
+				// Champ volontairement inexistant : un futur champ "language" ne doit pas changer l'analyse
+				SetLanguageOverride("textSearchLanguage"),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
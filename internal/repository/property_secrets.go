@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// cloneSecretSections retourne une copie de la propriété dont les sous-documents
+// contenant des secrets sont dupliqués, pour pouvoir les modifier sans toucher l'original
+func cloneSecretSections(p *models.Property) *models.Property {
+	clone := *p
+	if p.CheckInOut != nil {
+		v := *p.CheckInOut
+		clone.CheckInOut = &v
+	}
+	if p.Wifi != nil {
+		v := *p.Wifi
+		clone.Wifi = &v
+	}
+	if p.Parking != nil {
+		v := *p.Parking
+		clone.Parking = &v
+	}
+	if p.Security != nil {
+		v := *p.Security
+		clone.Security = &v
+	}
+	return &clone
+}
+
+// EncryptPropertySecrets retourne une copie de la propriété dont les champs sensibles sont chiffrés
+func EncryptPropertySecrets(keyring *fieldcrypt.Keyring, p *models.Property) (*models.Property, error) {
+	clone := cloneSecretSections(p)
+	for _, field := range clone.SecretFields() {
+		encrypted, err := keyring.Encrypt(*field)
+		if err != nil {
+			return nil, err
+		}
+		*field = encrypted
+	}
+	return clone, nil
+}
+
+// DecryptPropertySecrets déchiffre en place les champs sensibles d'une propriété lue en base
+func DecryptPropertySecrets(keyring *fieldcrypt.Keyring, p *models.Property) error {
+	for _, field := range p.SecretFields() {
+		decrypted, err := keyring.Decrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = decrypted
+	}
+	return nil
+}
+
+// EncryptSecretUpdates chiffre les sous-documents sensibles présents dans un $set
+func EncryptSecretUpdates(keyring *fieldcrypt.Keyring, updates bson.M) error {
+	partial := &models.Property{}
+	if v, ok := updates["checkInOut"].(*models.CheckInOut); ok {
+		partial.CheckInOut = v
+	}
+	if v, ok := updates["wifi"].(*models.Wifi); ok {
+		partial.Wifi = v
+	}
+	if v, ok := updates["parking"].(*models.Parking); ok {
+		partial.Parking = v
+	}
+	if v, ok := updates["security"].(*models.Security); ok {
+		partial.Security = v
+	}
+
+	encrypted, err := EncryptPropertySecrets(keyring, partial)
+	if err != nil {
+		return err
+	}
+
+	if encrypted.CheckInOut != nil {
+		updates["checkInOut"] = encrypted.CheckInOut
+	}
+	if encrypted.Wifi != nil {
+		updates["wifi"] = encrypted.Wifi
+	}
+	if encrypted.Parking != nil {
+		updates["parking"] = encrypted.Parking
+	}
+	if encrypted.Security != nil {
+		updates["security"] = encrypted.Security
+	}
+	return nil
+}
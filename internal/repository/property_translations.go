@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetTranslations remplace l'ensemble des traductions d'une propriété
+func (r *PropertyRepository) SetTranslations(ctx context.Context, propertyID primitive.ObjectID, translations []models.PropertyTranslation) error {
+	if translations == nil {
+		translations = []models.PropertyTranslation{}
+	}
+	return r.Update(ctx, propertyID, bson.M{"translations": translations})
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ReservationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReservationRepository(db *mongo.Database) *ReservationRepository {
+	return &ReservationRepository{
+		collection: db.Collection("reservations"),
+	}
+}
+
+// Create crée une nouvelle réservation
+func (r *ReservationRepository) Create(ctx context.Context, reservation *models.Reservation) error {
+	reservation.ID = primitive.NewObjectID()
+	reservation.CreatedAt = time.Now()
+	reservation.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, reservation)
+	return err
+}
+
+// FindByID trouve une réservation par son ID
+func (r *ReservationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&reservation)
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// FindByPropertyID liste les réservations d'une propriété, triées par date d'arrivée.
+// Les bornes from/to (optionnelles) filtrent les séjours qui chevauchent la période.
+func (r *ReservationRepository) FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, status string, from, to *time.Time) ([]models.Reservation, error) {
+	filter := bson.M{"propertyId": propertyID}
+	if status != "" {
+		filter["status"] = status
+	}
+	if from != nil {
+		filter["departureDate"] = bson.M{"$gt": *from}
+	}
+	if to != nil {
+		filter["arrivalDate"] = bson.M{"$lt": *to}
+	}
+
+	opts := options.Find().SetSort(bson.M{"arrivalDate": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reservations := []models.Reservation{}
+	if err := cursor.All(ctx, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// HasOverlap vérifie si une réservation non annulée chevauche la période [arrival, departure[.
+// Le jour de départ d'un séjour peut être le jour d'arrivée du suivant.
+func (r *ReservationRepository) HasOverlap(ctx context.Context, propertyID primitive.ObjectID, arrival, departure time.Time, excludeID *primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"propertyId":    propertyID,
+		"status":        bson.M{"$ne": models.ReservationStatusCancelled},
+		"arrivalDate":   bson.M{"$lt": departure},
+		"departureDate": bson.M{"$gt": arrival},
+	}
+	if excludeID != nil {
+		filter["_id"] = bson.M{"$ne": *excludeID}
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Update met à jour une réservation
+func (r *ReservationRepository) Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error {
+	updates["updatedAt"] = time.Now()
+
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": updates},
+	)
+	return err
+}
+
+// Delete supprime une réservation
+func (r *ReservationRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// DeleteByPropertyID supprime toutes les réservations d'une propriété
+func (r *ReservationRepository) DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"propertyId": propertyID})
+	return err
+}
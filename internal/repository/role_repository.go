@@ -3,9 +3,9 @@ package repository
 import (
 	"context"
 	"log"
+	"reflect"
 	"time"
 
-	"onestay-back/internal/database"
 	"onestay-back/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,9 +16,9 @@ type RoleRepository struct {
 	collection *mongo.Collection
 }
 
-func NewRoleRepository() *RoleRepository {
+func NewRoleRepository(db *mongo.Database) *RoleRepository {
 	return &RoleRepository{
-		collection: database.DB.Collection("roles"),
+		collection: db.Collection("roles"),
 	}
 }
 
@@ -30,6 +30,18 @@ func (r *RoleRepository) Create(ctx context.Context, role *models.Role) error {
 	return err
 }
 
+// Update met à jour un rôle
+func (r *RoleRepository) Update(ctx context.Context, id string, updates bson.M) error {
+	updates["updated_at"] = time.Now()
+
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": updates},
+	)
+	return err
+}
+
 func (r *RoleRepository) Delete(ctx context.Context, id string) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
@@ -94,8 +106,12 @@ func (r *RoleRepository) GetAll(ctx context.Context) ([]models.Role, error) {
 
 func decodeRoleFromRaw(raw bson.M) models.Role {
 	role := models.Role{
-		Name: getString(raw, "name"),
-		Slug: getString(raw, "slug"),
+		Name:        getString(raw, "name"),
+		Slug:        getString(raw, "slug"),
+		Permissions: getStringSlice(raw, "permissions"),
+	}
+	if isSystem, ok := raw["is_system"].(bool); ok {
+		role.IsSystem = isSystem
 	}
 	
 	// Convertir l'ID string depuis le document brut
@@ -128,3 +144,23 @@ func getString(m bson.M, key string) string {
 	}
 	return ""
 }
+
+// getStringSlice lit un tableau de chaînes quel que soit le type de tableau produit par le décodeur
+func getStringSlice(m bson.M, key string) []string {
+	values := []string{}
+	val, ok := m[key]
+	if !ok || val == nil {
+		return values
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice {
+		return values
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if str, ok := rv.Index(i).Interface().(string); ok {
+			values = append(values, str)
+		}
+	}
+	return values
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	bsonv2 "go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SectionTemplateRepository conserve les modèles de section des hôtes. Une propriété est liée
+// à au plus un modèle par section. Les secrets du contenu sont chiffrés.
+type SectionTemplateRepository struct {
+	collection *mongo.Collection
+	keyring    *fieldcrypt.Keyring
+}
+
+func NewSectionTemplateRepository(db *mongo.Database, keyring *fieldcrypt.Keyring) *SectionTemplateRepository {
+	return &SectionTemplateRepository{
+		collection: db.Collection("section_templates"),
+		keyring:    keyring,
+	}
+}
+
+// Create enregistre un nouveau modèle
+func (r *SectionTemplateRepository) Create(ctx context.Context, template *models.SectionTemplate) error {
+	content := template.Content
+	encrypted, err := EncryptPropertySecrets(r.keyring, content)
+	if err != nil {
+		return err
+	}
+
+	template.ID = primitive.NewObjectID()
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+	if template.LinkedPropertyIDs == nil {
+		template.LinkedPropertyIDs = []primitive.ObjectID{}
+	}
+	template.Content = encrypted
+
+	_, err = r.collection.InsertOne(ctx, template)
+	template.Content = content
+	template.Value = content.SectionValue(template.Section)
+	return err
+}
+
+// FindByID trouve un modèle par son ID, contenu déchiffré
+func (r *SectionTemplateRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.SectionTemplate, error) {
+	var template models.SectionTemplate
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&template); err != nil {
+		return nil, err
+	}
+	if err := r.decrypt(&template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// FindByHostID liste les modèles d'un hôte par nom, éventuellement pour une seule section
+func (r *SectionTemplateRepository) FindByHostID(ctx context.Context, hostID primitive.ObjectID, section string) ([]models.SectionTemplate, error) {
+	filter := bson.M{"hostId": hostID}
+	if section != "" {
+		filter["section"] = section
+	}
+
+	opts := options.Find().SetSort(bsonv2.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	templates := []models.SectionTemplate{}
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if err := r.decrypt(&templates[i]); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// Update renomme un modèle (name non vide) et/ou remplace son contenu (content non nil)
+func (r *SectionTemplateRepository) Update(ctx context.Context, id primitive.ObjectID, name string, content *models.Property) error {
+	set := bson.M{"updatedAt": time.Now()}
+	if name != "" {
+		set["name"] = name
+	}
+	if content != nil {
+		encrypted, err := EncryptPropertySecrets(r.keyring, content)
+		if err != nil {
+			return err
+		}
+		set["content"] = encrypted
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	return err
+}
+
+// Delete supprime un modèle ; les propriétés liées conservent leur contenu
+func (r *SectionTemplateRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// DeleteByHostID supprime les modèles d'un hôte
+func (r *SectionTemplateRepository) DeleteByHostID(ctx context.Context, hostID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"hostId": hostID})
+	return err
+}
+
+// Link lie une propriété au modèle. Elle est d'abord détachée des autres modèles de la même
+// section : une section ne peut suivre qu'un seul modèle.
+func (r *SectionTemplateRepository) Link(ctx context.Context, template *models.SectionTemplate, propertyID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"hostId": template.HostID, "section": template.Section, "_id": bson.M{"$ne": template.ID}},
+		bson.M{"$pull": bson.M{"linkedPropertyIds": propertyID}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": template.ID},
+		bson.M{"$addToSet": bson.M{"linkedPropertyIds": propertyID}},
+	)
+	return err
+}
+
+// Unlink détache une propriété d'un modèle
+func (r *SectionTemplateRepository) Unlink(ctx context.Context, id, propertyID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$pull": bson.M{"linkedPropertyIds": propertyID}})
+	return err
+}
+
+// UnlinkSections détache une propriété des modèles des sections données, modifiées directement
+func (r *SectionTemplateRepository) UnlinkSections(ctx context.Context, propertyID primitive.ObjectID, sections []string) error {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"linkedPropertyIds": propertyID, "section": bson.M{"$in": sections}},
+		bson.M{"$pull": bson.M{"linkedPropertyIds": propertyID}},
+	)
+	return err
+}
+
+// UnlinkProperty détache une propriété supprimée de tous les modèles
+func (r *SectionTemplateRepository) UnlinkProperty(ctx context.Context, propertyID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"linkedPropertyIds": propertyID},
+		bson.M{"$pull": bson.M{"linkedPropertyIds": propertyID}},
+	)
+	return err
+}
+
+// CopyLinks lie la propriété dst aux mêmes modèles que la propriété src (duplication)
+func (r *SectionTemplateRepository) CopyLinks(ctx context.Context, src, dst primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"linkedPropertyIds": src},
+		bson.M{"$addToSet": bson.M{"linkedPropertyIds": dst}},
+	)
+	return err
+}
+
+// ReencryptAll rechiffre avec la clé active les secrets des modèles.
+// Retourne le nombre de modèles modifiés.
+func (r *SectionTemplateRepository) ReencryptAll(ctx context.Context) (int, error) {
+	if r.keyring == nil {
+		return 0, fieldcrypt.ErrNoActiveKey
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var template models.SectionTemplate
+		if err := cursor.Decode(&template); err != nil {
+			return updated, err
+		}
+
+		needsRotation := false
+		for _, field := range template.Content.SecretFields() {
+			if r.keyring.NeedsRotation(*field) {
+				needsRotation = true
+				break
+			}
+		}
+		if !needsRotation {
+			continue
+		}
+
+		if err := DecryptPropertySecrets(r.keyring, template.Content); err != nil {
+			return updated, fmt.Errorf("modèle %s: %w", template.ID.Hex(), err)
+		}
+		encrypted, err := EncryptPropertySecrets(r.keyring, template.Content)
+		if err != nil {
+			return updated, err
+		}
+
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": template.ID}, bson.M{"$set": bson.M{"content": encrypted}}); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, cursor.Err()
+}
+
+// EnsureIndexes crée les index de listage par hôte et de recherche des modèles liés à une propriété
+func (r *SectionTemplateRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bsonv2.D{{Key: "hostId", Value: 1}, {Key: "section", Value: 1}, {Key: "name", Value: 1}}},
+		{Keys: bsonv2.D{{Key: "linkedPropertyIds", Value: 1}}},
+	})
+	return err
+}
+
+// decrypt déchiffre le contenu lu en base et renseigne la valeur de la section
+func (r *SectionTemplateRepository) decrypt(template *models.SectionTemplate) error {
+	if template.Content == nil {
+		template.Content = &models.Property{}
+	}
+	if err := DecryptPropertySecrets(r.keyring, template.Content); err != nil {
+		return err
+	}
+	template.Value = template.Content.SectionValue(template.Section)
+	return nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type SessionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSessionRepository(db *mongo.Database) *SessionRepository {
+	return &SessionRepository{
+		collection: db.Collection("sessions"),
+	}
+}
+
+// Create enregistre une nouvelle session
+func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
+	session.ID = primitive.NewObjectID()
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+	session.LastUsedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+// FindByID trouve une session par son ID
+func (r *SessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindByRefreshTokenHash trouve la session dont le refresh token courant correspond à l'empreinte
+func (r *SessionRepository) FindByRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"refresh_token_hash": hash}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindByPreviousTokenHash trouve la session ayant déjà consommé ce refresh token
+func (r *SessionRepository) FindByPreviousTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"previous_token_hashes": hash}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate remplace le refresh token d'une session active. Retourne false si le
+// token courant a changé entre-temps (rafraîchissement concurrent ou session révoquée).
+func (r *SessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":                id,
+			"refresh_token_hash": oldHash,
+			"revoked_at":         bson.M{"$exists": false},
+		},
+		bson.M{
+			"$set": bson.M{
+				"refresh_token_hash": newHash,
+				"expires_at":         expiresAt,
+				"last_used_at":       now,
+				"updated_at":         now,
+			},
+			// Seules les dernières empreintes sont conservées : le document reste borné
+			"$push": bson.M{"previous_token_hashes": bson.M{
+				"$each":  bson.A{oldHash},
+				"$slice": -models.MaxPreviousTokenHashes,
+			}},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// FindActive trouve une session non révoquée et non expirée ; mongo.ErrNoDocuments sinon
+func (r *SessionRepository) FindActive(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":        id,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// SetOrganization choisit l'organisation pour laquelle agit la session, ou aucune si organizationID est nil
+func (r *SessionRepository) SetOrganization(ctx context.Context, id primitive.ObjectID, organizationID *primitive.ObjectID) error {
+	update := bson.M{"$set": bson.M{"organization_id": organizationID, "updated_at": time.Now()}}
+	if organizationID == nil {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"organization_id": ""},
+		}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// ClearOrganization fait agir en leur nom propre les sessions qui agissaient pour une
+// organisation ; limité aux sessions d'un utilisateur si userID est renseigné
+func (r *SessionRepository) ClearOrganization(ctx context.Context, organizationID primitive.ObjectID, userID *primitive.ObjectID) error {
+	filter := bson.M{"organization_id": organizationID}
+	if userID != nil {
+		filter["user_id"] = *userID
+	}
+
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$unset": bson.M{"organization_id": ""},
+	})
+	return err
+}
+
+// Revoke révoque une session (et donc toute sa famille de tokens)
+func (r *SessionRepository) Revoke(ctx context.Context, id primitive.ObjectID, reason string) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+			"updated_at":     now,
+		}},
+	)
+	return err
+}
+
+// RevokeAllByUserID révoque toutes les sessions actives d'un utilisateur
+func (r *SessionRepository) RevokeAllByUserID(ctx context.Context, userID primitive.ObjectID, reason string) (int64, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+			"updated_at":     now,
+		}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// RevokeOthersByUserID révoque toutes les sessions actives d'un utilisateur sauf celle indiquée
+func (r *SessionRepository) RevokeOthersByUserID(ctx context.Context, userID, keepID primitive.ObjectID, reason string) (int64, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{
+			"user_id":    userID,
+			"_id":        bson.M{"$ne": keepID},
+			"revoked_at": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+			"updated_at":     now,
+		}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
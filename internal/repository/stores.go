@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/fieldcrypt"
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Les interfaces suivantes décrivent ce que les handlers attendent des repositories.
+// Les implémentations MongoDB de ce package les satisfont ; le package memory en fournit
+// une version en mémoire pour les tests. Les opérations de maintenance (index, rotation
+// des clés de chiffrement) restent propres aux implémentations MongoDB.
+
+// UserStore conserve les comptes utilisateurs
+type UserStore interface {
+	Create(ctx context.Context, user *models.User) error
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	GetAll(ctx context.Context) ([]models.User, error)
+	FindByID(ctx context.Context, id string) (*models.User, error)
+	Update(ctx context.Context, id string, updates bson.M) error
+	MarkEmailVerified(ctx context.Context, id primitive.ObjectID) error
+	Delete(ctx context.Context, id string) error
+}
+
+// RoleStore conserve les rôles et leurs permissions
+type RoleStore interface {
+	Create(ctx context.Context, role *models.Role) error
+	Update(ctx context.Context, id string, updates bson.M) error
+	Delete(ctx context.Context, id string) error
+	FindBySlug(ctx context.Context, slug string) (*models.Role, error)
+	FindByID(ctx context.Context, id string) (*models.Role, error)
+	ExistsBySlug(ctx context.Context, slug string) (bool, error)
+	GetAll(ctx context.Context) ([]models.Role, error)
+}
+
+// PropertyStore conserve les propriétés
+type PropertyStore interface {
+	Create(ctx context.Context, property *models.Property) error
+	ExistsBySlug(ctx context.Context, slug string) (bool, error)
+	ExistsByNameAndHostID(ctx context.Context, name string, hostID primitive.ObjectID) (bool, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Property, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Property, error)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Property, error)
+	FindByHostID(ctx context.Context, hostID primitive.ObjectID, includeDraft bool) ([]models.Property, error)
+	FindAll(ctx context.Context, query models.PropertySearchQuery) (*PropertyPage, error)
+	Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteByHostID(ctx context.Context, hostID primitive.ObjectID) (int64, error)
+	TransferOwnership(ctx context.Context, id, hostID primitive.ObjectID) error
+
+	AddPhoto(ctx context.Context, propertyID primitive.ObjectID, photo models.PropertyImage) error
+	SetPhotos(ctx context.Context, propertyID primitive.ObjectID, version int64, photos []models.PropertyImage) error
+	DeletePhoto(ctx context.Context, propertyID, photoID primitive.ObjectID) (*models.PropertyImage, error)
+	SetTranslations(ctx context.Context, propertyID primitive.ObjectID, translations []models.PropertyTranslation) error
+
+	Publish(ctx context.Context, id primitive.ObjectID, content bson.M, publishedAt *time.Time) error
+	Unpublish(ctx context.Context, id primitive.ObjectID, content bson.M) error
+	SchedulePublication(ctx context.Context, id primitive.ObjectID, at *time.Time) error
+	FindDueForPublication(ctx context.Context, now time.Time) ([]models.Property, error)
+
+	FindByOrganizationID(ctx context.Context, organizationID primitive.ObjectID, includeDraft bool) ([]models.Property, error)
+	SetOrganization(ctx context.Context, id primitive.ObjectID, organizationID *primitive.ObjectID) error
+	DetachOrganization(ctx context.Context, organizationID primitive.ObjectID) (int64, error)
+	FindOrganizationIDsByHostID(ctx context.Context, hostID primitive.ObjectID) ([]primitive.ObjectID, error)
+	ReassignOrganizationHost(ctx context.Context, organizationID, hostID, newHostID primitive.ObjectID) error
+	CountByOrganization(ctx context.Context) (map[primitive.ObjectID]models.OrganizationPropertyCount, error)
+}
+
+// LogementStore conserve les logements (ancien modèle)
+type LogementStore interface {
+	Create(ctx context.Context, logement *models.Logement) error
+	ExistsByNomBienAndUserID(ctx context.Context, nomBien string, userID primitive.ObjectID) (bool, error)
+	FindByUserID(ctx context.Context, userID primitive.ObjectID, includeBrouillon bool) ([]models.Logement, error)
+}
+
+// SessionStore conserve les sessions et leurs refresh tokens
+type SessionStore interface {
+	Create(ctx context.Context, session *models.Session) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+	FindByRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error)
+	FindByPreviousTokenHash(ctx context.Context, hash string) (*models.Session, error)
+	FindActive(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+	Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) (bool, error)
+	SetOrganization(ctx context.Context, id primitive.ObjectID, organizationID *primitive.ObjectID) error
+	ClearOrganization(ctx context.Context, organizationID primitive.ObjectID, userID *primitive.ObjectID) error
+	Revoke(ctx context.Context, id primitive.ObjectID, reason string) error
+	RevokeAllByUserID(ctx context.Context, userID primitive.ObjectID, reason string) (int64, error)
+	RevokeOthersByUserID(ctx context.Context, userID, keepID primitive.ObjectID, reason string) (int64, error)
+}
+
+// UserTokenStore conserve les tokens à usage unique envoyés par email
+type UserTokenStore interface {
+	Create(ctx context.Context, token *models.UserToken) error
+	Consume(ctx context.Context, tokenHash, purpose string) (*models.UserToken, error)
+	InvalidateByUserID(ctx context.Context, userID primitive.ObjectID, purpose string) error
+}
+
+// PropertyMemberStore conserve les collaborateurs des propriétés et leurs invitations
+type PropertyMemberStore interface {
+	Create(ctx context.Context, member *models.PropertyMember) error
+	FindByPropertyAndUser(ctx context.Context, propertyID, userID primitive.ObjectID) (*models.PropertyMember, error)
+	FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.PropertyMember, error)
+	FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]models.PropertyMember, error)
+	UpdateRole(ctx context.Context, propertyID, userID primitive.ObjectID, role string) error
+	Delete(ctx context.Context, propertyID, userID primitive.ObjectID) error
+	DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error
+	DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error
+
+	CreateInvitation(ctx context.Context, invitation *models.PropertyInvitation) error
+	FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.PropertyInvitation, error)
+	FindPendingInvitations(ctx context.Context, propertyID primitive.ObjectID) ([]models.PropertyInvitation, error)
+	RespondInvitation(ctx context.Context, id primitive.ObjectID, status string) error
+	DeleteInvitation(ctx context.Context, propertyID, id primitive.ObjectID) error
+}
+
+// OrganizationStore conserve les organisations et leurs membres
+type OrganizationStore interface {
+	Create(ctx context.Context, organization *models.Organization) error
+	ExistsBySlug(ctx context.Context, slug string) (bool, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Organization, error)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Organization, error)
+	FindAll(ctx context.Context) ([]models.Organization, error)
+	Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	AddMember(ctx context.Context, member *models.OrganizationMember) error
+	FindMember(ctx context.Context, organizationID, userID primitive.ObjectID) (*models.OrganizationMember, error)
+	FindMembers(ctx context.Context, organizationID primitive.ObjectID) ([]models.OrganizationMember, error)
+	FindMembershipsByUserID(ctx context.Context, userID primitive.ObjectID) ([]models.OrganizationMember, error)
+	CountOwners(ctx context.Context, organizationID primitive.ObjectID) (int64, error)
+	CountMembers(ctx context.Context) (map[primitive.ObjectID]int64, error)
+	UpdateMemberRole(ctx context.Context, organizationID, userID primitive.ObjectID, role string) error
+	RemoveMember(ctx context.Context, organizationID, userID primitive.ObjectID) error
+	DeleteMembershipsByUserID(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// PropertyDraftStore conserve les modifications en attente des propriétés publiées
+type PropertyDraftStore interface {
+	FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) (*models.PropertyDraft, error)
+	Save(ctx context.Context, live *models.Property, updates bson.M, authorID *primitive.ObjectID) error
+	DeleteIfUnchanged(ctx context.Context, propertyID primitive.ObjectID, version int64) (bool, error)
+	DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error
+}
+
+// PropertyRevisionStore conserve l'historique des propriétés
+type PropertyRevisionStore interface {
+	Record(ctx context.Context, before, after *models.Property, revision *models.PropertyRevision) error
+	FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, limit int64) ([]models.PropertyRevision, error)
+	FindByNumber(ctx context.Context, propertyID primitive.ObjectID, number int) (*models.PropertyRevision, error)
+	DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error
+}
+
+// SectionTemplateLinkStore entretient les liens entre les modèles de section et les
+// propriétés lorsqu'une propriété est modifiée, dupliquée ou supprimée
+type SectionTemplateLinkStore interface {
+	UnlinkSections(ctx context.Context, propertyID primitive.ObjectID, sections []string) error
+	UnlinkProperty(ctx context.Context, propertyID primitive.ObjectID) error
+	CopyLinks(ctx context.Context, src, dst primitive.ObjectID) error
+	DeleteByHostID(ctx context.Context, hostID primitive.ObjectID) error
+}
+
+// SectionTemplateStore conserve les modèles de section des hôtes
+type SectionTemplateStore interface {
+	SectionTemplateLinkStore
+	Create(ctx context.Context, template *models.SectionTemplate) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.SectionTemplate, error)
+	FindByHostID(ctx context.Context, hostID primitive.ObjectID, section string) ([]models.SectionTemplate, error)
+	Update(ctx context.Context, id primitive.ObjectID, name string, content *models.Property) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	Link(ctx context.Context, template *models.SectionTemplate, propertyID primitive.ObjectID) error
+	Unlink(ctx context.Context, id, propertyID primitive.ObjectID) error
+}
+
+// GuestLinkStore conserve les liens voyageurs et le journal de leurs accès
+type GuestLinkStore interface {
+	Create(ctx context.Context, link *models.GuestLink) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.GuestLink, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.GuestLink, error)
+	FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.GuestLink, error)
+	Revoke(ctx context.Context, id primitive.ObjectID) error
+	DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error
+	RecordUse(ctx context.Context, id primitive.ObjectID) error
+	RecordFailedAttempt(ctx context.Context, id primitive.ObjectID) error
+	LogAccess(ctx context.Context, access *models.GuestLinkAccess) error
+	FindAccessesByLinkID(ctx context.Context, linkID primitive.ObjectID, limit int64) ([]models.GuestLinkAccess, error)
+}
+
+// ReservationStore conserve les réservations des propriétés
+type ReservationStore interface {
+	Create(ctx context.Context, reservation *models.Reservation) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Reservation, error)
+	FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, status string, from, to *time.Time) ([]models.Reservation, error)
+	HasOverlap(ctx context.Context, propertyID primitive.ObjectID, arrival, departure time.Time, excludeID *primitive.ObjectID) (bool, error)
+	Update(ctx context.Context, id primitive.ObjectID, updates bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error
+}
+
+// CalendarBlockStore conserve les périodes bloquées des calendriers
+type CalendarBlockStore interface {
+	Create(ctx context.Context, block *models.CalendarBlock) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.CalendarBlock, error)
+	FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID, from, to *time.Time) ([]models.CalendarBlock, error)
+	HasOverlap(ctx context.Context, propertyID primitive.ObjectID, start, end time.Time) (bool, error)
+	ReplaceFeedBlocks(ctx context.Context, feedID primitive.ObjectID, blocks []models.CalendarBlock) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteByFeedID(ctx context.Context, feedID primitive.ObjectID) error
+	DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error
+}
+
+// CalendarFeedStore conserve les calendriers iCal externes importés
+type CalendarFeedStore interface {
+	Create(ctx context.Context, feed *models.CalendarFeed) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.CalendarFeed, error)
+	FindByPropertyID(ctx context.Context, propertyID primitive.ObjectID) ([]models.CalendarFeed, error)
+	FindAll(ctx context.Context) ([]models.CalendarFeed, error)
+	UpdateSyncStatus(ctx context.Context, id primitive.ObjectID, status, syncError string, eventCount int) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error
+}
+
+// CalendarLockStore sérialise les modifications du calendrier d'une propriété : la
+// vérification des disponibilités et l'écriture qui la suit se font sous le même verrou.
+// La fonction retournée libère le verrou.
+type CalendarLockStore interface {
+	Lock(ctx context.Context, propertyID primitive.ObjectID) (func(), error)
+}
+
+// PropertyDataCleaner supprime les données rattachées à une propriété supprimée
+// (liens voyageurs, réservations, calendriers...)
+type PropertyDataCleaner interface {
+	DeleteByPropertyID(ctx context.Context, propertyID primitive.ObjectID) error
+}
+
+var (
+	_ UserStore             = (*UserRepository)(nil)
+	_ RoleStore             = (*RoleRepository)(nil)
+	_ PropertyStore         = (*PropertyRepository)(nil)
+	_ LogementStore         = (*LogementRepository)(nil)
+	_ SessionStore          = (*SessionRepository)(nil)
+	_ UserTokenStore        = (*UserTokenRepository)(nil)
+	_ PropertyMemberStore   = (*PropertyMemberRepository)(nil)
+	_ OrganizationStore     = (*OrganizationRepository)(nil)
+	_ PropertyDraftStore    = (*PropertyDraftRepository)(nil)
+	_ PropertyRevisionStore = (*PropertyRevisionRepository)(nil)
+	_ SectionTemplateStore  = (*SectionTemplateRepository)(nil)
+	_ GuestLinkStore        = (*GuestLinkRepository)(nil)
+	_ ReservationStore      = (*ReservationRepository)(nil)
+	_ CalendarBlockStore    = (*CalendarBlockRepository)(nil)
+	_ CalendarFeedStore     = (*CalendarFeedRepository)(nil)
+	_ CalendarLockStore     = (*CalendarLockRepository)(nil)
+)
+
+// Stores regroupe les stores injectés dans les handlers
+type Stores struct {
+	Users             UserStore
+	Roles             RoleStore
+	Properties        PropertyStore
+	Logements         LogementStore
+	Sessions          SessionStore
+	UserTokens        UserTokenStore
+	PropertyMembers   PropertyMemberStore
+	Organizations     OrganizationStore
+	PropertyDrafts    PropertyDraftStore
+	PropertyRevisions PropertyRevisionStore
+
+	// SectionTemplateLinks suffit aux handlers qui ne font qu'entretenir les liens des
+	// propriétés vers les modèles ; SectionTemplates donne accès aux modèles eux-mêmes
+	SectionTemplateLinks SectionTemplateLinkStore
+	SectionTemplates     SectionTemplateStore
+	GuestLinks           GuestLinkStore
+	Reservations         ReservationStore
+	CalendarBlocks       CalendarBlockStore
+	CalendarFeeds        CalendarFeedStore
+	CalendarLocks        CalendarLockStore
+
+	// PropertyData liste les données à supprimer avec une propriété, hors brouillon,
+	// historique, collaborateurs et liens vers les modèles de section
+	PropertyData []PropertyDataCleaner
+}
+
+// NewStores construit les stores MongoDB de la base db. Les secrets des propriétés sont
+// chiffrés avec keyring ; nil les laisse en clair.
+func NewStores(db *mongo.Database, keyring *fieldcrypt.Keyring) *Stores {
+	templates := NewSectionTemplateRepository(db, keyring)
+	guestLinks := NewGuestLinkRepository(db)
+	reservations := NewReservationRepository(db)
+	blocks := NewCalendarBlockRepository(db)
+	feeds := NewCalendarFeedRepository(db)
+
+	return &Stores{
+		Users:                NewUserRepository(db),
+		Roles:                NewRoleRepository(db),
+		Properties:           NewPropertyRepository(db, keyring),
+		Logements:            NewLogementRepository(db),
+		Sessions:             NewSessionRepository(db),
+		UserTokens:           NewUserTokenRepository(db),
+		PropertyMembers:      NewPropertyMemberRepository(db),
+		Organizations:        NewOrganizationRepository(db),
+		PropertyDrafts:       NewPropertyDraftRepository(db, keyring),
+		PropertyRevisions:    NewPropertyRevisionRepository(db, keyring),
+		SectionTemplateLinks: templates,
+		SectionTemplates:     templates,
+		GuestLinks:           guestLinks,
+		Reservations:         reservations,
+		CalendarBlocks:       blocks,
+		CalendarFeeds:        feeds,
+		CalendarLocks:        NewCalendarLockRepository(db),
+		PropertyData:         []PropertyDataCleaner{guestLinks, reservations, blocks, feeds},
+	}
+}
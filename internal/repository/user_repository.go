@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"onestay-back/internal/database"
 	"onestay-back/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,9 +15,9 @@ type UserRepository struct {
 	collection *mongo.Collection
 }
 
-func NewUserRepository() *UserRepository {
+func NewUserRepository(db *mongo.Database) *UserRepository {
 	return &UserRepository{
-		collection: database.DB.Collection("users"),
+		collection: db.Collection("users"),
 	}
 }
 
@@ -92,6 +91,20 @@ func (r *UserRepository) Update(ctx context.Context, id string, updates bson.M)
 	return err
 }
 
+// MarkEmailVerified confirme l'adresse email d'un utilisateur
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$set":   bson.M{"email_verified_at": now, "updated_at": now},
+			"$unset": bson.M{"email_verification_pending": ""},
+		},
+	)
+	return err
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"onestay-back/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type UserTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewUserTokenRepository(db *mongo.Database) *UserTokenRepository {
+	return &UserTokenRepository{
+		collection: db.Collection("user_tokens"),
+	}
+}
+
+// Create enregistre un nouveau token
+func (r *UserTokenRepository) Create(ctx context.Context, token *models.UserToken) error {
+	token.ID = primitive.NewObjectID()
+	token.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+// Consume marque comme utilisé un token valide et le retourne.
+// La recherche et le marquage sont atomiques : un token ne peut être consommé qu'une fois.
+func (r *UserTokenRepository) Consume(ctx context.Context, tokenHash, purpose string) (*models.UserToken, error) {
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var token models.UserToken
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"token_hash": tokenHash,
+			"purpose":    purpose,
+			"used_at":    bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"used_at": now}},
+		opts,
+	).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// InvalidateByUserID invalide tous les tokens encore utilisables d'un utilisateur pour un usage donné
+func (r *UserTokenRepository) InvalidateByUserID(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{
+			"user_id": userID,
+			"purpose": purpose,
+			"used_at": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	)
+	return err
+}
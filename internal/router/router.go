@@ -1,58 +1,219 @@
 package router
 
 import (
+	"fmt"
+
+	"onestay-back/internal/apierror"
+	"onestay-back/internal/config"
 	"onestay-back/internal/handlers"
 	"onestay-back/internal/middleware"
+	"onestay-back/internal/models"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter() *gin.Engine {
-	r := gin.Default()
+// Handlers regroupe les handlers et middlewares d'authentification montés par le routeur
+type Handlers struct {
+	RequireAuth       gin.HandlerFunc
+	OptionalAuth      gin.HandlerFunc
+	RequirePermission func(permissions ...string) gin.HandlerFunc
+
+	Auth         *handlers.AuthHandler
+	Property     *handlers.PropertyHandler
+	GuestLink    *handlers.GuestLinkHandler
+	Reservation  *handlers.ReservationHandler
+	Calendar     *handlers.CalendarHandler
+	Image        *handlers.PropertyImageHandler
+	Guidebook    *handlers.GuidebookHandler
+	QRCode       *handlers.QRCodeHandler
+	Translation  *handlers.TranslationHandler
+	Revision     *handlers.PropertyRevisionHandler
+	Template     *handlers.SectionTemplateHandler
+	Member       *handlers.PropertyMemberHandler
+	Organization *handlers.OrganizationHandler
+	Health       *handlers.HealthHandler
+}
+
+// probePaths ne sont pas journalisées : l'orchestrateur les interroge en continu
+var probePaths = []string{"/healthz", "/readyz"}
+
+func SetupRouter(cfg *config.Config, h *Handlers) *gin.Engine {
+	r := gin.New()
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: probePaths}), gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		apierror.Internal(c, fmt.Errorf("panic: %v", recovered))
+	}))
+	r.NoRoute(func(c *gin.Context) {
+		apierror.Abort(c, apierror.RouteNotFound)
+	})
+
+	// Les erreurs de validation désignent les champs par leur nom JSON
+	apierror.RegisterFieldNames()
 
 	// Configuration CORS
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Guest-Pin"},
 		ExposeHeaders:    []string{"Authorization"},
 		AllowCredentials: true,
 	}))
 
-	authHandler := handlers.NewAuthHandler()
-	propertyHandler := handlers.NewPropertyHandler()
+	// Sondes de l'orchestrateur et informations de build, hors de l'API versionnée
+	r.GET("/healthz", h.Health.Liveness)
+	r.GET("/readyz", h.Health.Readiness)
+	r.GET("/version", h.Health.Version)
+
+	// Taille maximale du corps des requêtes ; l'envoi d'images a sa propre limite
+	if cfg.MaxRequestBodySize > 0 {
+		r.Use(middleware.BodyLimit(cfg.MaxRequestBodySize))
+	}
+
+	// Avec le stockage local, les médias sont servis directement par l'API
+	if cfg.StorageDriver != "s3" {
+		r.Static("/media", cfg.MediaLocalDir)
+	}
 
 	api := r.Group("/api/v1")
 	{
+		api.GET("/errors", handlers.GetErrorCatalog)
+
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", authHandler.Login)
-			auth.GET("/roles", middleware.AuthMiddleware(), middleware.RequireAdmin(), authHandler.GetRoles)
-			auth.POST("/roles", middleware.AuthMiddleware(), middleware.RequireSuperAdmin(), authHandler.CreateRole)
-			auth.DELETE("/roles/:id", middleware.AuthMiddleware(), middleware.RequireSuperAdmin(), authHandler.DeleteRole)
+			auth.POST("/login", h.Auth.Login)
+			auth.POST("/refresh", h.Auth.RefreshToken)
+			auth.POST("/logout", h.RequireAuth, h.Auth.Logout)
+			auth.POST("/logout-all", h.RequireAuth, h.Auth.LogoutAll)
+			auth.POST("/password/forgot", h.Auth.ForgotPassword)
+			auth.POST("/password/reset", h.Auth.ResetPassword)
+			auth.POST("/verify-email", h.Auth.VerifyEmail)
+			auth.POST("/verify-email/resend", h.Auth.ResendVerification)
+			auth.GET("/roles", h.RequireAuth, h.RequirePermission(models.PermissionRolesRead), h.Auth.GetRoles)
+			auth.GET("/permissions", h.RequireAuth, h.RequirePermission(models.PermissionRolesRead), h.Auth.GetPermissions)
+			auth.POST("/roles", h.RequireAuth, h.RequirePermission(models.PermissionRolesManage), h.Auth.CreateRole)
+			auth.PUT("/roles/:id", h.RequireAuth, h.RequirePermission(models.PermissionRolesManage), h.Auth.UpdateRole)
+			auth.DELETE("/roles/:id", h.RequireAuth, h.RequirePermission(models.PermissionRolesManage), h.Auth.DeleteRole)
 		}
 
 		users := api.Group("/users")
 		{
-			users.POST("/register", middleware.AuthMiddleware(), middleware.RequireAdmin(), authHandler.Register)
-			users.GET("/profile", middleware.AuthMiddleware(), authHandler.GetProfile)
-			users.PUT("/profile", middleware.AuthMiddleware(), authHandler.UpdateProfile)
-			users.DELETE("/profile", middleware.AuthMiddleware(), authHandler.DeleteAccount)
-			users.GET("", middleware.AuthMiddleware(), middleware.RequireAdmin(), authHandler.GetAllUsers)
-			users.PUT("/:id", middleware.AuthMiddleware(), middleware.RequireAdmin(), authHandler.UpdateUser)
-			users.DELETE("/:id", middleware.AuthMiddleware(), middleware.RequireAdmin(), authHandler.DeleteUser)
+			users.POST("/signup", h.Auth.Signup)
+			users.POST("/register", h.RequireAuth, h.RequirePermission(models.PermissionUsersWrite), h.Auth.Register)
+			users.GET("/profile", h.RequireAuth, h.Auth.GetProfile)
+			users.PUT("/profile", h.RequireAuth, h.Auth.UpdateProfile)
+			users.DELETE("/profile", h.RequireAuth, h.Auth.DeleteAccount)
+			users.GET("", h.RequireAuth, h.RequirePermission(models.PermissionUsersRead), h.Auth.GetAllUsers)
+			users.PUT("/:id", h.RequireAuth, h.RequirePermission(models.PermissionUsersWrite), h.Auth.UpdateUser)
+			users.DELETE("/:id", h.RequireAuth, h.RequirePermission(models.PermissionUsersWrite), h.Auth.DeleteUser)
 		}
 
 		properties := api.Group("/properties")
 		{
-			properties.POST("", middleware.AuthMiddleware(), propertyHandler.CreateProperty)
-			properties.GET("/user/:id", propertyHandler.GetUserProperties)
-			properties.GET("/:id", propertyHandler.GetProperty)
-			properties.PUT("/:id", middleware.AuthMiddleware(), propertyHandler.UpdateProperty)
-			properties.POST("/:id/publish", middleware.AuthMiddleware(), propertyHandler.PublishProperty)
-			properties.DELETE("/:id", middleware.AuthMiddleware(), propertyHandler.DeleteProperty)
+			properties.GET("", h.Property.SearchProperties)
+			properties.POST("", h.RequireAuth, h.Property.CreateProperty)
+			properties.GET("/user/:id", h.OptionalAuth, h.Property.GetUserProperties)
+			properties.GET("/shared", h.RequireAuth, h.Member.GetSharedProperties)
+			properties.GET("/:id", h.OptionalAuth, h.Property.GetProperty)
+			properties.GET("/:id/recommendations", h.OptionalAuth, h.Property.GetRecommendations)
+			properties.GET("/:id/guidebook.pdf", h.OptionalAuth, h.Guidebook.ExportGuidebook)
+			properties.GET("/:id/qrcodes/wifi", h.RequireAuth, h.QRCode.GetWifiQRCode)
+			properties.GET("/:id/qrcodes/guidebook", h.OptionalAuth, h.QRCode.GetGuidebookQRCode)
+			properties.PUT("/:id", h.RequireAuth, h.Property.UpdateProperty)
+			properties.POST("/:id/publish", h.RequireAuth, h.Property.PublishProperty)
+			properties.DELETE("/:id/publish/schedule", h.RequireAuth, h.Property.CancelScheduledPublication)
+			properties.POST("/:id/unpublish", h.RequireAuth, h.Property.UnpublishProperty)
+			properties.GET("/:id/readiness", h.RequireAuth, h.Property.GetReadiness)
+			properties.GET("/:id/draft", h.RequireAuth, h.Property.GetDraft)
+			properties.DELETE("/:id/draft", h.RequireAuth, h.Property.DiscardDraft)
+			properties.POST("/:id/draft/publish", h.RequireAuth, h.Property.PublishDraft)
+			properties.POST("/:id/duplicate", h.RequireAuth, h.Property.DuplicateProperty)
+			properties.DELETE("/:id", h.RequireAuth, h.Property.DeleteProperty)
+
+			properties.GET("/:id/members", h.RequireAuth, h.Member.GetMembers)
+			properties.PUT("/:id/members/:userId", h.RequireAuth, h.Member.UpdateMember)
+			properties.DELETE("/:id/members/:userId", h.RequireAuth, h.Member.RemoveMember)
+			properties.POST("/:id/invitations", h.RequireAuth, h.Member.InviteMember)
+			properties.DELETE("/:id/invitations/:invitationId", h.RequireAuth, h.Member.CancelInvitation)
+			properties.POST("/:id/transfer", h.RequireAuth, h.Member.TransferOwnership)
+			properties.PUT("/:id/organization", h.RequireAuth, h.Organization.AssignProperty)
+
+			properties.GET("/:id/translations", h.RequireAuth, h.Translation.GetTranslations)
+			properties.GET("/:id/translations/missing", h.RequireAuth, h.Translation.GetMissingTranslations)
+			properties.PUT("/:id/translations/:locale", h.RequireAuth, h.Translation.UpsertTranslation)
+			properties.DELETE("/:id/translations/:locale", h.RequireAuth, h.Translation.DeleteTranslation)
+
+			properties.GET("/:id/revisions", h.RequireAuth, h.Revision.GetRevisions)
+			properties.GET("/:id/revisions/diff", h.RequireAuth, h.Revision.DiffRevisions)
+			properties.GET("/:id/revisions/:rev", h.RequireAuth, h.Revision.GetRevision)
+			properties.POST("/:id/revisions/:rev/restore", h.RequireAuth, h.Revision.RestoreRevision)
+
+			properties.POST("/:id/images", h.RequireAuth, middleware.BodyLimit(cfg.MaxImageUploadSize+1<<20), h.Image.UploadImage)
+			properties.PUT("/:id/images/order", h.RequireAuth, h.Image.ReorderImages)
+			properties.PUT("/:id/images/:imageId", h.RequireAuth, h.Image.UpdateImage)
+			properties.DELETE("/:id/images/:imageId", h.RequireAuth, h.Image.DeleteImage)
+
+			properties.POST("/:id/guest-links", h.RequireAuth, h.GuestLink.CreateGuestLink)
+			properties.GET("/:id/guest-links", h.RequireAuth, h.GuestLink.GetGuestLinks)
+			properties.DELETE("/:id/guest-links/:linkId", h.RequireAuth, h.GuestLink.RevokeGuestLink)
+			properties.GET("/:id/guest-links/:linkId/accesses", h.RequireAuth, h.GuestLink.GetGuestLinkAccesses)
+
+			properties.POST("/:id/reservations", h.RequireAuth, h.Reservation.CreateReservation)
+			properties.GET("/:id/reservations", h.RequireAuth, h.Reservation.GetReservations)
+			properties.GET("/:id/reservations/:reservationId", h.RequireAuth, h.Reservation.GetReservation)
+			properties.PUT("/:id/reservations/:reservationId", h.RequireAuth, h.Reservation.UpdateReservation)
+			properties.DELETE("/:id/reservations/:reservationId", h.RequireAuth, h.Reservation.DeleteReservation)
+
+			properties.GET("/:id/calendar.ics", h.OptionalAuth, h.Calendar.ExportCalendar)
+			properties.GET("/:id/calendar", h.RequireAuth, h.Calendar.GetCalendar)
+			properties.POST("/:id/calendar/blocks", h.RequireAuth, h.Calendar.CreateBlock)
+			properties.DELETE("/:id/calendar/blocks/:blockId", h.RequireAuth, h.Calendar.DeleteBlock)
+			properties.GET("/:id/calendar/feeds", h.RequireAuth, h.Calendar.GetFeeds)
+			properties.POST("/:id/calendar/feeds", h.RequireAuth, h.Calendar.CreateFeed)
+			properties.POST("/:id/calendar/feeds/:feedId/sync", h.RequireAuth, h.Calendar.SyncFeed)
+			properties.DELETE("/:id/calendar/feeds/:feedId", h.RequireAuth, h.Calendar.DeleteFeed)
+		}
+
+		templates := api.Group("/templates")
+		{
+			templates.GET("", h.RequireAuth, h.Template.GetTemplates)
+			templates.POST("", h.RequireAuth, h.Template.CreateTemplate)
+			templates.GET("/:id", h.RequireAuth, h.Template.GetTemplate)
+			templates.PUT("/:id", h.RequireAuth, h.Template.UpdateTemplate)
+			templates.DELETE("/:id", h.RequireAuth, h.Template.DeleteTemplate)
+			templates.POST("/:id/apply", h.RequireAuth, h.Template.ApplyTemplate)
+			templates.DELETE("/:id/links/:propertyId", h.RequireAuth, h.Template.UnlinkProperty)
 		}
+
+		organizations := api.Group("/organizations")
+		{
+			organizations.GET("", h.RequireAuth, h.Organization.GetOrganizations)
+			organizations.POST("", h.RequireAuth, h.Organization.CreateOrganization)
+			organizations.PUT("/active", h.RequireAuth, h.Organization.SwitchOrganization)
+			organizations.GET("/stats", h.RequireAuth, h.RequirePermission(models.PermissionPropertiesModerate), h.Organization.GetOrganizationStats)
+			organizations.GET("/:id", h.RequireAuth, h.Organization.GetOrganization)
+			organizations.PUT("/:id", h.RequireAuth, h.Organization.UpdateOrganization)
+			organizations.DELETE("/:id", h.RequireAuth, h.Organization.DeleteOrganization)
+			organizations.GET("/:id/properties", h.RequireAuth, h.Organization.GetProperties)
+			organizations.GET("/:id/members", h.RequireAuth, h.Organization.GetMembers)
+			organizations.POST("/:id/members", h.RequireAuth, h.Organization.AddMember)
+			organizations.PUT("/:id/members/:userId", h.RequireAuth, h.Organization.UpdateMember)
+			organizations.DELETE("/:id/members/:userId", h.RequireAuth, h.Organization.RemoveMember)
+		}
+
+		invitations := api.Group("/invitations")
+		{
+			invitations.GET("/:token", h.Member.GetInvitation)
+			invitations.POST("/:token/accept", h.RequireAuth, h.Member.AcceptInvitation)
+			invitations.POST("/:token/decline", h.Member.DeclineInvitation)
+		}
+
+		// Le PIN d'un lien voyageur passe par le header X-Guest-Pin ou le corps d'un POST
+		api.GET("/guest/:token", h.GuestLink.GetGuestProperty)
+		api.POST("/guest/:token", h.GuestLink.GetGuestProperty)
+		api.GET("/guest/:token/guidebook.pdf", h.Guidebook.ExportGuestGuidebook)
+		api.POST("/guest/:token/guidebook.pdf", h.Guidebook.ExportGuestGuidebook)
+		api.GET("/guest/:token/qrcodes/wifi", h.QRCode.GetGuestWifiQRCode)
+		api.POST("/guest/:token/qrcodes/wifi", h.QRCode.GetGuestWifiQRCode)
 	}
 
 	return r
@@ -6,11 +6,14 @@ import (
 
 	"onestay-back/internal/models"
 	"onestay-back/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func SeedRoles() error {
+// SeedRoles crée les rôles système manquants et complète ceux créés avant les permissions
+func SeedRoles(roleRepo repository.RoleStore) error {
 	ctx := context.Background()
-	roleRepo := repository.NewRoleRepository()
 
 	roles := []struct {
 		id   string
@@ -24,22 +27,43 @@ func SeedRoles() error {
 	}
 
 	for _, roleData := range roles {
-		exists, err := roleRepo.ExistsBySlug(ctx, roleData.slug)
-		if err != nil {
+		existing, err := roleRepo.FindBySlug(ctx, roleData.slug)
+		if err != nil && err != mongo.ErrNoDocuments {
 			return err
 		}
 
-		if !exists {
+		if existing == nil {
 			role := &models.Role{
-				ID:   roleData.id,
-				Name: roleData.name,
-				Slug: roleData.slug,
+				ID:          roleData.id,
+				Name:        roleData.name,
+				Slug:        roleData.slug,
+				Permissions: models.DefaultRolePermissions[roleData.slug],
+				IsSystem:    true,
 			}
 
 			if err := roleRepo.Create(ctx, role); err != nil {
 				return err
 			}
 			log.Printf("Rôle créé: %s (%s) avec ID: %s", roleData.name, roleData.slug, roleData.id)
+			continue
+		}
+
+		// Migration des rôles créés avant l'introduction des permissions :
+		// on marque le rôle comme système et on lui donne ses permissions par défaut
+		// uniquement s'il n'en a encore aucune, pour ne pas écraser des modifications.
+		updates := bson.M{}
+		if !existing.IsSystem {
+			updates["is_system"] = true
+		}
+		if len(existing.Permissions) == 0 && len(models.DefaultRolePermissions[roleData.slug]) > 0 {
+			updates["permissions"] = models.DefaultRolePermissions[roleData.slug]
+		}
+
+		if len(updates) > 0 {
+			if err := roleRepo.Update(ctx, existing.ID, updates); err != nil {
+				return err
+			}
+			log.Printf("Rôle mis à jour: %s (%s)", roleData.name, roleData.slug)
 		} else {
 			log.Printf("Rôle déjà existant: %s (%s)", roleData.name, roleData.slug)
 		}
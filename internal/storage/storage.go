@@ -0,0 +1,54 @@
+// Package storage enregistre les fichiers médias (photos des propriétés...) sur
+// disque local ou dans un bucket compatible S3.
+package storage
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path"
+	"strings"
+
+	"onestay-back/internal/config"
+)
+
+var ErrInvalidKey = errors.New("storage: clé de fichier invalide")
+
+// Storage est implémenté par chaque backend de stockage.
+// Les clés sont des chemins relatifs ("properties/<id>/<image>/medium.jpg").
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL retourne l'adresse publique du fichier
+	URL(key string) string
+}
+
+// New construit le Storage correspondant à STORAGE_DRIVER ("local" ou "s3")
+func New(cfg *config.Config) Storage {
+	switch cfg.StorageDriver {
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+			PublicURL: cfg.S3PublicURL,
+		})
+	case "local":
+		return NewLocalStorage(cfg.MediaLocalDir, cfg.MediaBaseURL)
+	default:
+		log.Printf("Warning: unknown STORAGE_DRIVER %q, files will be stored in %s", cfg.StorageDriver, cfg.MediaLocalDir)
+		return NewLocalStorage(cfg.MediaLocalDir, cfg.MediaBaseURL)
+	}
+}
+
+// cleanKey normalise une clé et refuse toute sortie du répertoire racine
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") || strings.Contains(cleaned, "..") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
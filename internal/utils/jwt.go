@@ -3,26 +3,42 @@ package utils
 import (
 	"time"
 
-	"onestay-back/internal/config"
-
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Claims struct {
-	UserID primitive.ObjectID `json:"user_id"`
-	Email  string             `json:"email"`
-	RoleID string             `json:"role_id"`
+	UserID    primitive.ObjectID `json:"user_id"`
+	Email     string             `json:"email"`
+	RoleID    string             `json:"role_id"`
+	SessionID string             `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID primitive.ObjectID, roleID string, email string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// TokenService signe et vérifie les access tokens avec le secret JWT de l'application
+type TokenService struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewTokenService(secret string, ttl time.Duration) *TokenService {
+	return &TokenService{secret: []byte(secret), ttl: ttl}
+}
+
+// TTL retourne la durée de validité des access tokens
+func (s *TokenService) TTL() time.Duration {
+	return s.ttl
+}
+
+// Generate génère un access token de courte durée rattaché à une session
+func (s *TokenService) Generate(userID primitive.ObjectID, roleID string, email string, sessionID string) (string, error) {
+	expirationTime := time.Now().Add(s.ttl)
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		RoleID: roleID,
+		UserID:    userID,
+		Email:     email,
+		RoleID:    roleID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -30,7 +46,7 @@ func GenerateToken(userID primitive.ObjectID, roleID string, email string) (stri
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.AppConfig.JWTSecret))
+	tokenString, err := token.SignedString(s.secret)
 	if err != nil {
 		return "", err
 	}
@@ -38,12 +54,12 @@ func GenerateToken(userID primitive.ObjectID, roleID string, email string) (stri
 	return tokenString, nil
 }
 
-func ValidateToken(tokenString string) (*Claims, error) {
+func (s *TokenService) Validate(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.AppConfig.JWTSecret), nil
-	})
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err